@@ -2,8 +2,11 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
-	"log"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
 	"strings"
@@ -11,46 +14,112 @@ import (
 	"time"
 
 	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/logging"
 	"github.com/amir-the-h/mcp-hub/internal/plugin"
 	"github.com/amir-the-h/mcp-hub/internal/registry"
 	"github.com/amir-the-h/mcp-hub/internal/server"
+	"github.com/amir-the-h/mcp-hub/internal/telemetry"
 	"github.com/amir-the-h/mcp-hub/internal/watcher"
 )
 
+// defaultShutdownDrain bounds how long a shutdown waits for running tool
+// calls unless hub.shutdownDrain says otherwise
+const defaultShutdownDrain = 30 * time.Second
+
 func main() {
 	configPath := flag.String("config", "config.json", "Path to configuration file")
+	requireServers := flag.Bool("require-servers", false, "Exit with an error if no MCP servers are enabled")
+	configURL := flag.String("config-url", "", "Fetch configuration from this URL instead of --config")
+	configPoll := flag.Duration("config-poll", 30*time.Second, "How often to poll --config-url for changes")
+	configHeader := flag.String("config-header", "Authorization", "Header carrying MCP_HUB_CONFIG_TOKEN when fetching --config-url")
+	logFormat := flag.String("log-format", "text", "Log format: text or json")
+	logLevel := flag.String("log-level", "info", "Minimum log level: debug, info, warn or error")
+	validate := flag.Bool("validate", false, "Validate the configuration, print the servers that would start and exit without starting them")
 	flag.Parse()
 
+	logger, err := logging.New(os.Stderr, *logFormat, *logLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "mcp-hub: %v\n", err)
+		os.Exit(2)
+	}
+	slog.SetDefault(logger)
+
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 
+	// Check the configuration only, e.g. in CI
+	if *validate {
+		source, load := *configPath, func(context.Context) (*config.Config, error) { return config.Load(*configPath) }
+		if *configURL != "" {
+			source, load = *configURL, remoteConfig(*configURL, *configHeader).Load
+		}
+		code := validateConfig(ctx, os.Stdout, load, source, *requireServers)
+		cancel()
+		os.Exit(code)
+	}
+
 	// Initialize registry
 	reg := registry.New()
 
 	// Initialize plugin manager
-	pm := plugin.NewManager(reg)
+	pm := plugin.NewManager(reg, plugin.WithLogger(logger))
 
-	// Load configuration
-	cfg, err := config.Load(*configPath)
+	// Load configuration, either from a remote URL or the local file
+	var cfg *config.Config
+	var configWatcher *watcher.Watcher
+	configSource := *configPath
+	if *configURL != "" {
+		configSource = *configURL
+		configWatcher, cfg, err = watcher.NewRemote(ctx, remoteConfig(*configURL, *configHeader), *configPoll, pm)
+	} else {
+		cfg, err = config.Load(*configPath)
+	}
 	if err != nil {
-		log.Printf("warning: failed to load config from %s: %v", *configPath, err)
-		log.Printf("starting with no MCP servers configured")
+		logger.Warn("config:load-fail", "source", configSource, "err", err)
+		logger.Warn("config:empty", "reason", "starting with no MCP servers configured")
 	} else {
+		// Mask credentials before anything logs tool calls
+		logging.SetRedactKeys(cfg.Hub.Redact)
+
+		// Export spans and metrics if configured, before connecting so the
+		// startup connect spans are exported too
+		if cfg.Hub.Telemetry != nil {
+			shutdownTelemetry, err := telemetry.Setup(ctx, *cfg.Hub.Telemetry)
+			if err != nil {
+				logger.Warn("telemetry:setup-fail", "err", err)
+			} else {
+				logger.Info("telemetry:export", "endpoint", cfg.Hub.Telemetry.Endpoint)
+				defer func() {
+					flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+					defer flushCancel()
+					if err := shutdownTelemetry(flushCtx); err != nil {
+						logger.Warn("telemetry:flush-fail", "err", err)
+					}
+				}()
+			}
+		}
+
 		// Load servers from configuration
 		if err := pm.LoadFromConfig(ctx, cfg); err != nil {
-			log.Printf("warning: failed to load servers from config: %v", err)
+			logger.Warn("config:servers-fail", "err", err)
 		}
 	}
 
+	if err := checkServers(logger, cfg, configSource, *requireServers); err != nil {
+		os.Exit(1)
+	}
+
 	// Start config watcher
-	var configWatcher *watcher.Watcher
 	if cfg != nil {
-		configWatcher, err = watcher.New(*configPath, pm)
+		if configWatcher == nil {
+			configWatcher, err = watcher.New(*configPath, pm)
+		}
 		if err != nil {
-			log.Printf("warning: failed to create config watcher: %v", err)
+			logger.Warn("watch:create-fail", "err", err)
 		} else {
+			configWatcher.SetLogger(logger)
 			if err := configWatcher.Start(ctx); err != nil {
-				log.Printf("warning: failed to start config watcher: %v", err)
+				logger.Warn("watch:start-fail", "err", err)
 			} else {
 				defer configWatcher.Stop()
 			}
@@ -58,7 +127,34 @@ func main() {
 	}
 
 	// Start HTTP server (server.New now returns *http.Server)
-	srv := server.New(reg, pm)
+	var hubCfg config.HubConfig
+	var authCfg config.AuthConfig
+	if cfg != nil {
+		hubCfg = cfg.Hub
+		authCfg = cfg.Auth
+	}
+
+	srv := server.New(reg, pm,
+		server.WithUnknownToolPolicy(hubCfg.UnknownTool, hubCfg.FallbackTool),
+		server.WithBasePath(basePath(hubCfg)),
+		server.WithMaxTools(hubCfg.MaxTools, hubCfg.ToolSelection),
+		server.WithToolOrder(hubCfg.ToolOrder),
+		server.WithToolUpdates(hubCfg.ToolUpdates),
+		server.WithClients(hubCfg.Clients),
+		server.WithAPIKeys(authCfg.APIKeys),
+		server.WithBareToolNames(hubCfg.BareToolNames),
+		server.WithToolSeparator(hubCfg.ToolSeparator),
+		server.WithReconcileInterval(time.Duration(hubCfg.ReconcileInterval)*time.Second),
+		server.WithSessionLimits(
+			time.Duration(hubCfg.SessionMaxLifetime)*time.Second,
+			time.Duration(hubCfg.SessionIdleTimeout)*time.Second,
+			hubCfg.MaxSessions,
+		),
+		server.WithReadiness(hubCfg.Readiness),
+		server.WithClientDisconnect(hubCfg.ClientDisconnect),
+		server.WithCapabilities(hubCfg.Capabilities),
+		server.WithLogger(logger),
+	)
 
 	// Allow listen port/address to be overridden via environment variables.
 	// Priority: MCP_HUB_PORT, PORT. If value contains a colon assume it's a full
@@ -78,14 +174,14 @@ func main() {
 	}
 
 	go func() {
-		log.Printf("mcp-hub listening on %s", srv.Addr)
+		logger.Info("listen", "addr", srv.Addr)
 		if err := srv.ListenAndServe(); err != nil {
-			log.Printf("server stopped: %v", err)
+			logger.Info("listen:stopped", "err", err)
 		}
 	}()
 
 	<-ctx.Done()
-	log.Println("shutting down...")
+	logger.Info("shutdown:start")
 
 	// Graceful shutdown
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -93,8 +189,53 @@ func main() {
 
 	_ = srv.Shutdown(shutdownCtx)
 
-	// Give plugins a moment to exit
-	pm.StopAll(shutdownCtx)
+	// Let running tool calls finish, then stop the servers. Open client
+	// streams can use up the HTTP shutdown deadline, so this gets its own.
+	drain := defaultShutdownDrain
+	if hubCfg.ShutdownDrain > 0 {
+		drain = time.Duration(hubCfg.ShutdownDrain) * time.Second
+	}
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), drain)
+	defer drainCancel()
+	pm.StopAll(drainCtx)
+
+	logger.Info("shutdown:done")
+}
+
+// checkServers makes it obvious whether an empty hub was intended: it is
+// valid and logged as such, unless servers are required
+func checkServers(logger *slog.Logger, cfg *config.Config, source string, require bool) error {
+	if cfg != nil && len(cfg.GetEnabledServers()) > 0 {
+		return nil
+	}
+	if require {
+		logger.Error("config:no-servers", "source", source, "reason", "refusing to start (--require-servers)")
+		return errors.New("no servers enabled")
+	}
+	logger.Info("config:no-servers", "source", source, "reason", "serving an empty tool list until servers are added")
+	return nil
+}
+
+// basePath returns the URL prefix to serve under. MCP_HUB_BASE_PATH overrides
+// the config so the same config works behind different proxies.
+func basePath(hubCfg config.HubConfig) string {
+	if p := os.Getenv("MCP_HUB_BASE_PATH"); p != "" {
+		return p
+	}
+	return hubCfg.BasePath
+}
 
-	log.Println("shutdown complete")
+// remoteConfig describes the remote config source. The token is read from
+// MCP_HUB_CONFIG_TOKEN rather than a flag to keep it out of process listings,
+// and is sent as a bearer token unless a custom header is used.
+func remoteConfig(url, header string) *config.Remote {
+	remote := &config.Remote{URL: url, Client: &http.Client{Timeout: 30 * time.Second}}
+	if token := os.Getenv("MCP_HUB_CONFIG_TOKEN"); token != "" && header != "" {
+		remote.HeaderName = header
+		remote.HeaderValue = token
+		if strings.EqualFold(header, "Authorization") {
+			remote.HeaderValue = "Bearer " + token
+		}
+	}
+	return remote
 }
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+)
+
+func TestCheckServers(t *testing.T) {
+	running := &config.Config{MCPServers: map[string]config.ServerConfig{
+		"github": {Command: "github-mcp"},
+	}}
+	disabled := &config.Config{MCPServers: map[string]config.ServerConfig{
+		"github": {Command: "github-mcp", Disabled: true},
+	}}
+
+	tests := []struct {
+		name    string
+		cfg     *config.Config
+		require bool
+		wantErr bool
+		wantLog string
+	}{
+		{name: "servers", cfg: running},
+		{name: "servers required", cfg: running, require: true},
+		{name: "no config", wantLog: "level=INFO msg=config:no-servers"},
+		{name: "empty", cfg: &config.Config{}, wantLog: "level=INFO msg=config:no-servers"},
+		{name: "all disabled", cfg: disabled, wantLog: "level=INFO msg=config:no-servers"},
+		{name: "empty required", cfg: &config.Config{}, require: true, wantErr: true, wantLog: "level=ERROR msg=config:no-servers"},
+		{name: "all disabled required", cfg: disabled, require: true, wantErr: true, wantLog: "level=ERROR msg=config:no-servers"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			err := checkServers(slog.New(slog.NewTextHandler(&logs, nil)), tt.cfg, "config.json", tt.require)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if tt.wantLog == "" && logs.Len() > 0 {
+				t.Errorf("logged %q", logs.String())
+			}
+			if !strings.Contains(logs.String(), tt.wantLog) {
+				t.Errorf("logs %q, want %q", logs.String(), tt.wantLog)
+			}
+		})
+	}
+}
+
+func TestValidateConfigEmpty(t *testing.T) {
+	load := func(context.Context) (*config.Config, error) { return &config.Config{}, nil }
+	for _, require := range []bool{false, true} {
+		var out bytes.Buffer
+		code := validateConfig(context.Background(), &out, load, "config.json", require)
+		want := 0
+		if require {
+			want = 1
+		}
+		if code != want {
+			t.Errorf("require %v: exit code %d, want %d\n%s", require, code, want, &out)
+		}
+		if require && !strings.Contains(out.String(), "no servers enabled") {
+			t.Errorf("output %q doesn't explain the failure", &out)
+		}
+	}
+}
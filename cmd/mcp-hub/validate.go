@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"text/tabwriter"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+)
+
+// validateConfig loads and validates the configuration without starting
+// anything, printing what would start to out. It returns the exit code:
+// 0 if the hub would start every enabled server, 1 otherwise. Servers a
+// lenient hub would skip count as errors, so CI catches them.
+func validateConfig(ctx context.Context, out io.Writer, load func(context.Context) (*config.Config, error), source string, requireServers bool) int {
+	cfg, err := load(ctx)
+	if err != nil {
+		fmt.Fprintf(out, "%s: %v\n", source, err)
+		return 1
+	}
+	active, invalid, err := cfg.ActiveServers()
+	if err != nil {
+		fmt.Fprintf(out, "%s: %v\n", source, err)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "Servers that would start (%d):\n", len(active))
+	for _, name := range slices.Sorted(maps.Keys(active)) {
+		srv := active[name]
+		fmt.Fprintf(w, "  %s\t%s\t%s\n", name, srv.TransportType(), serverTarget(srv))
+	}
+	var disabled []string
+	for name, srv := range cfg.MCPServers {
+		if srv.Disabled {
+			disabled = append(disabled, name)
+		}
+	}
+	if len(disabled) > 0 {
+		slices.Sort(disabled)
+		fmt.Fprintf(w, "Disabled servers (%d):\n", len(disabled))
+		for _, name := range disabled {
+			fmt.Fprintf(w, "  %s\n", name)
+		}
+	}
+	if len(cfg.VirtualServers) > 0 {
+		fmt.Fprintf(w, "Virtual servers (%d):\n", len(cfg.VirtualServers))
+		for _, name := range slices.Sorted(maps.Keys(cfg.VirtualServers)) {
+			fmt.Fprintf(w, "  %s\t%s\n", name, cfg.VirtualServers[name].Server)
+		}
+	}
+	if len(invalid) > 0 {
+		fmt.Fprintf(w, "Invalid servers (%d):\n", len(invalid))
+		for _, name := range slices.Sorted(maps.Keys(invalid)) {
+			fmt.Fprintf(w, "  %s\t%v\n", name, invalid[name])
+		}
+	}
+	w.Flush()
+
+	switch {
+	case len(invalid) > 0:
+		fmt.Fprintf(out, "%s: %d invalid servers\n", source, len(invalid))
+		return 1
+	case len(active) == 0 && requireServers:
+		fmt.Fprintf(out, "%s: no servers enabled (--require-servers)\n", source)
+		return 1
+	}
+	fmt.Fprintf(out, "%s: OK\n", source)
+	return 0
+}
+
+// serverTarget describes what a server runs or connects to. Arguments and
+// headers are left out, they may carry credentials.
+func serverTarget(srv config.ServerConfig) string {
+	switch srv.TransportType() {
+	case "stdio":
+		return srv.Command
+	case "docker":
+		return srv.Image
+	default:
+		return srv.URL
+	}
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentLoad(t *testing.T) {
+	t.Setenv("MCP_HUB_TEST_TOKEN", "s3cret")
+	path := filepath.Join(t.TempDir(), "config.json")
+	doc := `{"mcpServers":{
+		"files":{"command":"mcp-files","args":["--root","$HOME"],"env":{"TOKEN":"$MCP_HUB_TEST_TOKEN"}},
+		"github":{"type":"http","url":"http://github.example/mcp","headers":{"Authorization":"Bearer ${MCP_HUB_TEST_TOKEN}"}}
+	}}`
+	if err := os.WriteFile(path, []byte(doc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	shared, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reloads load and read configs while others change their copies
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				cfg, err := Load(path)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				servers := shared.GetEnabledServers()
+				servers["files"].Env["TOKEN"] = "changed"
+				servers["files"].Args[0] = "--changed"
+				servers["github"].Headers["Authorization"] = "changed"
+
+				clone := cfg.Clone()
+				clone.MCPServers["files"].Env["TOKEN"] = "changed"
+				if got := cfg.MCPServers["files"].Env["TOKEN"]; got != "s3cret" {
+					t.Errorf("loaded TOKEN = %q after changing a clone", got)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	files := shared.MCPServers["files"]
+	if files.Env["TOKEN"] != "s3cret" || files.Args[0] != "--root" {
+		t.Errorf("shared files config changed: %+v", files)
+	}
+	if got := shared.MCPServers["github"].Headers["Authorization"]; got != "Bearer s3cret" {
+		t.Errorf("shared github Authorization = %q", got)
+	}
+}
+
+func TestCloneSharesNothing(t *testing.T) {
+	var cfg Config
+	fillValue(reflect.ValueOf(&cfg).Elem(), 0)
+	clone := cfg.Clone()
+	if !reflect.DeepEqual(&cfg, clone) {
+		t.Fatal("clone differs from the original")
+	}
+	checkUnshared(t, "Config", reflect.ValueOf(cfg), reflect.ValueOf(*clone))
+}
+
+// fillValue sets every exported field reachable from v, giving slices, maps
+// and pointers one element, so that Clone has something to copy
+func fillValue(v reflect.Value, depth int) {
+	if depth > 8 {
+		return
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				fillValue(v.Field(i), depth+1)
+			}
+		}
+	case reflect.Pointer:
+		v.Set(reflect.New(v.Type().Elem()))
+		fillValue(v.Elem(), depth+1)
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fillValue(v.Index(0), depth+1)
+	case reflect.Map:
+		v.Set(reflect.MakeMap(v.Type()))
+		key := reflect.New(v.Type().Key()).Elem()
+		fillValue(key, depth+1)
+		elem := reflect.New(v.Type().Elem()).Elem()
+		fillValue(elem, depth+1)
+		v.SetMapIndex(key, elem)
+	case reflect.Interface:
+		// Decoded JSON, as in experimentalCapabilities
+		v.Set(reflect.ValueOf(map[string]any{"list": []any{"x"}}))
+	case reflect.String:
+		v.SetString("x")
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1)
+	}
+}
+
+// checkUnshared fails if a and b, a value and its clone, share the backing
+// memory of any slice, map or pointer
+func checkUnshared(t *testing.T, path string, a, b reflect.Value) {
+	t.Helper()
+	switch a.Kind() {
+	case reflect.Struct:
+		for i := range a.NumField() {
+			if a.Type().Field(i).IsExported() {
+				checkUnshared(t, path+"."+a.Type().Field(i).Name, a.Field(i), b.Field(i))
+			}
+		}
+	case reflect.Pointer:
+		if a.IsNil() {
+			return
+		}
+		if a.Pointer() == b.Pointer() {
+			t.Errorf("%s is shared with the clone", path)
+			return
+		}
+		checkUnshared(t, path, a.Elem(), b.Elem())
+	case reflect.Slice:
+		if a.Len() == 0 {
+			return
+		}
+		if a.Pointer() == b.Pointer() {
+			t.Errorf("%s is shared with the clone", path)
+			return
+		}
+		for i := range a.Len() {
+			checkUnshared(t, path+"[]", a.Index(i), b.Index(i))
+		}
+	case reflect.Map:
+		if a.Len() == 0 {
+			return
+		}
+		if a.Pointer() == b.Pointer() {
+			t.Errorf("%s is shared with the clone", path)
+			return
+		}
+		for _, key := range a.MapKeys() {
+			checkUnshared(t, path+"[]", a.MapIndex(key), b.MapIndex(key))
+		}
+	case reflect.Interface:
+		if !a.IsNil() {
+			checkUnshared(t, path, a.Elem(), b.Elem())
+		}
+	}
+}
@@ -3,14 +3,279 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
+	"unicode"
 )
 
+// labelNameRe matches valid Prometheus label names
+var labelNameRe = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// imageRefRe matches docker image references: an optional registry host
+// with port, lowercase path components, an optional tag and an optional
+// digest, e.g. ghcr.io/org/server:1.2@sha256:...
+var imageRefRe = regexp.MustCompile(`^` +
+	`(?:(?:localhost|[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?(?:\.[a-zA-Z0-9](?:[a-zA-Z0-9-]*[a-zA-Z0-9])?)+|[a-zA-Z0-9-]+:[0-9]+)(?::[0-9]+)?/)?` +
+	`[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|-+)[a-z0-9]+)*)*` +
+	`(?::[a-zA-Z0-9_][a-zA-Z0-9_.-]{0,127})?` +
+	`(?:@[a-zA-Z][a-zA-Z0-9]*(?:[-_+.][a-zA-Z][a-zA-Z0-9]*)*:[0-9a-fA-F]{32,})?$`)
+
+// memoryRe matches docker memory quantities such as 512m, 1.5g or 2GiB
+var memoryRe = regexp.MustCompile(`^[0-9]+(?:\.[0-9]+)?\s?(?:[kKmMgGtTpP][iI]?)?[bB]?$`)
+
+// reservedLabels are label names used by the hub's own metrics
+var reservedLabels = map[string]bool{
+	"plugin": true,
+	"tool":   true,
+	"status": true,
+	"state":  true,
+}
+
+// maxLabels bounds the number of custom labels per server
+const maxLabels = 10
+
 // Config represents the MCP hub configuration
 type Config struct {
 	MCPServers map[string]ServerConfig `json:"mcpServers"`
+	Hub        HubConfig               `json:"hub,omitempty"`
+
+	// Subsets of a server's tools exposed under their own namespace
+	VirtualServers map[string]VirtualServer `json:"virtualServers,omitempty"`
+
+	// Authentication of requests to the hub itself
+	Auth AuthConfig `json:"auth,omitempty"`
+}
+
+// AuthConfig protects the hub's MCP endpoint and API
+type AuthConfig struct {
+	// Keys accepted as "Authorization: Bearer <key>", each allowed to call
+	// any tool. Without keys or hub clients the hub is open.
+	APIKeys []string `json:"apiKeys,omitempty"`
+}
+
+// VirtualServer exposes some tools of a real server as if they were a
+// server of their own, calls are routed to the real server
+type VirtualServer struct {
+	// Name of the server in mcpServers providing the tools
+	Server string `json:"server"`
+	// Glob patterns matched against the real server's tool names
+	Tools []string `json:"tools"`
+}
+
+// Exposes reports whether the virtual server includes the named tool
+func (v VirtualServer) Exposes(tool string) bool {
+	for _, pattern := range v.Tools {
+		if ok, _ := path.Match(pattern, tool); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// CacheTTLFor returns the seconds results of a tool are cached, 0 if they
+// aren't. The longest cacheTTL pattern matching the tool wins.
+func (s ServerConfig) CacheTTLFor(tool string) int {
+	ttl, best := 0, ""
+	for pattern, seconds := range s.CacheTTL {
+		if ok, _ := path.Match(pattern, tool); !ok {
+			continue
+		}
+		if best == "" || len(pattern) > len(best) || len(pattern) == len(best) && pattern < best {
+			ttl, best = seconds, pattern
+		}
+	}
+	return ttl
+}
+
+// ExposesTool reports whether the server's includeTools and excludeTools let
+// the named tool through
+func (s ServerConfig) ExposesTool(tool string) bool {
+	for _, pattern := range s.ExcludeTools {
+		if ok, _ := path.Match(pattern, tool); ok {
+			return false
+		}
+	}
+	if len(s.IncludeTools) == 0 {
+		return true
+	}
+	for _, pattern := range s.IncludeTools {
+		if ok, _ := path.Match(pattern, tool); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// HubConfig holds settings for the hub itself rather than a single server
+type HubConfig struct {
+	// How calls to unknown tools are answered: "error" (default),
+	// "suggest" (list closest tool names) or "fallback"
+	UnknownTool string `json:"unknownTool,omitempty"`
+	// Namespaced tool receiving unknown calls when UnknownTool is "fallback"
+	FallbackTool string `json:"fallbackTool,omitempty"`
+
+	// How calls naming a tool without namespace are resolved: "single"
+	// (default), "search" or "strict"
+	BareToolNames string `json:"bareToolNames,omitempty"`
+
+	// Joins a server's prefix and its tool names in exposed tool names
+	// (default ":"), e.g. "_" or "__" for clients rejecting colons
+	ToolSeparator string `json:"toolSeparator,omitempty"`
+
+	// How invalid servers are handled: "strict" (default) rejects the whole
+	// config, "lenient" starts the valid servers and reports the rest
+	Validation string `json:"validation,omitempty"`
+
+	// URL path prefix all routes are served under (e.g. "/mcp-hub")
+	BasePath string `json:"basePath,omitempty"`
+
+	// Limits on client sessions: lifetime and idle time in seconds, and the
+	// number of open sessions (0 disables each)
+	SessionMaxLifetime int `json:"sessionMaxLifetime,omitempty"`
+	SessionIdleTimeout int `json:"sessionIdleTimeout,omitempty"`
+	MaxSessions        int `json:"maxSessions,omitempty"`
+
+	// When /readyz reports ready: "any" (default) once one server is
+	// connected, "all" once every server meant to run is connected
+	Readiness string `json:"readiness,omitempty"`
+
+	// Maximum number of servers running at once, a guardrail for generated
+	// configs (0 means unlimited)
+	MaxServers int `json:"maxServers,omitempty"`
+
+	// Seconds a shutdown waits for running tool calls before closing the
+	// servers anyway (default 30)
+	ShutdownDrain int `json:"shutdownDrain,omitempty"`
+
+	// Tool argument, result and header keys whose values are logged as
+	// "***", besides built-in ones such as token and password
+	Redact []string `json:"redact,omitempty"`
+
+	// Limits on how often each client may call each tool, off when nil
+	RateLimit *RateLimitConfig `json:"rateLimit,omitempty"`
+
+	// How the config file is watched: "fsnotify" (default), falling back to
+	// polling where notifications are unavailable, or "poll"
+	WatchMode string `json:"watchMode,omitempty"`
+	// How often the config file is polled when file change notifications
+	// are unavailable or not used (in seconds, default 2)
+	WatchPollInterval int `json:"watchPollInterval,omitempty"`
+	// How config changes are applied: "server" (default) restarts each
+	// changed server, "graceful" connects the new set alongside the old
+	// and swaps to it in one step
+	ReloadMode string `json:"reloadMode,omitempty"`
+
+	// What happens to a tool call whose client disconnected: "cancel"
+	// (default) cancels the backend call, "continue" lets it finish
+	ClientDisconnect string `json:"clientDisconnect,omitempty"`
+
+	// Capabilities advertised to clients: "static" (default) always
+	// advertises prompts, "backends" only while a backend provides some
+	Capabilities string `json:"capabilities,omitempty"`
+
+	// How often the running servers are compared with the config on disk
+	// (in seconds, 0 disables)
+	DriftCheckInterval int `json:"driftCheckInterval,omitempty"`
+	// Start, stop or reload servers found to differ from the config
+	DriftRepair bool `json:"driftRepair,omitempty"`
+
+	// Maximum number of tools returned by tools/list (0 means unlimited)
+	MaxTools int `json:"maxTools,omitempty"`
+	// Which tools are kept under MaxTools: "first" (default), "priority"
+	// or "usage"
+	ToolSelection string `json:"toolSelection,omitempty"`
+	// Order of tools in tools/list: "name" (default), "listed" (servers
+	// by name, each server's tools as it listed them) or "priority"
+	// (servers by priority, each server's tools as it listed them)
+	ToolOrder string `json:"toolOrder,omitempty"`
+
+	// How changed definitions of exposed tools are handled: "update"
+	// (default) or "ignore"
+	ToolUpdates string `json:"toolUpdates,omitempty"`
+
+	// How often exposed tools are reconciled with the registry (in seconds,
+	// default 60)
+	ReconcileInterval int `json:"reconcileInterval,omitempty"`
+
+	// Default for servers without their own maxArgumentsSize
+	MaxArgumentsSize int `json:"maxArgumentsSize,omitempty"`
+
+	// Memory for tool results cached by servers' cacheTTL, the least
+	// recently used are dropped beyond it (in bytes, default 64 MiB)
+	ResultCacheSize int `json:"resultCacheSize,omitempty"`
+
+	// Clients allowed to connect, by name. When set, every request needs
+	// the bearer token of one of them.
+	Clients map[string]ClientConfig `json:"clients,omitempty"`
+
+	// OpenTelemetry export of tool call spans and metrics, off when nil
+	Telemetry *TelemetryConfig `json:"telemetry,omitempty"`
+}
+
+// RateLimit is a token bucket: calls per second, and calls allowed at once
+// after a quiet period
+type RateLimit struct {
+	RPS   float64 `json:"rps"`
+	Burst int     `json:"burst,omitempty"`
+}
+
+// RateLimitConfig limits tool calls with a bucket per client and tool, so a
+// busy tool doesn't hold back others. Unauthenticated callers share one
+// client's buckets.
+type RateLimitConfig struct {
+	// Limit of tools without an override (rps 0 means unlimited)
+	RateLimit
+	// Overrides keyed by "<plugin>:<tool>" glob patterns, the longest
+	// matching pattern wins
+	Tools map[string]RateLimit `json:"tools,omitempty"`
+}
+
+// For returns the limit of a tool
+func (r *RateLimitConfig) For(pluginID, toolName string) RateLimit {
+	name := pluginID + ":" + toolName
+	limit, best := r.RateLimit, ""
+	for pattern, l := range r.Tools {
+		if ok, _ := path.Match(pattern, name); !ok {
+			continue
+		}
+		if best == "" || len(pattern) > len(best) || len(pattern) == len(best) && pattern < best {
+			limit, best = l, pattern
+		}
+	}
+	return limit
+}
+
+func (l RateLimit) validate(prefix string) error {
+	if l.RPS < 0 {
+		return fmt.Errorf("%s: rps must not be negative", prefix)
+	}
+	if l.Burst < 0 {
+		return fmt.Errorf("%s: burst must not be negative", prefix)
+	}
+	return nil
+}
+
+// TelemetryConfig configures OTLP/HTTP export
+type TelemetryConfig struct {
+	// Collector base URL, e.g. "http://otel-collector:4318"
+	Endpoint    string            `json:"endpoint"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	ServiceName string            `json:"serviceName,omitempty"`
+	// Metric export interval in seconds (default 60)
+	MetricInterval int `json:"metricInterval,omitempty"`
+}
+
+// ClientConfig is a client of the hub and its access policy
+type ClientConfig struct {
+	Token string `json:"token"`
+	// Glob patterns of "<plugin>:<tool>" names the client may call
+	Allow []string `json:"allow,omitempty"`
 }
 
 // ServerConfig represents a single MCP server configuration
@@ -19,6 +284,145 @@ type ServerConfig struct {
 	Disabled bool              `json:"disabled,omitempty"`
 	Timeout  int               `json:"timeout,omitempty"` // in seconds
 	Env      map[string]string `json:"env,omitempty"`
+	// Whether a stdio server gets the whole host environment besides its
+	// env (default true). Without it, it only gets PATH and EnvPassthrough.
+	InheritEnv *bool `json:"inheritEnv,omitempty"`
+	// Host environment variables (names or glob patterns) passed to a stdio
+	// server that doesn't inherit the environment, or into a docker container
+	EnvPassthrough []string `json:"envPassthrough,omitempty"`
+	// What a call exceeding Timeout returns: "error" (default) or
+	// "partial" for the output the backend streamed so far
+	OnTimeout string `json:"onTimeout,omitempty"`
+	// Skip the server with a log line instead of a startup warning when its
+	// runtime (docker) is unavailable
+	Optional bool `json:"optional,omitempty"`
+
+	// Server retried with the same call when this one fails
+	Standby string `json:"standby,omitempty"`
+	// Failures moving a call to the standby: "unavailable", "error",
+	// "timeout" and "toolError" (default all but "toolError")
+	FailoverOn []string `json:"failoverOn,omitempty"`
+	// Time limit for calls to this server before failing over to the
+	// standby (in seconds, 0 for none)
+	FailoverTimeout int `json:"failoverTimeout,omitempty"`
+
+	// How HTTP rate-limit hints are handled: "retry" (default) retries 429s
+	// after Retry-After, "throttle" also pauses while the quota reported by
+	// X-RateLimit-Remaining is exhausted, "ignore" passes them through
+	RateLimitHints string `json:"rateLimitHints,omitempty"`
+
+	// Whether the backend can handle concurrent calls: "serial" or
+	// "parallel". Defaults to serial for stdio and docker, parallel for HTTP.
+	ConcurrencyModel string `json:"concurrencyModel,omitempty"`
+	// Calls a parallel server runs at once, further calls wait in its queue
+	// so a slow server can't tie up an unbounded number of them (default 16)
+	MaxConcurrency int `json:"maxConcurrency,omitempty"`
+	// Calls allowed to wait for a busy server before new ones are rejected
+	// (0 means unlimited)
+	MaxQueue int `json:"maxQueue,omitempty"`
+
+	// Largest serialized tool call arguments forwarded to this server
+	// (in bytes, 0 uses the hub default, unlimited if that is unset)
+	MaxArgumentsSize int `json:"maxArgumentsSize,omitempty"`
+	// Check tool call arguments against the tool's input schema before
+	// forwarding them, off by default as some servers publish loose schemas
+	ValidateArgs bool `json:"validateArgs,omitempty"`
+	// Seconds a tool's successful results are reused for calls with the
+	// same arguments, keyed by tool name or glob pattern (0 disables)
+	CacheTTL map[string]int `json:"cacheTTL,omitempty"`
+	// Log a truncated snippet of each tool result, like the arguments are
+	LogResults bool `json:"logResults,omitempty"`
+	// Forward JSON-RPC errors from this server to clients with their
+	// original code, message and data
+	ForwardErrors bool `json:"forwardErrors,omitempty"`
+
+	// Priority used when the hub caps the exposed tool list (higher first)
+	Priority int `json:"priority,omitempty"`
+
+	// Prefix of the server's exposed tool names instead of its name
+	ToolPrefix string `json:"toolPrefix,omitempty"`
+
+	// Servers with the same group are replicas: their tools are listed once
+	// under the group's name and calls are spread over the running members
+	Group string `json:"group,omitempty"`
+	// Share of the group's calls relative to the other members (default 1)
+	Weight int `json:"weight,omitempty"`
+
+	// Custom metric labels attached to this server's series (team, env, ...)
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Experimental capabilities advertised to this server during initialize,
+	// for backends that only enable some tools when a capability is echoed
+	ExperimentalCapabilities map[string]any `json:"experimentalCapabilities,omitempty"`
+	// Fields merged into the initialize request params, an escape hatch for
+	// backends expecting non-standard handshake data. Must be a JSON object.
+	InitializeExtra json.RawMessage `json:"initializeExtra,omitempty"`
+
+	// How long to wait after the initial tools/list for late tool
+	// announcements before registering (in milliseconds, 0 disables)
+	DiscoveryWindow int `json:"discoveryWindow,omitempty"`
+
+	// Give up reconnecting after the server has been failing continuously
+	// for this long, until the next reload (in seconds, 0 retries forever)
+	GiveUpAfter int `json:"giveUpAfter,omitempty"`
+	// Whether a server whose session ended unexpectedly is restarted:
+	// "always" (default) or "never"
+	RestartPolicy string `json:"restartPolicy,omitempty"`
+	// Backoff between restart attempts, doubling from the base delay up to
+	// the max delay (in seconds, default 1 and 60)
+	RestartBaseDelay int `json:"restartBaseDelay,omitempty"`
+	RestartMaxDelay  int `json:"restartMaxDelay,omitempty"`
+	// Give up after this many failed restart attempts (0 retries forever)
+	MaxRestarts int `json:"maxRestarts,omitempty"`
+	// Retries of a start whose connect or tools/list failed transiently,
+	// e.g. a backend busy right after connecting, doubling the delay from
+	// startRetryDelay (in milliseconds, default 500)
+	StartRetries    int `json:"startRetries,omitempty"`
+	StartRetryDelay int `json:"startRetryDelay,omitempty"`
+
+	// Reject calls for breakerCooldown seconds (default 30) once
+	// breakerThreshold calls in a row failed within breakerWindow seconds
+	// (default 60), then let one call through to probe (0 disables)
+	BreakerThreshold int `json:"breakerThreshold,omitempty"`
+	BreakerWindow    int `json:"breakerWindow,omitempty"`
+	BreakerCooldown  int `json:"breakerCooldown,omitempty"`
+
+	// Setup step run after connecting and before registering tools
+	Init *InitHook `json:"init,omitempty"`
+
+	// Filesystem roots returned when the server asks for roots/list
+	Roots []Root `json:"roots,omitempty"`
+
+	// External processes rewriting arguments and results, by tool name
+	Transforms map[string]ToolTransform `json:"transforms,omitempty"`
+
+	// How tools listed more than once by this server are handled: "first"
+	// (default), "last" or "error"
+	DuplicateTools string `json:"duplicateTools,omitempty"`
+	// How tools listed without an input schema are handled: "object"
+	// (default) substitutes a schema accepting any object, "reject" drops
+	// them, "passthrough" registers them without a schema
+	MissingInputSchema string `json:"missingInputSchema,omitempty"`
+
+	// Glob patterns selecting which of the server's tools are exposed, all
+	// if empty. Exclusions win over inclusions.
+	IncludeTools []string `json:"includeTools,omitempty"`
+	ExcludeTools []string `json:"excludeTools,omitempty"`
+
+	// Keepalive ping interval for detecting backends that are running but
+	// no longer answering (in seconds, 0 disables)
+	PingInterval int `json:"pingInterval,omitempty"`
+	// Request used as the keepalive ping: "ping" (default) or "tools/list"
+	// for backends that don't implement ping
+	PingMethod string `json:"pingMethod,omitempty"`
+
+	// JSON-RPC request ID encoding for backends that only accept one form
+	// ("int" or "string", default "int")
+	IDFormat string `json:"idFormat,omitempty"`
+	// What to do when the server answers initialize with a protocol version
+	// the hub doesn't support: "strict" (default) fails the connect,
+	// "lenient" logs a warning and proceeds
+	VersionPolicy string `json:"versionPolicy,omitempty"`
 
 	// Transport type (stdio, sse, http, streamable-http, docker)
 	Type string `json:"type,omitempty"` // if not specified, inferred from command/url/image
@@ -30,16 +434,69 @@ type ServerConfig struct {
 	// For HTTP transports (SSE, Streamable HTTP)
 	URL     string            `json:"url,omitempty"`
 	Headers map[string]string `json:"headers,omitempty"`
+	// Headers resolved on every request rather than once at load, so
+	// rotating tokens apply without a reload
+	DynamicHeaders map[string]HeaderSource `json:"dynamicHeaders,omitempty"`
 
 	// For Docker transport
 	Image   string            `json:"image,omitempty"`   // Docker image name
 	Volumes map[string]string `json:"volumes,omitempty"` // host:container volume mappings
 	Network string            `json:"network,omitempty"` // Docker network name
+	Runtime string            `json:"runtime,omitempty"` // container CLI: docker (default), podman or nerdctl
+
+	// Container resource limits, unlimited if unset
+	Memory    string  `json:"memory,omitempty"`    // e.g. "512m" or "2g"
+	CPUs      float64 `json:"cpus,omitempty"`      // e.g. 1.5
+	PidsLimit int     `json:"pidsLimit,omitempty"` // processes in the container
 
 	// Legacy support - if transport not specified in type field
 	Transport string `json:"transport,omitempty"` // "stdio", "sse", "docker", etc.
 }
 
+// Root is a filesystem root offered to a server
+type Root struct {
+	// file:// URI of the root
+	URI  string `json:"uri"`
+	Name string `json:"name,omitempty"`
+}
+
+// HeaderSource resolves a header value from an environment variable or the
+// output of a command, e.g. a credential helper
+type HeaderSource struct {
+	Env     string   `json:"env,omitempty"`
+	Command []string `json:"command,omitempty"`
+	// Prepended to the value, e.g. "Bearer "
+	Prefix string `json:"prefix,omitempty"`
+	// Seconds a command's output is reused (0 runs it on every request)
+	CacheSeconds int `json:"cacheSeconds,omitempty"`
+}
+
+// InitHook is a setup step for a server, either a local command or a tool
+// call on the server itself. A failing hook fails the server start.
+type InitHook struct {
+	// Command to run locally (argv form, no shell), with the server's env
+	Command []string `json:"command,omitempty"`
+
+	// Tool to call on the server, with Arguments
+	Tool      string         `json:"tool,omitempty"`
+	Arguments map[string]any `json:"arguments,omitempty"`
+
+	// Timeout in seconds (default 30)
+	Timeout int `json:"timeout,omitempty"`
+}
+
+// ToolTransform runs external processes on a tool's arguments before they
+// are forwarded and on its result before it is returned. Each process gets a
+// JSON document on stdin and writes the replacement JSON to stdout.
+type ToolTransform struct {
+	// Command (argv form) rewriting the arguments object
+	Input []string `json:"input,omitempty"`
+	// Command (argv form) rewriting the CallToolResult
+	Output []string `json:"output,omitempty"`
+	// Timeout per process in seconds (default 10)
+	Timeout int `json:"timeout,omitempty"`
+}
+
 // TransportType returns the normalized transport type
 func (s *ServerConfig) TransportType() string {
 	// Check Type field first
@@ -54,6 +511,9 @@ func (s *ServerConfig) TransportType() string {
 	if s.Image != "" {
 		return "docker"
 	}
+	if strings.HasPrefix(s.URL, "ws://") || strings.HasPrefix(s.URL, "wss://") {
+		return "ws"
+	}
 	if s.URL != "" {
 		return "http"
 	}
@@ -63,6 +523,29 @@ func (s *ServerConfig) TransportType() string {
 	return "stdio" // default
 }
 
+// ContainerRuntime returns the CLI running the server's container
+func (s *ServerConfig) ContainerRuntime() string {
+	if s.Runtime == "" {
+		return "docker"
+	}
+	return s.Runtime
+}
+
+// Serial reports whether calls to the server must be sent one at a time
+func (s *ServerConfig) Serial() bool {
+	switch s.ConcurrencyModel {
+	case "serial":
+		return true
+	case "parallel":
+		return false
+	}
+	switch s.TransportType() {
+	case "stdio", "docker":
+		return true
+	}
+	return false
+}
+
 func normalizeTransport(t string) string {
 	t = strings.ToLower(strings.TrimSpace(t))
 	switch t {
@@ -70,6 +553,8 @@ func normalizeTransport(t string) string {
 		return "stdio"
 	case "sse":
 		return "sse"
+	case "ws", "wss", "websocket":
+		return "ws"
 	case "http", "streamable-http", "streamablehttp":
 		return "http"
 	case "docker", "container":
@@ -79,7 +564,9 @@ func normalizeTransport(t string) string {
 	}
 }
 
-// Load reads and parses the configuration file
+// Load reads and parses the configuration file. A directory is read as a
+// set of config fragments merged into one config, and files ending in .yaml
+// or .yml are parsed as YAML.
 func Load(path string) (*Config, error) {
 	// Expand ~ to home directory
 	if strings.HasPrefix(path, "~/") {
@@ -90,11 +577,30 @@ func Load(path string) (*Config, error) {
 		path = filepath.Join(home, path[2:])
 	}
 
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		return loadDir(path)
+	}
+
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	if ext := strings.ToLower(filepath.Ext(path)); ext == ".yaml" || ext == ".yml" {
+		cfg, err := decode(path, data)
+		if err != nil {
+			return nil, err
+		}
+		if err := cfg.processEnvVars(); err != nil {
+			return nil, err
+		}
+		return cfg, nil
+	}
+	return Parse(data)
+}
+
+// Parse decodes a configuration document and expands environment variables
+func Parse(data []byte) (*Config, error) {
 	var cfg Config
 	if err := json.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
@@ -108,96 +614,850 @@ func Load(path string) (*Config, error) {
 	return &cfg, nil
 }
 
-// processEnvVars expands environment variables in configuration
+// processEnvVars expands environment variables in configuration. Every
+// expanded map and slice is freshly allocated so the result never shares
+// state with the decoded input.
 func (c *Config) processEnvVars() error {
+	servers := make(map[string]ServerConfig, len(c.MCPServers))
 	for name, srv := range c.MCPServers {
-		// Expand environment variables in env values
-		if srv.Env != nil {
-			for k, v := range srv.Env {
-				srv.Env[k] = os.ExpandEnv(v)
+		srv = srv.Clone()
+
+		// Expand environment variables in env values, then read the ones
+		// naming a secret file. Disabled servers may refer to files that
+		// only exist where they run.
+		srv.Env = expandValues(srv.Env)
+		if !srv.Disabled {
+			if err := readSecretFiles(srv.Env); err != nil {
+				return fmt.Errorf("server %s: %w", name, err)
 			}
 		}
 
 		// Expand in command
-		if srv.Command != "" {
-			srv.Command = os.ExpandEnv(srv.Command)
-		}
+		srv.Command = os.ExpandEnv(srv.Command)
 
 		// Expand in args
 		for i, arg := range srv.Args {
 			srv.Args[i] = os.ExpandEnv(arg)
 		}
 
-		// Expand in URL
-		if srv.URL != "" {
-			srv.URL = os.ExpandEnv(srv.URL)
+		// Expand in init command
+		if srv.Init != nil {
+			for i, arg := range srv.Init.Command {
+				srv.Init.Command[i] = os.ExpandEnv(arg)
+			}
 		}
 
+		// Expand in transform commands
+		for _, tr := range srv.Transforms {
+			for i, arg := range tr.Input {
+				tr.Input[i] = os.ExpandEnv(arg)
+			}
+			for i, arg := range tr.Output {
+				tr.Output[i] = os.ExpandEnv(arg)
+			}
+		}
+
+		// Expand in roots
+		for i, root := range srv.Roots {
+			srv.Roots[i].URI = os.ExpandEnv(root.URI)
+		}
+
+		// Expand in URL
+		srv.URL = os.ExpandEnv(srv.URL)
+
 		// Expand in headers
-		if srv.Headers != nil {
-			for k, v := range srv.Headers {
-				srv.Headers[k] = os.ExpandEnv(v)
+		srv.Headers = expandValues(srv.Headers)
+
+		// Expand in header commands, their output is resolved per request
+		for _, src := range srv.DynamicHeaders {
+			for i, arg := range src.Command {
+				src.Command[i] = os.ExpandEnv(arg)
 			}
 		}
 
 		// Expand in Docker image
-		if srv.Image != "" {
-			srv.Image = os.ExpandEnv(srv.Image)
-		}
+		srv.Image = os.ExpandEnv(srv.Image)
 
 		// Expand in volumes (both keys and values)
 		if srv.Volumes != nil {
-			newVolumes := make(map[string]string)
+			newVolumes := make(map[string]string, len(srv.Volumes))
 			for k, v := range srv.Volumes {
-				newKey := os.ExpandEnv(k)
-				newVal := os.ExpandEnv(v)
-				newVolumes[newKey] = newVal
+				newVolumes[os.ExpandEnv(k)] = os.ExpandEnv(v)
 			}
 			srv.Volumes = newVolumes
 		}
 
-		c.MCPServers[name] = srv
+		servers[name] = srv
+	}
+	c.MCPServers = servers
+
+	// Expand in telemetry settings
+	if t := c.Hub.Telemetry; t != nil {
+		c.Hub.Telemetry = &TelemetryConfig{
+			Endpoint:       os.ExpandEnv(t.Endpoint),
+			Headers:        expandValues(t.Headers),
+			ServiceName:    t.ServiceName,
+			MetricInterval: t.MetricInterval,
+		}
+	}
+
+	// Expand in API keys
+	if c.Auth.APIKeys != nil {
+		keys := make([]string, len(c.Auth.APIKeys))
+		for i, key := range c.Auth.APIKeys {
+			keys[i] = os.ExpandEnv(key)
+		}
+		c.Auth.APIKeys = keys
+	}
+
+	// Expand in client tokens
+	if c.Hub.Clients != nil {
+		clients := make(map[string]ClientConfig, len(c.Hub.Clients))
+		for name, client := range c.Hub.Clients {
+			client.Token = os.ExpandEnv(client.Token)
+			client.Allow = slices.Clone(client.Allow)
+			clients[name] = client
+		}
+		c.Hub.Clients = clients
+	}
+	return nil
+}
+
+// expandValues returns a copy of m with environment variables expanded in values
+func expandValues(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = os.ExpandEnv(v)
+	}
+	return out
+}
+
+// secretFilePrefix marks an env value read from a file, e.g. a docker or
+// Kubernetes secret mounted at /run/secrets/token
+const secretFilePrefix = "file:"
+
+// readSecretFiles replaces env values with the file: prefix by the contents
+// of the named file, without its trailing newline
+func readSecretFiles(env map[string]string) error {
+	for k, v := range env {
+		path, ok := strings.CutPrefix(v, secretFilePrefix)
+		if !ok {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("env %s: failed to read secret file: %w", k, err)
+		}
+		env[k] = strings.TrimRight(string(data), "\r\n")
 	}
 	return nil
 }
 
+// Clone returns a deep copy of the configuration
+func (c *Config) Clone() *Config {
+	out := *c
+	if c.MCPServers != nil {
+		out.MCPServers = make(map[string]ServerConfig, len(c.MCPServers))
+		for name, srv := range c.MCPServers {
+			out.MCPServers[name] = srv.Clone()
+		}
+	}
+	if c.VirtualServers != nil {
+		out.VirtualServers = make(map[string]VirtualServer, len(c.VirtualServers))
+		for name, v := range c.VirtualServers {
+			v.Tools = slices.Clone(v.Tools)
+			out.VirtualServers[name] = v
+		}
+	}
+	out.Auth.APIKeys = slices.Clone(c.Auth.APIKeys)
+	out.Hub.Redact = slices.Clone(c.Hub.Redact)
+	if c.Hub.Telemetry != nil {
+		t := *c.Hub.Telemetry
+		t.Headers = maps.Clone(t.Headers)
+		out.Hub.Telemetry = &t
+	}
+	if c.Hub.RateLimit != nil {
+		r := *c.Hub.RateLimit
+		r.Tools = maps.Clone(r.Tools)
+		out.Hub.RateLimit = &r
+	}
+	if c.Hub.Clients != nil {
+		out.Hub.Clients = make(map[string]ClientConfig, len(c.Hub.Clients))
+		for name, client := range c.Hub.Clients {
+			client.Allow = slices.Clone(client.Allow)
+			out.Hub.Clients[name] = client
+		}
+	}
+	return &out
+}
+
+// InheritsEnv reports whether a stdio server gets the whole host environment
+func (s ServerConfig) InheritsEnv() bool {
+	return s.InheritEnv == nil || *s.InheritEnv
+}
+
+// InitializeExtraObject decodes InitializeExtra, nil if unset or not an object
+func (s ServerConfig) InitializeExtraObject() map[string]any {
+	if len(s.InitializeExtra) == 0 {
+		return nil
+	}
+	var extra map[string]any
+	if err := json.Unmarshal(s.InitializeExtra, &extra); err != nil {
+		return nil
+	}
+	return extra
+}
+
+// Clone returns a deep copy of the server configuration
+func (s ServerConfig) Clone() ServerConfig {
+	s.Env = maps.Clone(s.Env)
+	s.Labels = maps.Clone(s.Labels)
+	s.CacheTTL = maps.Clone(s.CacheTTL)
+	s.Args = slices.Clone(s.Args)
+	if s.InheritEnv != nil {
+		inherit := *s.InheritEnv
+		s.InheritEnv = &inherit
+	}
+	s.EnvPassthrough = slices.Clone(s.EnvPassthrough)
+	s.IncludeTools = slices.Clone(s.IncludeTools)
+	s.ExcludeTools = slices.Clone(s.ExcludeTools)
+	s.FailoverOn = slices.Clone(s.FailoverOn)
+	s.Roots = slices.Clone(s.Roots)
+	s.Headers = maps.Clone(s.Headers)
+	if s.DynamicHeaders != nil {
+		sources := make(map[string]HeaderSource, len(s.DynamicHeaders))
+		for name, src := range s.DynamicHeaders {
+			src.Command = slices.Clone(src.Command)
+			sources[name] = src
+		}
+		s.DynamicHeaders = sources
+	}
+	s.Volumes = maps.Clone(s.Volumes)
+	s.InitializeExtra = slices.Clone(s.InitializeExtra)
+	if s.ExperimentalCapabilities != nil {
+		s.ExperimentalCapabilities = cloneValue(s.ExperimentalCapabilities).(map[string]any)
+	}
+	if s.Transforms != nil {
+		transforms := make(map[string]ToolTransform, len(s.Transforms))
+		for tool, tr := range s.Transforms {
+			tr.Input = slices.Clone(tr.Input)
+			tr.Output = slices.Clone(tr.Output)
+			transforms[tool] = tr
+		}
+		s.Transforms = transforms
+	}
+	if s.Init != nil {
+		hook := *s.Init
+		hook.Command = slices.Clone(hook.Command)
+		if hook.Arguments != nil {
+			hook.Arguments = cloneValue(hook.Arguments).(map[string]any)
+		}
+		s.Init = &hook
+	}
+	return s
+}
+
+// cloneValue deep copies a decoded JSON value
+func cloneValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[k] = cloneValue(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = cloneValue(e)
+		}
+		return out
+	default:
+		return v
+	}
+}
+
 // Validate checks if the configuration is valid
 func (c *Config) Validate() error {
+	if err := c.Hub.validate(); err != nil {
+		return err
+	}
+	if err := c.validateAuth(); err != nil {
+		return err
+	}
+
 	for name, srv := range c.MCPServers {
 		if srv.Disabled {
 			continue
 		}
+		if err := validateServer(name, srv); err != nil {
+			return err
+		}
+		if err := c.validateStandby(name, srv); err != nil {
+			return err
+		}
+	}
+	if err := c.validateVirtualServers(); err != nil {
+		return err
+	}
+	return c.validateToolPrefixes()
+}
+
+// validateAuth checks that API keys are set and distinct from each other and
+// from client tokens
+func (c *Config) validateAuth() error {
+	seen := make(map[string]bool, len(c.Auth.APIKeys))
+	for _, client := range c.Hub.Clients {
+		seen[client.Token] = true
+	}
+	for i, key := range c.Auth.APIKeys {
+		if key == "" {
+			return fmt.Errorf("auth: apiKeys[%d] is empty", i)
+		}
+		if seen[key] {
+			return fmt.Errorf("auth: apiKeys[%d] is used more than once or as a client token", i)
+		}
+		seen[key] = true
+	}
+	return nil
+}
 
-		transport := srv.TransportType()
-		switch transport {
-		case "stdio":
-			if srv.Command == "" {
-				return fmt.Errorf("server %s: command is required for stdio transport", name)
-			}
-		case "sse":
-			if srv.URL == "" {
-				return fmt.Errorf("server %s: url is required for sse transport", name)
+// validateToolPrefixes checks that no two servers expose their tools under the
+// same prefix, a server's prefix being its group, toolPrefix or name
+func (c *Config) validateToolPrefixes() error {
+	owners := make(map[string]string)
+	groups := make(map[string]bool)
+	for name := range c.VirtualServers {
+		owners[name] = name
+	}
+	for _, name := range slices.Sorted(maps.Keys(c.MCPServers)) {
+		srv := c.MCPServers[name]
+		if srv.Disabled {
+			continue
+		}
+		// Members of a group share its name as their prefix
+		if g := srv.Group; g != "" {
+			if _, ok := c.MCPServers[g]; ok {
+				return fmt.Errorf("server %s: group %q is already used as a server name", name, g)
 			}
-		case "http":
-			if srv.URL == "" {
-				return fmt.Errorf("server %s: url is required for http transport", name)
+			if owner, ok := owners[g]; ok && !groups[g] {
+				return fmt.Errorf("server %s: group %q is already used as a tool prefix by %s", name, g, owner)
 			}
-		case "docker":
-			if srv.Image == "" {
-				return fmt.Errorf("server %s: image is required for docker transport", name)
+			groups[g] = true
+			owners[g] = g
+			continue
+		}
+		prefix := srv.ToolPrefix
+		if prefix == "" {
+			prefix = name
+		} else if strings.ContainsFunc(prefix, unicode.IsSpace) {
+			return fmt.Errorf("server %s: toolPrefix must not contain whitespace", name)
+		}
+		if owner, ok := owners[prefix]; ok && owner != name {
+			return fmt.Errorf("server %s: tool prefix %q is already used by %s", name, prefix, owner)
+		}
+		owners[prefix] = name
+	}
+	return nil
+}
+
+// validateVirtualServers checks that virtual servers name an enabled server,
+// select at least one tool and don't shadow a real server
+func (c *Config) validateVirtualServers() error {
+	for name, v := range c.VirtualServers {
+		if name == "" || strings.Contains(name, ":") {
+			return fmt.Errorf("virtual server %q: name must be non-empty and not contain ':'", name)
+		}
+		if _, ok := c.MCPServers[name]; ok {
+			return fmt.Errorf("virtual server %s: name is already used by a server", name)
+		}
+		backend, ok := c.MCPServers[v.Server]
+		if !ok || backend.Disabled {
+			return fmt.Errorf("virtual server %s: server %s is not an enabled server", name, v.Server)
+		}
+		if len(v.Tools) == 0 {
+			return fmt.Errorf("virtual server %s: tools is required", name)
+		}
+		for _, pattern := range v.Tools {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("virtual server %s: invalid tool pattern %q: %w", name, pattern, err)
 			}
+		}
+	}
+	return nil
+}
+
+// validateStandby checks that a server's standby is another enabled server
+func (c *Config) validateStandby(name string, srv ServerConfig) error {
+	if srv.Standby == "" {
+		return nil
+	}
+	if srv.Standby == name {
+		return fmt.Errorf("server %s: cannot be its own standby", name)
+	}
+	standby, ok := c.MCPServers[srv.Standby]
+	if !ok || standby.Disabled {
+		return fmt.Errorf("server %s: standby %s is not an enabled server", name, srv.Standby)
+	}
+	return nil
+}
+
+// ActiveServers returns the enabled servers that should run. In strict mode
+// (the default) any invalid server fails the whole config. In lenient mode
+// invalid servers are left out and reported per server instead.
+func (c *Config) ActiveServers() (map[string]ServerConfig, map[string]error, error) {
+	if c.Hub.Validation != "lenient" {
+		if err := c.Validate(); err != nil {
+			return nil, nil, err
+		}
+		return c.GetEnabledServers(), nil, nil
+	}
+
+	if err := c.Hub.validate(); err != nil {
+		return nil, nil, err
+	}
+	if err := c.validateAuth(); err != nil {
+		return nil, nil, err
+	}
+	if err := c.validateVirtualServers(); err != nil {
+		return nil, nil, err
+	}
+	if err := c.validateToolPrefixes(); err != nil {
+		return nil, nil, err
+	}
+
+	valid := make(map[string]ServerConfig)
+	invalid := make(map[string]error)
+	for name, srv := range c.GetEnabledServers() {
+		if err := validateServer(name, srv); err != nil {
+			invalid[name] = err
+			continue
+		}
+		if err := c.validateStandby(name, srv); err != nil {
+			invalid[name] = err
+			continue
+		}
+		valid[name] = srv
+	}
+	return valid, invalid, nil
+}
+
+// validateServer checks a single enabled server configuration
+func validateServer(name string, srv ServerConfig) error {
+	transport := srv.TransportType()
+	switch transport {
+	case "stdio":
+		if srv.Command == "" {
+			return fmt.Errorf("server %s: command is required for stdio transport", name)
+		}
+	case "sse":
+		if srv.URL == "" {
+			return fmt.Errorf("server %s: url is required for sse transport", name)
+		}
+	case "http":
+		if srv.URL == "" {
+			return fmt.Errorf("server %s: url is required for http transport", name)
+		}
+	case "ws":
+		if srv.URL == "" {
+			return fmt.Errorf("server %s: url is required for ws transport", name)
+		}
+		if !strings.HasPrefix(srv.URL, "ws://") && !strings.HasPrefix(srv.URL, "wss://") {
+			return fmt.Errorf("server %s: url of a ws transport must start with ws:// or wss://", name)
+		}
+	case "docker":
+		if srv.Image == "" {
+			return fmt.Errorf("server %s: image is required for docker transport", name)
+		}
+		if !imageRefRe.MatchString(srv.Image) {
+			return fmt.Errorf("server %s: invalid docker image reference %q, expected e.g. registry.example.com/org/name:tag", name, srv.Image)
+		}
+		switch srv.Runtime {
+		case "", "docker", "podman", "nerdctl":
+		default:
+			return fmt.Errorf("server %s: runtime must be docker, podman or nerdctl, got %q", name, srv.Runtime)
+		}
+		if srv.Memory != "" && !memoryRe.MatchString(srv.Memory) {
+			return fmt.Errorf("server %s: invalid memory limit %q, expected e.g. 512m or 2g", name, srv.Memory)
+		}
+		if srv.CPUs < 0 {
+			return fmt.Errorf("server %s: cpus must not be negative", name)
+		}
+		if srv.PidsLimit < 0 {
+			return fmt.Errorf("server %s: pidsLimit must not be negative", name)
+		}
+	default:
+		return fmt.Errorf("server %s: unsupported transport type: %s", name, transport)
+	}
+
+	if len(srv.DynamicHeaders) > 0 && transport != "http" && transport != "sse" && transport != "ws" {
+		return fmt.Errorf("server %s: dynamicHeaders need an http, sse or ws transport", name)
+	}
+	for header, src := range srv.DynamicHeaders {
+		if (src.Env != "") == (len(src.Command) > 0) {
+			return fmt.Errorf("server %s: dynamic header %s needs exactly one of env or command", name, header)
+		}
+		if src.CacheSeconds < 0 {
+			return fmt.Errorf("server %s: cacheSeconds of dynamic header %s must not be negative", name, header)
+		}
+	}
+
+	// The host's PATH, HOME etc. would break the container's own
+	if transport == "docker" && srv.InheritEnv != nil && *srv.InheritEnv {
+		return fmt.Errorf("server %s: inheritEnv is not supported for docker, list the variables to pass in envPassthrough", name)
+	}
+	for _, pattern := range srv.EnvPassthrough {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("server %s: invalid envPassthrough pattern %q: %w", name, pattern, err)
+		}
+		if transport == "docker" && pattern == "*" {
+			return fmt.Errorf("server %s: envPassthrough \"*\" is not supported for docker, list the variables to pass", name)
+		}
+	}
+
+	switch srv.IDFormat {
+	case "", "int", "string":
+	default:
+		return fmt.Errorf("server %s: invalid idFormat: %s (must be int or string)", name, srv.IDFormat)
+	}
+	switch srv.VersionPolicy {
+	case "", "strict", "lenient":
+	default:
+		return fmt.Errorf("server %s: invalid versionPolicy: %s (must be strict or lenient)", name, srv.VersionPolicy)
+	}
+
+	if hook := srv.Init; hook != nil {
+		if (len(hook.Command) > 0) == (hook.Tool != "") {
+			return fmt.Errorf("server %s: init needs exactly one of command or tool", name)
+		}
+		if hook.Timeout < 0 {
+			return fmt.Errorf("server %s: init timeout must not be negative", name)
+		}
+	}
+
+	for tool, tr := range srv.Transforms {
+		if len(tr.Input) == 0 && len(tr.Output) == 0 {
+			return fmt.Errorf("server %s: transform for tool %s needs an input or output command", name, tool)
+		}
+		if tr.Timeout < 0 {
+			return fmt.Errorf("server %s: transform timeout for tool %s must not be negative", name, tool)
+		}
+	}
+
+	switch srv.DuplicateTools {
+	case "", "first", "last", "error":
+	default:
+		return fmt.Errorf("server %s: invalid duplicateTools policy: %s", name, srv.DuplicateTools)
+	}
+	switch srv.MissingInputSchema {
+	case "", "object", "reject", "passthrough":
+	default:
+		return fmt.Errorf("server %s: invalid missingInputSchema policy: %s", name, srv.MissingInputSchema)
+	}
+
+	for _, pattern := range append(slices.Clone(srv.IncludeTools), srv.ExcludeTools...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("server %s: invalid tool pattern %q: %w", name, pattern, err)
+		}
+	}
+	for pattern, ttl := range srv.CacheTTL {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("server %s: invalid cacheTTL pattern %q: %w", name, pattern, err)
+		}
+		if ttl < 0 {
+			return fmt.Errorf("server %s: cacheTTL of %s must not be negative", name, pattern)
+		}
+	}
+
+	for _, root := range srv.Roots {
+		if u, err := url.Parse(root.URI); err != nil || u.Scheme != "file" {
+			return fmt.Errorf("server %s: root %q must be a file:// URI", name, root.URI)
+		}
+	}
+
+	switch srv.OnTimeout {
+	case "", "error", "partial":
+	default:
+		return fmt.Errorf("server %s: invalid onTimeout behavior: %s", name, srv.OnTimeout)
+	}
+
+	for _, trigger := range srv.FailoverOn {
+		switch trigger {
+		case "unavailable", "error", "timeout", "toolError":
 		default:
-			return fmt.Errorf("server %s: unsupported transport type: %s", name, transport)
+			return fmt.Errorf("server %s: invalid failoverOn trigger: %s", name, trigger)
+		}
+	}
+	if srv.FailoverTimeout < 0 {
+		return fmt.Errorf("server %s: failoverTimeout must not be negative", name)
+	}
+
+	switch srv.RateLimitHints {
+	case "", "retry", "throttle", "ignore":
+	default:
+		return fmt.Errorf("server %s: invalid rateLimitHints policy: %s", name, srv.RateLimitHints)
+	}
+
+	if srv.MaxQueue < 0 {
+		return fmt.Errorf("server %s: maxQueue must not be negative", name)
+	}
+	switch srv.ConcurrencyModel {
+	case "", "serial", "parallel":
+	default:
+		return fmt.Errorf("server %s: invalid concurrencyModel: %s (must be serial or parallel)", name, srv.ConcurrencyModel)
+	}
+	if srv.MaxConcurrency < 0 {
+		return fmt.Errorf("server %s: maxConcurrency must not be negative", name)
+	}
+	if srv.MaxConcurrency > 1 && srv.Serial() {
+		return fmt.Errorf("server %s: maxConcurrency needs concurrencyModel parallel", name)
+	}
+
+	if srv.MaxArgumentsSize < 0 {
+		return fmt.Errorf("server %s: maxArgumentsSize must not be negative", name)
+	}
+
+	if srv.PingInterval < 0 {
+		return fmt.Errorf("server %s: pingInterval must not be negative", name)
+	}
+	switch srv.PingMethod {
+	case "", "ping", "tools/list":
+	default:
+		return fmt.Errorf("server %s: invalid pingMethod: %s (must be ping or tools/list)", name, srv.PingMethod)
+	}
+
+	if srv.GiveUpAfter < 0 {
+		return fmt.Errorf("server %s: giveUpAfter must not be negative", name)
+	}
+	if srv.Group != "" {
+		if strings.Contains(srv.Group, ":") || strings.ContainsFunc(srv.Group, unicode.IsSpace) {
+			return fmt.Errorf("server %s: group must not contain ':' or whitespace", name)
+		}
+		if srv.ToolPrefix != "" {
+			return fmt.Errorf("server %s: toolPrefix can't be used with group, tools are listed under the group's name", name)
+		}
+	}
+	if srv.Weight < 0 {
+		return fmt.Errorf("server %s: weight must not be negative", name)
+	}
+	if srv.BreakerThreshold < 0 || srv.BreakerWindow < 0 || srv.BreakerCooldown < 0 {
+		return fmt.Errorf("server %s: breakerThreshold, breakerWindow and breakerCooldown must not be negative", name)
+	}
+	if len(srv.InitializeExtra) > 0 {
+		var extra map[string]any
+		if err := json.Unmarshal(srv.InitializeExtra, &extra); err != nil || extra == nil {
+			return fmt.Errorf("server %s: initializeExtra must be a JSON object", name)
+		}
+	}
+
+	switch srv.RestartPolicy {
+	case "", "always", "never":
+	default:
+		return fmt.Errorf("server %s: restartPolicy must be always or never", name)
+	}
+	if srv.RestartBaseDelay < 0 || srv.RestartMaxDelay < 0 || srv.MaxRestarts < 0 {
+		return fmt.Errorf("server %s: restartBaseDelay, restartMaxDelay and maxRestarts must not be negative", name)
+	}
+	if srv.RestartBaseDelay > 0 && srv.RestartMaxDelay > 0 && srv.RestartBaseDelay > srv.RestartMaxDelay {
+		return fmt.Errorf("server %s: restartBaseDelay must not exceed restartMaxDelay", name)
+	}
+	if srv.StartRetries < 0 || srv.StartRetryDelay < 0 {
+		return fmt.Errorf("server %s: startRetries and startRetryDelay must not be negative", name)
+	}
+
+	if err := validateLabels(srv.Labels); err != nil {
+		return fmt.Errorf("server %s: %w", name, err)
+	}
+	return nil
+}
+
+// validate checks the hub-level settings
+func (h *HubConfig) validate() error {
+	switch h.UnknownTool {
+	case "", "error", "suggest":
+	case "fallback":
+		if !strings.Contains(h.FallbackTool, ":") {
+			return fmt.Errorf("hub: fallbackTool must be a namespaced <plugin>:<tool> name")
+		}
+	default:
+		return fmt.Errorf("hub: invalid unknownTool policy: %s", h.UnknownTool)
+	}
+
+	switch h.BareToolNames {
+	case "", "single", "search", "strict":
+	default:
+		return fmt.Errorf("hub: invalid bareToolNames mode: %s", h.BareToolNames)
+	}
+
+	if strings.ContainsFunc(h.ToolSeparator, unicode.IsSpace) {
+		return fmt.Errorf("hub: toolSeparator must not contain whitespace")
+	}
+
+	switch h.Validation {
+	case "", "strict", "lenient":
+	default:
+		return fmt.Errorf("hub: invalid validation mode: %s", h.Validation)
+	}
+
+	if h.MaxTools < 0 {
+		return fmt.Errorf("hub: maxTools must not be negative")
+	}
+	switch h.ToolSelection {
+	case "", "first", "priority", "usage":
+	default:
+		return fmt.Errorf("hub: invalid toolSelection: %s", h.ToolSelection)
+	}
+	switch h.ToolOrder {
+	case "", "name", "listed", "priority":
+	default:
+		return fmt.Errorf("hub: invalid toolOrder: %s", h.ToolOrder)
+	}
+
+	if h.SessionMaxLifetime < 0 || h.SessionIdleTimeout < 0 || h.MaxSessions < 0 {
+		return fmt.Errorf("hub: session limits must not be negative")
+	}
+
+	switch h.Readiness {
+	case "", "any", "all":
+	default:
+		return fmt.Errorf("hub: invalid readiness: %s", h.Readiness)
+	}
+
+	if h.MaxServers < 0 {
+		return fmt.Errorf("hub: maxServers must not be negative")
+	}
+
+	if h.ShutdownDrain < 0 {
+		return fmt.Errorf("hub: shutdownDrain must not be negative")
+	}
+
+	for _, key := range h.Redact {
+		if strings.TrimSpace(key) == "" {
+			return fmt.Errorf("hub: redact keys must not be empty")
+		}
+	}
+
+	if r := h.RateLimit; r != nil {
+		if err := r.validate("hub: rateLimit"); err != nil {
+			return err
+		}
+		for pattern, l := range r.Tools {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("hub: rateLimit: invalid tool pattern %q: %w", pattern, err)
+			}
+			if err := l.validate("hub: rateLimit: tool " + pattern); err != nil {
+				return err
+			}
+		}
+	}
+
+	if h.WatchPollInterval < 0 {
+		return fmt.Errorf("hub: watchPollInterval must not be negative")
+	}
+
+	switch h.WatchMode {
+	case "", "fsnotify", "poll":
+	default:
+		return fmt.Errorf("hub: invalid watchMode: %s", h.WatchMode)
+	}
+
+	switch h.ClientDisconnect {
+	case "", "cancel", "continue":
+	default:
+		return fmt.Errorf("hub: invalid clientDisconnect: %s", h.ClientDisconnect)
+	}
+
+	switch h.Capabilities {
+	case "", "static", "backends":
+	default:
+		return fmt.Errorf("hub: invalid capabilities mode: %s", h.Capabilities)
+	}
+
+	switch h.ReloadMode {
+	case "", "server", "graceful":
+	default:
+		return fmt.Errorf("hub: invalid reloadMode: %s", h.ReloadMode)
+	}
+
+	if h.DriftCheckInterval < 0 {
+		return fmt.Errorf("hub: driftCheckInterval must not be negative")
+	}
+
+	if h.ReconcileInterval < 0 {
+		return fmt.Errorf("hub: reconcileInterval must not be negative")
+	}
+
+	if h.MaxArgumentsSize < 0 {
+		return fmt.Errorf("hub: maxArgumentsSize must not be negative")
+	}
+
+	if h.ResultCacheSize < 0 {
+		return fmt.Errorf("hub: resultCacheSize must not be negative")
+	}
+
+	if t := h.Telemetry; t != nil {
+		u, err := url.Parse(t.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("hub: telemetry endpoint must be an http(s) URL: %q", t.Endpoint)
+		}
+		if t.MetricInterval < 0 {
+			return fmt.Errorf("hub: telemetry metricInterval must not be negative")
+		}
+	}
+
+	tokens := make(map[string]string, len(h.Clients))
+	for name, client := range h.Clients {
+		if client.Token == "" {
+			return fmt.Errorf("hub: client %s: token is required", name)
+		}
+		if other, ok := tokens[client.Token]; ok {
+			return fmt.Errorf("hub: clients %s and %s share a token", other, name)
+		}
+		tokens[client.Token] = name
+		for _, pattern := range client.Allow {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("hub: client %s: invalid allow pattern %q", name, pattern)
+			}
 		}
 	}
+
+	switch h.ToolUpdates {
+	case "", "update", "ignore":
+	default:
+		return fmt.Errorf("hub: invalid toolUpdates policy: %s", h.ToolUpdates)
+	}
 	return nil
 }
 
-// GetEnabledServers returns a list of enabled server configurations
+// validateLabels checks custom metric labels for valid, non-reserved names
+func validateLabels(labels map[string]string) error {
+	if len(labels) > maxLabels {
+		return fmt.Errorf("too many labels: %d (max %d)", len(labels), maxLabels)
+	}
+	for k := range labels {
+		if !labelNameRe.MatchString(k) || strings.HasPrefix(k, "__") {
+			return fmt.Errorf("invalid label name: %q", k)
+		}
+		if reservedLabels[k] {
+			return fmt.Errorf("label name %q is reserved", k)
+		}
+	}
+	return nil
+}
+
+// GetEnabledServers returns a list of enabled server configurations, with
+// hub-wide defaults applied
 func (c *Config) GetEnabledServers() map[string]ServerConfig {
 	enabled := make(map[string]ServerConfig)
 	for name, srv := range c.MCPServers {
 		if !srv.Disabled {
+			srv = srv.Clone()
+			if srv.MaxArgumentsSize == 0 {
+				srv.MaxArgumentsSize = c.Hub.MaxArgumentsSize
+			}
 			enabled[name] = srv
 		}
 	}
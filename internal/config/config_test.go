@@ -0,0 +1,104 @@
+package config
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestActiveServersLenient(t *testing.T) {
+	cfg := &Config{MCPServers: map[string]ServerConfig{
+		"github":  {Type: "http", URL: "http://localhost:3000/mcp"},
+		"files":   {Command: "mcp-files"},
+		"weather": {Type: "http"},
+	}}
+
+	// Strict validation fails the whole config
+	if _, _, err := cfg.ActiveServers(); err == nil {
+		t.Fatal("strict validation accepted an invalid server")
+	}
+
+	cfg.Hub.Validation = "lenient"
+	valid, invalid, err := cfg.ActiveServers()
+	if err != nil {
+		t.Fatalf("lenient validation: %v", err)
+	}
+	var names []string
+	for name := range valid {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	if !slices.Equal(names, []string{"files", "github"}) {
+		t.Errorf("valid servers = %v, want [files github]", names)
+	}
+	if len(invalid) != 1 || invalid["weather"] == nil {
+		t.Errorf("invalid servers = %v, want weather", invalid)
+	}
+}
+
+func TestMaxArgumentsSizeDefault(t *testing.T) {
+	cfg := &Config{
+		Hub: HubConfig{MaxArgumentsSize: 1024},
+		MCPServers: map[string]ServerConfig{
+			"github": {Type: "http", URL: "http://localhost:3000/mcp"},
+			"files":  {Command: "mcp-files", MaxArgumentsSize: 64},
+		},
+	}
+	servers := cfg.GetEnabledServers()
+	if got := servers["github"].MaxArgumentsSize; got != 1024 {
+		t.Errorf("github limit = %d, want the hub default 1024", got)
+	}
+	if got := servers["files"].MaxArgumentsSize; got != 64 {
+		t.Errorf("files limit = %d, want its own 64", got)
+	}
+}
+
+func TestInitializeExtraMustBeObject(t *testing.T) {
+	for extra, valid := range map[string]bool{
+		`{"clientInfo":{"vendor":"acme"}}`: true,
+		`["acme"]`:                         false,
+		`"acme"`:                           false,
+	} {
+		cfg := &Config{MCPServers: map[string]ServerConfig{
+			"github": {Type: "http", URL: "http://localhost:3000/mcp", InitializeExtra: json.RawMessage(extra)},
+		}}
+		err := cfg.Validate()
+		if valid && err != nil {
+			t.Errorf("initializeExtra %s: %v", extra, err)
+		}
+		if !valid && (err == nil || !strings.Contains(err.Error(), "initializeExtra must be a JSON object")) {
+			t.Errorf("initializeExtra %s: err = %v", extra, err)
+		}
+	}
+}
+
+func TestValidateLabels(t *testing.T) {
+	tooMany := make(map[string]string)
+	for _, k := range strings.Split("a b c d e f g h i j k", " ") {
+		tooMany[k] = "x"
+	}
+	tests := []struct {
+		name   string
+		labels map[string]string
+		valid  bool
+	}{
+		{"none", nil, true},
+		{"business dimensions", map[string]string{"team": "search", "env": "prod", "cost_center": "42"}, true},
+		{"invalid name", map[string]string{"cost-center": "42"}, false},
+		{"leading digit", map[string]string{"1team": "search"}, false},
+		{"prometheus internal", map[string]string{"__name__": "x"}, false},
+		{"reserved", map[string]string{"plugin": "github"}, false},
+		{"too many", tooMany, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{MCPServers: map[string]ServerConfig{
+				"github": {Type: "http", URL: "http://localhost:3000/mcp", Labels: tt.labels},
+			}}
+			if err := cfg.Validate(); (err == nil) != tt.valid {
+				t.Errorf("Validate() = %v, want valid %v", err, tt.valid)
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IsFragment reports whether a file in a config directory is read as part of
+// the config. Hidden files, such as editor swap files, are skipped.
+func IsFragment(name string) bool {
+	base := filepath.Base(name)
+	if strings.HasPrefix(base, ".") {
+		return false
+	}
+	switch strings.ToLower(filepath.Ext(base)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// Fragments returns the config fragments in dir, in name order
+func Fragments(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config directory: %w", err)
+	}
+	var files []string
+	for _, e := range entries {
+		if !e.IsDir() && IsFragment(e.Name()) {
+			files = append(files, filepath.Join(dir, e.Name()))
+		}
+	}
+	slices.Sort(files)
+	return files, nil
+}
+
+// loadDir merges the fragments of a config directory, e.g. one file per
+// server. A server or virtual server may only be defined in one fragment, as
+// may the hub and auth settings.
+func loadDir(dir string) (*Config, error) {
+	files, err := Fragments(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := &Config{MCPServers: make(map[string]ServerConfig)}
+	defined := make(map[string]string)
+	var hubFrom, authFrom string
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		frag, err := decode(file, data)
+		if err != nil {
+			return nil, err
+		}
+		name := filepath.Base(file)
+
+		for srv, cfg := range frag.MCPServers {
+			if prev, ok := defined[srv]; ok {
+				return nil, fmt.Errorf("server %s is defined in both %s and %s", srv, prev, name)
+			}
+			defined[srv] = name
+			merged.MCPServers[srv] = cfg
+		}
+		for v, cfg := range frag.VirtualServers {
+			if prev, ok := defined[v]; ok {
+				return nil, fmt.Errorf("server %s is defined in both %s and %s", v, prev, name)
+			}
+			defined[v] = name
+			if merged.VirtualServers == nil {
+				merged.VirtualServers = make(map[string]VirtualServer)
+			}
+			merged.VirtualServers[v] = cfg
+		}
+		if !reflect.ValueOf(frag.Hub).IsZero() {
+			if hubFrom != "" {
+				return nil, fmt.Errorf("hub settings are set in both %s and %s", hubFrom, name)
+			}
+			hubFrom = name
+			merged.Hub = frag.Hub
+		}
+		if !reflect.ValueOf(frag.Auth).IsZero() {
+			if authFrom != "" {
+				return nil, fmt.Errorf("auth settings are set in both %s and %s", authFrom, name)
+			}
+			authFrom = name
+			merged.Auth = frag.Auth
+		}
+	}
+
+	if err := merged.processEnvVars(); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// decode parses one config document, YAML if the file name says so and JSON
+// otherwise, without expanding environment variables
+func decode(file string, data []byte) (*Config, error) {
+	switch strings.ToLower(filepath.Ext(file)) {
+	case ".yaml", ".yml":
+		// Go through JSON so both formats share the json field names
+		var doc any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(file), err)
+		}
+		converted, err := json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(file), err)
+		}
+		data = converted
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(file), err)
+	}
+	return &cfg, nil
+}
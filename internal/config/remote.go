@@ -0,0 +1,64 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxRemoteSize bounds the size of a remotely fetched configuration
+const maxRemoteSize = 10 << 20
+
+// Remote describes a configuration served over HTTP
+type Remote struct {
+	URL string
+	// Header sent with every fetch, e.g. Authorization, empty for none
+	HeaderName  string
+	HeaderValue string
+	Client      *http.Client
+}
+
+// Fetch downloads the configuration document without parsing it
+func (r *Remote) Fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create config request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if r.HeaderName != "" {
+		req.Header.Set(r.HeaderName, r.HeaderValue)
+	}
+
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch config: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch config: unexpected status %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config response: %w", err)
+	}
+	if len(data) > maxRemoteSize {
+		return nil, fmt.Errorf("config response exceeds %d bytes", maxRemoteSize)
+	}
+	return data, nil
+}
+
+// Load fetches and parses the configuration
+func (r *Remote) Load(ctx context.Context) (*Config, error) {
+	data, err := r.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
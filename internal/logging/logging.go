@@ -0,0 +1,45 @@
+// Package logging builds the structured loggers used across the hub.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+var defaultLogger = slog.New(slog.NewTextHandler(os.Stderr, nil))
+
+// Default returns the logger components use until one is injected: text on
+// stderr at info level
+func Default() *slog.Logger {
+	return defaultLogger
+}
+
+// New builds a logger writing to w in format "text" (default) or "json" at
+// level "debug", "info" (default), "warn" or "error"
+func New(w io.Writer, format, level string) (*slog.Logger, error) {
+	var lvl slog.Level
+	switch strings.ToLower(level) {
+	case "", "info":
+		lvl = slog.LevelInfo
+	case "debug":
+		lvl = slog.LevelDebug
+	case "warn", "warning":
+		lvl = slog.LevelWarn
+	case "error":
+		lvl = slog.LevelError
+	default:
+		return nil, fmt.Errorf("invalid log level: %s", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	switch strings.ToLower(format) {
+	case "", "text":
+		return slog.New(slog.NewTextHandler(w, opts)), nil
+	case "json":
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	return nil, fmt.Errorf("invalid log format: %s", format)
+}
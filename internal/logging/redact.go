@@ -0,0 +1,151 @@
+package logging
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces the value of a sensitive key in logged payloads
+const Redacted = "***"
+
+// defaultRedactKeys are always masked, matched case-insensitively
+var defaultRedactKeys = []string{
+	"token", "access_token", "refresh_token", "password", "secret",
+	"client_secret", "authorization", "api_key", "apikey", "x-api-key", "cookie",
+}
+
+var (
+	redactMu   sync.RWMutex
+	redactKeys = keySet(nil)
+)
+
+// SetRedactKeys sets the keys masked in logged payloads besides the defaults,
+// e.g. names of tool arguments or headers carrying credentials
+func SetRedactKeys(keys []string) {
+	set := keySet(keys)
+	redactMu.Lock()
+	redactKeys = set
+	redactMu.Unlock()
+}
+
+func keySet(extra []string) map[string]bool {
+	set := make(map[string]bool, len(defaultRedactKeys)+len(extra))
+	for _, k := range defaultRedactKeys {
+		set[k] = true
+	}
+	for _, k := range extra {
+		set[strings.ToLower(k)] = true
+	}
+	return set
+}
+
+// sensitive reports whether the value of key must be masked
+func sensitive(key string) bool {
+	redactMu.RLock()
+	defer redactMu.RUnlock()
+	return redactKeys[strings.ToLower(key)]
+}
+
+// Redact returns a JSON payload with the values of sensitive keys, at any
+// depth, replaced by "***". Anything that isn't JSON is returned unchanged,
+// so it must be redacted before it is shortened for logging.
+func Redact(data []byte) []byte {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return data
+	}
+	if !redactValue(v) {
+		return data
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		return data
+	}
+	return out
+}
+
+// redactValue masks sensitive keys in place and reports whether it did
+func redactValue(v any) bool {
+	changed := false
+	switch v := v.(type) {
+	case map[string]any:
+		for k, sub := range v {
+			if sensitive(k) {
+				v[k] = Redacted
+				changed = true
+				continue
+			}
+			changed = redactValue(sub) || changed
+		}
+	case []any:
+		for _, sub := range v {
+			changed = redactValue(sub) || changed
+		}
+	}
+	return changed
+}
+
+// keyValuePattern finds "key: value", "key=value" and "key":"value" pairs in
+// free text, allowing an auth scheme before the value
+var keyValuePattern = regexp.MustCompile(`([A-Za-z_][A-Za-z0-9_.-]*)("?\s*[:=]\s*"?)((?:Bearer|Basic|Token)\s+)?([^\s"',;&]+)`)
+
+// RedactText masks the values of sensitive keys in text that isn't JSON,
+// e.g. an error message or a command's stderr, and every occurrence of the
+// values of sensitive headers among headers, which may be nil
+func RedactText(s string, headers http.Header) string {
+	for name, values := range headers {
+		if !sensitive(name) {
+			continue
+		}
+		for _, v := range values {
+			if v != "" {
+				s = strings.ReplaceAll(s, v, Redacted)
+			}
+		}
+	}
+	var out strings.Builder
+	for {
+		m := keyValuePattern.FindStringSubmatchIndex(s)
+		if m == nil {
+			break
+		}
+		if !sensitive(s[m[2]:m[3]]) {
+			// The value may itself start the next pair, e.g. "failed: token=x"
+			out.WriteString(s[:m[5]])
+			s = s[m[5]:]
+			continue
+		}
+		out.WriteString(s[:m[8]])
+		out.WriteString(Redacted)
+		s = s[m[1]:]
+	}
+	out.WriteString(s)
+	return out.String()
+}
+
+// RedactError returns err with its message passed through RedactText, for
+// logging and returning errors that may carry credentials. It still unwraps
+// to err.
+func RedactError(err error, headers http.Header) error {
+	if err == nil {
+		return nil
+	}
+	msg := err.Error()
+	redacted := RedactText(msg, headers)
+	if redacted == msg {
+		return err
+	}
+	return &redactedError{err: err, msg: redacted}
+}
+
+type redactedError struct {
+	err error
+	msg string
+}
+
+func (e *redactedError) Error() string { return e.msg }
+
+func (e *redactedError) Unwrap() error { return e.err }
@@ -0,0 +1,68 @@
+package logging
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	SetRedactKeys([]string{"X-Team-Key"})
+	defer SetRedactKeys(nil)
+
+	tests := []struct {
+		in, want string
+	}{
+		{`{"query":"go","token":"abc"}`, `{"query":"go","token":"***"}`},
+		{`{"auth":{"Password":"hunter2"},"items":[{"x-team-key":"k"}]}`, `{"auth":{"Password":"***"},"items":[{"x-team-key":"***"}]}`},
+		{`{"query":"go"}`, `{"query":"go"}`},
+		{`not json token=abc`, `not json token=abc`},
+	}
+	for _, tt := range tests {
+		if got := string(Redact([]byte(tt.in))); got != tt.want {
+			t.Errorf("Redact(%s) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRedactText(t *testing.T) {
+	SetRedactKeys([]string{"X-Team-Key"})
+	defer SetRedactKeys(nil)
+
+	tests := []struct {
+		in, want string
+	}{
+		{"Authorization: Bearer abc123", "Authorization: Bearer ***"},
+		{"refused token=abc123&user=bob", "refused token=***&user=bob"},
+		{`backend said {"api_key": "abc123"}`, `backend said {"api_key": "***"}`},
+		{"X-Team-Key: k1 rejected", "X-Team-Key: *** rejected"},
+		{"dial tcp https://example.com:443: refused", "dial tcp https://example.com:443: refused"},
+	}
+	for _, tt := range tests {
+		if got := RedactText(tt.in, nil); got != tt.want {
+			t.Errorf("RedactText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	headers := http.Header{"Authorization": {"Bearer s3cret"}, "Accept": {"text/plain"}}
+	if got := RedactText("sent Bearer s3cret as text/plain", headers); got != "sent *** as text/plain" {
+		t.Errorf("header values: %q", got)
+	}
+}
+
+func TestRedactError(t *testing.T) {
+	if RedactError(nil, nil) != nil {
+		t.Error("nil error redacted to non-nil")
+	}
+	plain := errors.New("connection refused")
+	if RedactError(plain, nil) != plain {
+		t.Error("error without credentials wrapped")
+	}
+	err := RedactError(errors.New("login failed: password=hunter2"), nil)
+	if err.Error() != "login failed: password=***" {
+		t.Errorf("Error() = %q", err)
+	}
+	if errors.Unwrap(err) == nil {
+		t.Error("redacted error doesn't unwrap")
+	}
+}
@@ -0,0 +1,51 @@
+package plugin
+
+import (
+	"context"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// abortTransport keeps the connection it opened so a session can be torn
+// down without its cooperation. Closing a session waits for every request
+// still outstanding, which never returns for a backend that stopped
+// answering.
+type abortTransport struct {
+	mcp.Transport
+
+	mu   sync.Mutex
+	conn mcp.Connection
+}
+
+func (t *abortTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	conn, err := t.Transport.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	t.conn = conn
+	t.mu.Unlock()
+	return conn, nil
+}
+
+// abort closes the connection under the session, which ends the session
+// and fails its outstanding requests
+func (t *abortTransport) abort() error {
+	t.mu.Lock()
+	conn := t.conn
+	t.mu.Unlock()
+	if conn == nil {
+		return nil
+	}
+	return conn.Close()
+}
+
+// abort ends the server's session without waiting for the backend to
+// answer, canceling the connect context the SDK ties HTTP connections to
+func (s *MCPServer) abort() error {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	return s.transport.abort()
+}
@@ -0,0 +1,35 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMaxArgumentsSize(t *testing.T) {
+	m := newTestManager()
+	b := newTestBackend(t, nil)
+	cfg := b.config()
+	cfg.MaxArgumentsSize = 32
+	startServer(t, m, "echo", cfg)
+	t.Cleanup(func() { m.StopServer("echo") })
+
+	ctx := context.Background()
+	resp, err := m.Execute(ctx, "echo", "echo", json.RawMessage(`{"text":"short"}`))
+	if err != nil {
+		t.Fatalf("within the limit: %v", err)
+	}
+	if got := resultText(t, resp); got != "short" {
+		t.Errorf("echo returned %q", got)
+	}
+
+	large, _ := json.Marshal(map[string]string{"text": strings.Repeat("x", 64)})
+	_, err = m.Execute(ctx, "echo", "echo", large)
+	if err == nil || !strings.Contains(err.Error(), "arguments too large") {
+		t.Fatalf("oversized arguments: err = %v", err)
+	}
+	if n := b.count("tools/call"); n != 1 {
+		t.Errorf("backend got %d calls, want only the one within the limit", n)
+	}
+}
@@ -0,0 +1,211 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+)
+
+// ErrCircuitOpen is returned for calls to a server whose circuit breaker is
+// open after repeated failures
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// Events emitted when a server's circuit breaker opens and closes again
+const (
+	EventBreakerOpen   EventType = "breaker_open"
+	EventBreakerClosed EventType = "breaker_closed"
+)
+
+// Breaker states as reported in the server status
+const (
+	breakerClosed   = "closed"
+	breakerOpen     = "open"
+	breakerHalfOpen = "half-open"
+)
+
+const (
+	defaultBreakerWindow   = 60 * time.Second
+	defaultBreakerCooldown = 30 * time.Second
+)
+
+// breaker counts consecutive failed calls to one server. Once the threshold
+// is reached within the window it rejects calls for the cooldown, then lets
+// a single probe through which closes it again or reopens it.
+type breaker struct {
+	mu       sync.Mutex
+	failures int
+	first    time.Time // first failure of the current run
+	openedAt time.Time // zero while closed
+	probing  bool
+}
+
+// breakerSettings are the thresholds of a server, read per call so they
+// can be changed by a reload
+type breakerSettings struct {
+	threshold int
+	window    time.Duration
+	cooldown  time.Duration
+}
+
+func breakerSettingsFor(cfg config.ServerConfig) breakerSettings {
+	s := breakerSettings{
+		threshold: cfg.BreakerThreshold,
+		window:    time.Duration(cfg.BreakerWindow) * time.Second,
+		cooldown:  time.Duration(cfg.BreakerCooldown) * time.Second,
+	}
+	if s.window == 0 {
+		s.window = defaultBreakerWindow
+	}
+	if s.cooldown == 0 {
+		s.cooldown = defaultBreakerCooldown
+	}
+	return s
+}
+
+// stateLocked returns the breaker's state at now
+func (b *breaker) stateLocked(s breakerSettings, now time.Time) string {
+	switch {
+	case b.openedAt.IsZero():
+		return breakerClosed
+	case now.Sub(b.openedAt) < s.cooldown:
+		return breakerOpen
+	default:
+		return breakerHalfOpen
+	}
+}
+
+// breakerCall is a call admitted by a breaker. The call's outcome is
+// recorded with succeeded or failed, end releases a probe that never got
+// to the backend. A nil breakerCall ignores all of them.
+type breakerCall struct {
+	m        *Manager
+	name     string
+	b        *breaker
+	s        breakerSettings
+	probe    bool
+	recorded bool
+}
+
+// enterBreaker admits a call to a server, or fails fast while its breaker is
+// open. Servers without breakerThreshold get a nil breakerCall.
+func (m *Manager) enterBreaker(name string, cfg config.ServerConfig) (*breakerCall, error) {
+	s := breakerSettingsFor(cfg)
+	if s.threshold <= 0 {
+		return nil, nil
+	}
+	m.mu.Lock()
+	b, ok := m.breakers[name]
+	if !ok {
+		b = &breaker{}
+		m.breakers[name] = b
+	}
+	m.mu.Unlock()
+
+	now := time.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	probe := false
+	switch b.stateLocked(s, now) {
+	case breakerOpen:
+		retry := s.cooldown - now.Sub(b.openedAt)
+		return nil, fmt.Errorf("%w: server %s failed %d calls in a row, retrying in %s", ErrCircuitOpen, name, b.failures, retry.Round(time.Second))
+	case breakerHalfOpen:
+		if b.probing {
+			return nil, fmt.Errorf("%w: server %s is being probed", ErrCircuitOpen, name)
+		}
+		b.probing, probe = true, true
+		m.logger.Info("breaker:probe", "plugin", name)
+	}
+	return &breakerCall{m: m, name: name, b: b, s: s, probe: probe}, nil
+}
+
+// succeeded records a call the server answered, closing the breaker
+func (c *breakerCall) succeeded() {
+	if c == nil {
+		return
+	}
+	c.recorded = true
+	b := c.b
+	b.mu.Lock()
+	wasOpen := !b.openedAt.IsZero()
+	b.failures, b.first, b.openedAt = 0, time.Time{}, time.Time{}
+	if c.probe {
+		b.probing = false
+	}
+	b.mu.Unlock()
+	if wasOpen {
+		c.m.logger.Info("breaker:closed", "plugin", c.name)
+		c.m.emit(EventBreakerClosed, c.name, nil)
+	}
+}
+
+// failed records a call the server didn't answer. A failed probe reopens
+// the breaker, reaching the threshold within the window opens it.
+func (c *breakerCall) failed() {
+	if c == nil {
+		return
+	}
+	c.recorded = true
+	b := c.b
+	now := time.Now()
+	b.mu.Lock()
+	if b.failures == 0 || now.Sub(b.first) > c.s.window {
+		b.failures, b.first = 0, now
+	}
+	b.failures++
+	failures := b.failures
+	opened := false
+	switch {
+	case c.probe:
+		b.probing = false
+		b.openedAt = now
+	case b.openedAt.IsZero() && b.failures >= c.s.threshold:
+		b.openedAt = now
+		opened = true
+	}
+	b.mu.Unlock()
+	if opened {
+		c.m.logger.Warn("breaker:open", "plugin", c.name, "failures", failures, "cooldown", c.s.cooldown)
+		c.m.emit(EventBreakerOpen, c.name, map[string]string{"failures": strconv.Itoa(failures)})
+	} else if c.probe {
+		c.m.logger.Warn("breaker:reopen", "plugin", c.name, "cooldown", c.s.cooldown)
+	}
+}
+
+// end releases a probe whose call ended before reaching the server, e.g.
+// rejected by a full queue, so the next call probes instead
+func (c *breakerCall) end() {
+	if c == nil || c.recorded || !c.probe {
+		return
+	}
+	c.b.mu.Lock()
+	c.b.probing = false
+	c.b.mu.Unlock()
+}
+
+// countsAsFailure reports whether a failed call says something about the
+// server. Calls the client cancelled don't, timeouts do.
+func countsAsFailure(ctx context.Context) bool {
+	return !errors.Is(ctx.Err(), context.Canceled)
+}
+
+// breakerStatusLocked returns the state and consecutive failures of a
+// server's breaker, "" if it has none. m.mu must be held.
+func (m *Manager) breakerStatusLocked(name string, cfg config.ServerConfig) (string, int) {
+	s := breakerSettingsFor(cfg)
+	if s.threshold <= 0 {
+		return "", 0
+	}
+	b, ok := m.breakers[name]
+	if !ok {
+		return breakerClosed, 0
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.stateLocked(s, time.Now()), b.failures
+}
@@ -0,0 +1,115 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// flakyServer has a tool "fetch" counting its calls, which fails with a
+// protocol error while down is set
+func flakyServer(down *atomic.Bool, calls *atomic.Int32) *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "flaky"}, nil)
+	server.AddTool(&mcp.Tool{Name: "fetch", InputSchema: map[string]any{"type": "object"}}, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		calls.Add(1)
+		if down.Load() {
+			return nil, errors.New("upstream unavailable")
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil
+	})
+	return server
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	var down atomic.Bool
+	var calls atomic.Int32
+	m := newTestManager()
+	events := m.SubscribeEvents()
+	defer m.UnsubscribeEvents(events)
+	cfg := newTestBackend(t, flakyServer(&down, &calls)).config()
+	cfg.BreakerThreshold = 2
+	cfg.BreakerCooldown = 1
+	startServer(t, m, "flaky", cfg)
+	t.Cleanup(func() { m.StopServer("flaky") })
+
+	ctx := context.Background()
+	fetch := func() error {
+		_, err := m.Execute(ctx, "flaky", "fetch", json.RawMessage(`{}`))
+		return err
+	}
+	breakerState := func() (string, int) {
+		st := m.ServerStatuses()[0]
+		return st.Breaker, st.BreakerFailures
+	}
+	waitForBreaker := func(want string) {
+		t.Helper()
+		for deadline := time.Now().Add(3 * time.Second); ; time.Sleep(20 * time.Millisecond) {
+			if state, _ := breakerState(); state == want {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("breaker never %s", want)
+			}
+		}
+	}
+	if state, _ := breakerState(); state != breakerClosed {
+		t.Fatalf("breaker %s before any call", state)
+	}
+
+	// Two failures in a row open it
+	down.Store(true)
+	for range 2 {
+		if err := fetch(); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("call to the failing server: %v", err)
+		}
+	}
+	if ev := nextEvent(t, events, EventBreakerOpen); ev.Server != "flaky" || ev.Details["failures"] != "2" {
+		t.Errorf("breaker_open event = %+v", ev)
+	}
+	if state, failures := breakerState(); state != breakerOpen || failures != 2 {
+		t.Errorf("breaker %s after %d failures, want open after 2", state, failures)
+	}
+
+	// While open, calls fail fast without reaching the backend
+	before := calls.Load()
+	start := time.Now()
+	if err := fetch(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("call while open: %v, want ErrCircuitOpen", err)
+	}
+	if d := time.Since(start); d > 100*time.Millisecond {
+		t.Errorf("rejected call took %v", d)
+	}
+	if calls.Load() != before {
+		t.Error("call reached the backend while the breaker was open")
+	}
+
+	// After the cooldown a failed probe opens it again
+	waitForBreaker(breakerHalfOpen)
+	if err := fetch(); err == nil || errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("probe: %v, want the backend's error", err)
+	}
+	if state, _ := breakerState(); state != breakerOpen {
+		t.Errorf("breaker %s after a failed probe, want open", state)
+	}
+
+	// A successful probe closes it
+	down.Store(false)
+	waitForBreaker(breakerHalfOpen)
+	if err := fetch(); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if ev := nextEvent(t, events, EventBreakerClosed); ev.Server != "flaky" {
+		t.Errorf("breaker_closed event = %+v", ev)
+	}
+	if state, failures := breakerState(); state != breakerClosed || failures != 0 {
+		t.Errorf("breaker %s with %d failures after recovering", state, failures)
+	}
+	if err := fetch(); err != nil {
+		t.Errorf("call after recovering: %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package plugin
+
+import (
+	"bytes"
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// defaultResultCacheSize bounds the memory of cached tool results unless
+// hub.resultCacheSize says otherwise
+const defaultResultCacheSize = 64 << 20
+
+// resultCache keeps successful tool results for calls with the same
+// arguments, dropping the least recently used once over its size
+type resultCache struct {
+	mu      sync.Mutex
+	maxSize int
+	size    int
+	entries map[string]*list.Element
+	lru     *list.List // most recently used first
+}
+
+type cacheEntry struct {
+	key     string
+	result  json.RawMessage
+	expires time.Time
+}
+
+func newResultCache(maxSize int) *resultCache {
+	return &resultCache{maxSize: maxSize, entries: make(map[string]*list.Element), lru: list.New()}
+}
+
+func (e *cacheEntry) size() int { return len(e.key) + len(e.result) }
+
+// get returns the cached result of a call, unless it expired
+func (c *resultCache) get(key string, now time.Time) (json.RawMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	e := el.Value.(*cacheEntry)
+	if !now.Before(e.expires) {
+		c.removeLocked(el)
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	return e.result, true
+}
+
+// put caches a result until expires. Results larger than the whole cache
+// aren't kept.
+func (c *resultCache) put(key string, result json.RawMessage, expires time.Time) {
+	e := &cacheEntry{key: key, result: result, expires: expires}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.removeLocked(el)
+	}
+	if e.size() > c.maxSize {
+		return
+	}
+	c.entries[key] = c.lru.PushFront(e)
+	c.size += e.size()
+	c.evictLocked()
+}
+
+// setMaxSize changes the cache's size, evicting results beyond it
+func (c *resultCache) setMaxSize(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxSize = n
+	c.evictLocked()
+}
+
+func (c *resultCache) evictLocked() {
+	for c.size > c.maxSize {
+		c.removeLocked(c.lru.Back())
+	}
+}
+
+func (c *resultCache) removeLocked(el *list.Element) {
+	e := c.lru.Remove(el).(*cacheEntry)
+	delete(c.entries, e.key)
+	c.size -= e.size()
+}
+
+// cacheKey identifies a call by server, tool and a hash of its arguments.
+// The arguments are hashed in canonical form, so key order and whitespace
+// don't matter.
+func cacheKey(pluginID, toolName string, arguments json.RawMessage) string {
+	canonical := []byte(arguments)
+	dec := json.NewDecoder(bytes.NewReader(arguments))
+	dec.UseNumber()
+	var v any
+	if len(arguments) > 0 && dec.Decode(&v) == nil {
+		if data, err := json.Marshal(v); err == nil {
+			canonical = data
+		}
+	}
+	sum := sha256.Sum256(canonical)
+	return pluginID + ":" + toolName + ":" + hex.EncodeToString(sum[:])
+}
+
+// SetResultCacheSize bounds the memory of cached tool results in bytes, 0
+// for the default
+func (m *Manager) SetResultCacheSize(n int) {
+	if n == 0 {
+		n = defaultResultCacheSize
+	}
+	m.cache.setMaxSize(n)
+}
@@ -0,0 +1,120 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+)
+
+func TestResultCacheLRU(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Minute)
+	result := json.RawMessage(`"result"`) // 8 bytes, 9 with a one letter key
+	c := newResultCache(27)
+	for _, key := range []string{"a", "b", "c"} {
+		c.put(key, result, later)
+	}
+
+	// Using a keeps it, b is the least recently used once d comes in
+	if _, ok := c.get("a", now); !ok {
+		t.Fatal("a not cached")
+	}
+	c.put("d", result, later)
+	for key, want := range map[string]bool{"a": true, "b": false, "c": true, "d": true} {
+		if _, ok := c.get(key, now); ok != want {
+			t.Errorf("%s cached = %v, want %v", key, ok, want)
+		}
+	}
+	if c.size != 27 {
+		t.Errorf("size = %d, want 27", c.size)
+	}
+
+	// Expired results are dropped when asked for
+	if _, ok := c.get("a", later); ok {
+		t.Error("expired a returned")
+	}
+	if _, ok := c.entries["a"]; ok || c.size != 18 {
+		t.Errorf("expired a kept, size %d", c.size)
+	}
+
+	// Results larger than the cache aren't kept, shrinking it evicts
+	c.put("big", json.RawMessage(strings.Repeat("x", 30)), later)
+	if _, ok := c.get("big", now); ok {
+		t.Error("oversized result cached")
+	}
+	c.setMaxSize(9)
+	if len(c.entries) != 1 || c.size != 9 {
+		t.Errorf("%d results of %d bytes after shrinking, want 1 of 9", len(c.entries), c.size)
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	a := cacheKey("docs", "lookup", json.RawMessage(`{"q":"mcp","limit":10}`))
+	b := cacheKey("docs", "lookup", json.RawMessage(` { "limit": 10, "q": "mcp" } `))
+	if a != b {
+		t.Errorf("key order and whitespace changed the key: %s != %s", a, b)
+	}
+	for _, other := range []string{
+		cacheKey("docs", "lookup", json.RawMessage(`{"q":"mcp","limit":11}`)),
+		cacheKey("docs", "search", json.RawMessage(`{"q":"mcp","limit":10}`)),
+		cacheKey("wiki", "lookup", json.RawMessage(`{"q":"mcp","limit":10}`)),
+	} {
+		if other == a {
+			t.Errorf("different calls share the key %s", a)
+		}
+	}
+}
+
+func TestCachedExecute(t *testing.T) {
+	var down atomic.Bool
+	var calls atomic.Int32
+	var logs bytes.Buffer
+	m := NewManager(registry.New(), WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	cfg := newTestBackend(t, flakyServer(&down, &calls)).config()
+	cfg.CacheTTL = map[string]int{"fetch": 60}
+	startServer(t, m, "docs", cfg)
+	t.Cleanup(func() { m.StopServer("docs") })
+
+	ctx := context.Background()
+	for _, args := range []string{`{"q":"mcp","limit":10}`, `{"limit":10,"q":"mcp"}`} {
+		resp, err := m.Execute(ctx, "docs", "fetch", json.RawMessage(args))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resultText(t, resp); got != "ok" {
+			t.Errorf("result %q", got)
+		}
+	}
+	if n := calls.Load(); n != 1 {
+		t.Errorf("backend called %d times for the same arguments, want 1", n)
+	}
+	if !strings.Contains(logs.String(), "msg=exec:cache-hit plugin=docs tool=fetch") {
+		t.Errorf("cache hit not logged:\n%s", logs.String())
+	}
+
+	// Other arguments go to the backend
+	if _, err := m.Execute(ctx, "docs", "fetch", json.RawMessage(`{"q":"hub"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("backend called %d times, want 2", n)
+	}
+
+	// Failed calls aren't cached
+	down.Store(true)
+	for range 2 {
+		if _, err := m.Execute(ctx, "docs", "fetch", json.RawMessage(`{"q":"down"}`)); err == nil {
+			t.Fatal("call to the failing backend succeeded")
+		}
+	}
+	if n := calls.Load(); n != 4 {
+		t.Errorf("backend called %d times, want 4", n)
+	}
+}
@@ -0,0 +1,67 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+// cancelNotifyTimeout bounds posting notifications/cancelled to a backend
+const cancelNotifyTimeout = 5 * time.Second
+
+// cancelRoundTripper posts notifications/cancelled in the background. The
+// SDK sends them before a canceled call returns, under a context that is
+// never done, so a backend that stopped answering would hold the call past
+// its timeout and keep a failover from happening.
+type cancelRoundTripper struct {
+	base http.RoundTripper
+}
+
+func (rt *cancelRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := rt.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if req.Method != http.MethodPost || req.Body == nil {
+		return base.RoundTrip(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	msg, err := jsonrpc.DecodeMessage(body)
+	if note, ok := msg.(*jsonrpc.Request); err != nil || !ok || note.IsCall() || note.Method != "notifications/cancelled" {
+		return base.RoundTrip(req)
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(req.Context()), cancelNotifyTimeout)
+		defer cancel()
+		resp, err := base.RoundTrip(req.WithContext(ctx))
+		if err == nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+	}()
+	return &http.Response{
+		Status:     "202 Accepted",
+		StatusCode: http.StatusAccepted,
+		Proto:      req.Proto,
+		ProtoMajor: req.ProtoMajor,
+		ProtoMinor: req.ProtoMinor,
+		Header:     http.Header{},
+		Body:       http.NoBody,
+		Request:    req,
+	}, nil
+}
@@ -0,0 +1,66 @@
+package plugin
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// EventCapabilitiesChanged is emitted when a server advertises different
+// capabilities than on its previous connection, e.g. resources once
+// authentication completed
+const EventCapabilitiesChanged EventType = "capabilities_changed"
+
+// capabilityNames lists the capabilities a backend advertised in its
+// initialize result, sorted
+func capabilityNames(session *mcp.ClientSession) []string {
+	init := session.InitializeResult()
+	if init == nil || init.Capabilities == nil {
+		return nil
+	}
+	caps := init.Capabilities
+	var names []string
+	if caps.Completions != nil {
+		names = append(names, "completions")
+	}
+	if caps.Logging != nil {
+		names = append(names, "logging")
+	}
+	if caps.Prompts != nil {
+		names = append(names, "prompts")
+	}
+	if caps.Resources != nil {
+		names = append(names, "resources")
+	}
+	if caps.Tools != nil {
+		names = append(names, "tools")
+	}
+	return names
+}
+
+// recordCapabilities stores the capabilities a server advertised on its
+// latest connection, reporting a change from the previous one
+func (m *Manager) recordCapabilities(name string, caps []string) {
+	m.mu.Lock()
+	prev, seen := m.capabilities[name]
+	m.capabilities[name] = caps
+	m.mu.Unlock()
+
+	if !seen || slices.Equal(prev, caps) {
+		return
+	}
+	m.logger.Info("capabilities:changed", "plugin", name, "from", prev, "to", caps)
+	m.emit(EventCapabilitiesChanged, name, map[string]string{
+		"from": strings.Join(prev, ","),
+		"to":   strings.Join(caps, ","),
+	})
+}
+
+// Capabilities returns the capabilities a server advertised on its latest
+// connection
+func (m *Manager) Capabilities(name string) []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return slices.Clone(m.capabilities[name])
+}
@@ -0,0 +1,52 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrServerLimit is returned when starting a server would exceed maxServers
+var ErrServerLimit = errors.New("server limit reached")
+
+// SetMaxServers caps how many servers may run at once, 0 for no limit.
+// Running servers beyond a lowered cap are left alone.
+func (m *Manager) SetMaxServers(n int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.maxServers = n
+}
+
+// reserveSlot claims a place under maxServers for a server about to start.
+// Servers still connecting count too, so concurrent starts can't overshoot.
+func (m *Manager) reserveSlot(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.servers[name]; ok {
+		return nil
+	}
+	if m.maxServers > 0 && len(m.servers)+len(m.starting) >= m.maxServers {
+		return fmt.Errorf("%w: %d servers running or starting, maxServers is %d", ErrServerLimit, len(m.servers)+len(m.starting), m.maxServers)
+	}
+	m.starting[name] = struct{}{}
+	return nil
+}
+
+// releaseSlot ends the reservation, a started server holds its place in
+// m.servers from then on
+func (m *Manager) releaseSlot(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.starting, name)
+}
+
+// startWithinLimit starts a server if the server limit allows it
+func (m *Manager) startWithinLimit(name string, start func() error) error {
+	if err := m.reserveSlot(name); err != nil {
+		m.logger.Warn("start:refuse", "plugin", name, "err", err)
+		m.transition(name, StateStopped, "server limit reached")
+		m.emit(EventFailed, name, map[string]string{"error": err.Error()})
+		return err
+	}
+	defer m.releaseSlot(name)
+	return start()
+}
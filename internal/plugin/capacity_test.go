@@ -0,0 +1,60 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+)
+
+func TestMaxServers(t *testing.T) {
+	var logs bytes.Buffer
+	m := NewManager(registry.New(), WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	cfg := &config.Config{
+		Hub:        config.HubConfig{MaxServers: 2},
+		MCPServers: make(map[string]config.ServerConfig),
+	}
+	for _, name := range []string{"a", "b", "c", "d"} {
+		cfg.MCPServers[name] = newTestBackend(t, nil).config()
+	}
+	ctx := context.Background()
+	if err := m.LoadFromConfig(ctx, cfg); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	t.Cleanup(func() { m.StopAll(ctx) })
+
+	// The first servers by name take the slots
+	running := m.ListServers()
+	slices.Sort(running)
+	if !slices.Equal(running, []string{"a", "b"}) {
+		t.Errorf("running = %v, want [a b]", running)
+	}
+	for _, name := range []string{"c", "d"} {
+		if st := m.State(name); st != StateStopped {
+			t.Errorf("%s is %s, want %s", name, st, StateStopped)
+		}
+		if !strings.Contains(logs.String(), "msg=start:refuse plugin="+name) {
+			t.Errorf("refusing %s wasn't reported", name)
+		}
+	}
+
+	// Reloading a running server keeps its slot, a new one is refused
+	if err := m.ReloadServer(ctx, "a", cfg.MCPServers["a"]); err != nil {
+		t.Errorf("reload at the cap: %v", err)
+	}
+	if err := m.StartServer(ctx, "c", cfg.MCPServers["c"]); !errors.Is(err, ErrServerLimit) {
+		t.Errorf("start beyond the cap: err = %v", err)
+	}
+
+	// A stopped server frees its slot
+	m.StopServer("b")
+	if err := m.StartServer(ctx, "c", cfg.MCPServers["c"]); err != nil {
+		t.Errorf("start after freeing a slot: %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+package plugin
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+)
+
+func TestStartStopChurn(t *testing.T) {
+	reg := registry.New()
+	m := NewManager(reg, WithLogger(testLogger))
+	b := newTestBackend(t, nil)
+	cfg := b.config()
+
+	var starts, stops atomic.Int32
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 5 {
+				if m.StartServer(context.Background(), "echo", cfg) == nil {
+					starts.Add(1)
+				}
+				if m.StopServer("echo") == nil {
+					stops.Add(1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Every started session was stopped exactly once
+	if _, ok := m.GetServer("echo"); ok {
+		t.Fatal("server still running after the last stop")
+	}
+	if starts.Load() == 0 || starts.Load() != stops.Load() {
+		t.Errorf("%d starts and %d stops succeeded, want as many of each", starts.Load(), stops.Load())
+	}
+	if n := b.count("initialize"); n != int(starts.Load()) {
+		t.Errorf("backend saw %d sessions for %d starts", n, starts.Load())
+	}
+	if tools := reg.List(); len(tools) != 0 {
+		t.Errorf("stopped server left tools %v", tools)
+	}
+	if st := m.State("echo"); st != StateStopped {
+		t.Errorf("state = %s, want %s", st, StateStopped)
+	}
+}
@@ -0,0 +1,61 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// duplicatingServer is the echo server with a bug: it lists the echo tool a
+// second time, described as "second"
+func duplicatingServer() *mcp.Server {
+	server := echoServer()
+	server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			res, err := next(ctx, method, req)
+			if list, ok := res.(*mcp.ListToolsResult); ok && err == nil {
+				dup := *list.Tools[0]
+				dup.Description = "second"
+				list.Tools = append(list.Tools, &dup)
+			}
+			return res, err
+		}
+	})
+	return server
+}
+
+func TestDuplicateTools(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   string // description of the registered tool, "" if the start fails
+	}{
+		{"", ""},
+		{"first", ""},
+		{"last", "second"},
+		{"error", ""},
+	}
+	for _, tt := range tests {
+		m := newTestManager()
+		cfg := newTestBackend(t, duplicatingServer()).config()
+		cfg.DuplicateTools = tt.policy
+		err := m.StartServer(context.Background(), "dup", cfg)
+		if tt.policy == "error" {
+			if err == nil {
+				t.Error("error policy: start succeeded")
+				m.StopServer("dup")
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("policy %q: start: %v", tt.policy, err)
+		}
+		if n := len(m.reg.List()); n != 1 {
+			t.Errorf("policy %q: %d tools registered, want 1", tt.policy, n)
+		}
+		if tool, _ := m.reg.Tool("dup", "echo"); tool.Description != tt.want {
+			t.Errorf("policy %q: kept tool described %q, want %q", tt.policy, tool.Description, tt.want)
+		}
+		m.StopServer("dup")
+	}
+}
@@ -0,0 +1,37 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func noopTool(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+	return &mcp.CallToolResult{}, nil, nil
+}
+
+func TestDiscoveryWindowLateTools(t *testing.T) {
+	var server *mcp.Server
+	server = mcp.NewServer(&mcp.Implementation{Name: "slow-discovery"}, &mcp.ServerOptions{
+		// Announce a second tool shortly after the client connected
+		InitializedHandler: func(context.Context, *mcp.InitializedRequest) {
+			time.AfterFunc(20*time.Millisecond, func() {
+				mcp.AddTool(server, &mcp.Tool{Name: "late"}, noopTool)
+			})
+		},
+	})
+	mcp.AddTool(server, &mcp.Tool{Name: "early"}, noopTool)
+
+	m := newTestManager()
+	cfg := newTestBackend(t, server).config()
+	cfg.DiscoveryWindow = 500
+	startServer(t, m, "slow", cfg)
+
+	for _, tool := range []string{"early", "late"} {
+		if _, ok := m.reg.Tool("slow", tool); !ok {
+			t.Errorf("%s not registered", tool)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/transport"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// ErrDockerUnavailable is returned when a docker server can't start because
+// its container runtime CLI is missing or its daemon isn't reachable
+var ErrDockerUnavailable = errors.New("container runtime not available")
+
+// dockerCheckTimeout bounds the daemon probe, an unreachable daemon on a
+// remote DOCKER_HOST can otherwise hang for a long time
+const dockerCheckTimeout = 5 * time.Second
+
+// checkDocker verifies that the container runtime CLI (docker, podman or
+// nerdctl) is installed and its daemon answers, so docker servers fail with
+// an actionable error instead of a raw exec or connection error
+func checkDocker(ctx context.Context, runtime string) error {
+	path, err := exec.LookPath(runtime)
+	if err != nil {
+		return fmt.Errorf("%w: %s not found in PATH", ErrDockerUnavailable, runtime)
+	}
+
+	// Only the exit status matters, the output format differs per runtime
+	ctx, cancel := context.WithTimeout(ctx, dockerCheckTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, "info").CombinedOutput()
+	if err != nil {
+		msg := strings.TrimSpace(string(out))
+		if msg == "" {
+			msg = err.Error()
+		}
+		return fmt.Errorf("%w: daemon not reachable, is it running? (%s)", ErrDockerUnavailable, truncate(msg, maxInitOutput))
+	}
+	return nil
+}
+
+// warnDockerUnavailable logs a single warning per container runtime naming
+// every required docker server that won't start because it is unavailable
+func (m *Manager) warnDockerUnavailable(ctx context.Context, servers map[string]config.ServerConfig) {
+	byRuntime := make(map[string][]string)
+	for name, cfg := range servers {
+		if cfg.TransportType() == "docker" && !cfg.Optional {
+			runtime := cfg.ContainerRuntime()
+			byRuntime[runtime] = append(byRuntime[runtime], name)
+		}
+	}
+	for runtime, names := range byRuntime {
+		if err := checkDocker(ctx, runtime); err != nil {
+			sort.Strings(names)
+			m.logger.Warn("docker:unavailable", "runtime", runtime, "servers", strings.Join(names, ","), "err", err)
+		}
+	}
+}
+
+// containerStopTimeout bounds stopping a container left behind by its CLI
+const containerStopTimeout = 15 * time.Second
+
+// container is the container of a running docker server. It is named by the
+// hub and the runtime writes its ID to a cidfile once it is created.
+type container struct {
+	runtime string
+	name    string
+	cidfile string
+
+	mu sync.Mutex
+	id string
+}
+
+func newContainer(server string, cfg config.ServerConfig) *container {
+	name := transport.ContainerName(server)
+	return &container{runtime: cfg.ContainerRuntime(), name: name, cidfile: transport.CIDFile(name)}
+}
+
+// ID returns the container ID, "" until the runtime has created it
+func (c *container) ID() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.id == "" {
+		c.id = transport.ReadContainerID(c.cidfile)
+	}
+	return c.id
+}
+
+// stop stops the container by name. Closing stdin normally ends it, but once
+// the CLI is killed the container would keep running.
+func (c *container) stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), containerStopTimeout)
+	defer cancel()
+	_ = exec.CommandContext(ctx, c.runtime, "stop", c.name).Run()
+}
+
+// containerTransport runs a docker server's container, stopping it when the
+// connection closes without the container exiting
+type containerTransport struct {
+	mcp.Transport
+	container *container
+}
+
+func (t *containerTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	conn, err := t.Transport.Connect(ctx)
+	if err != nil {
+		os.Remove(t.container.cidfile)
+		return nil, err
+	}
+	return &containerConn{Connection: conn, container: t.container}, nil
+}
+
+type containerConn struct {
+	mcp.Connection
+	container *container
+}
+
+func (c *containerConn) Close() error {
+	err := c.Connection.Close()
+	// A clean exit of the CLI means the container exited too
+	if err != nil {
+		c.container.stop()
+	}
+	os.Remove(c.container.cidfile)
+	return err
+}
@@ -0,0 +1,64 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+)
+
+func TestDockerUnavailable(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("PATH", dir)
+	m := newTestManager()
+	cfg := config.ServerConfig{Type: "docker", Image: "example/mcp"}
+
+	err := m.StartServer(context.Background(), "ctr", cfg)
+	if !errors.Is(err, ErrDockerUnavailable) || !strings.Contains(err.Error(), "docker not found in PATH") {
+		t.Fatalf("without docker: err = %v", err)
+	}
+
+	// A docker CLI whose daemon is down
+	script := "#!/bin/sh\necho 'Cannot connect to the Docker daemon' >&2\nexit 1\n"
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	err = m.StartServer(context.Background(), "ctr", cfg)
+	if !errors.Is(err, ErrDockerUnavailable) || !strings.Contains(err.Error(), "daemon not reachable") || !strings.Contains(err.Error(), "Cannot connect") {
+		t.Fatalf("with the daemon down: err = %v", err)
+	}
+	if _, ok := m.GetServer("ctr"); ok {
+		t.Error("server registered without docker")
+	}
+}
+
+func TestLoadFromConfigDockerUnavailable(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	var logs bytes.Buffer
+	m := NewManager(registry.New(), WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	cfg := &config.Config{MCPServers: map[string]config.ServerConfig{
+		"required": {Type: "docker", Image: "example/required"},
+		"optional": {Type: "docker", Image: "example/optional", Optional: true},
+	}}
+	if err := m.LoadFromConfig(context.Background(), cfg); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	out := logs.String()
+	if strings.Count(out, "docker:unavailable") != 1 || !strings.Contains(out, "servers=required ") {
+		t.Errorf("want one startup warning naming only the required server:\n%s", out)
+	}
+	if !strings.Contains(out, "msg=start:skip plugin=optional") {
+		t.Errorf("optional server wasn't skipped:\n%s", out)
+	}
+	if !strings.Contains(out, "msg=start:fail plugin=required") {
+		t.Errorf("required server didn't fail:\n%s", out)
+	}
+}
@@ -0,0 +1,75 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrDrained is returned for calls to a server taken out of rotation
+var ErrDrained = errors.New("server is drained")
+
+// DrainStatus describes a server's drain state
+type DrainStatus struct {
+	Server   string `json:"server"`
+	Drained  bool   `json:"drained"`
+	InFlight int    `json:"inFlight"`
+	Standby  string `json:"standby,omitempty"`
+}
+
+// Drain takes a server out of rotation for maintenance. New calls go to its
+// standby, or fail if it has none, while calls already running finish. The
+// server stays connected and keeps its tools until Undrain.
+func (m *Manager) Drain(name string) (DrainStatus, error) {
+	return m.setDrained(name, true)
+}
+
+// Undrain returns a drained server to rotation
+func (m *Manager) Undrain(name string) (DrainStatus, error) {
+	return m.setDrained(name, false)
+}
+
+func (m *Manager) setDrained(name string, drained bool) (DrainStatus, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.servers[name]; !ok {
+		if _, ok := m.states[name]; !ok {
+			return DrainStatus{}, fmt.Errorf("server not found: %s", name)
+		}
+	}
+	if drained {
+		m.drained[name] = true
+	} else {
+		delete(m.drained, name)
+	}
+	st := m.drainStatusLocked(name)
+	m.logger.Info("drain", "plugin", name, "drained", drained, "inFlight", st.InFlight)
+	return st, nil
+}
+
+// DrainStatus reports whether a server is drained and how many calls it is
+// still running
+func (m *Manager) DrainStatus(name string) DrainStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.drainStatusLocked(name)
+}
+
+func (m *Manager) drainStatusLocked(name string) DrainStatus {
+	st := DrainStatus{
+		Server:  name,
+		Drained: m.drained[name],
+		Standby: m.failovers[name].standby,
+	}
+	if s, ok := m.servers[name]; ok {
+		l := s.gate.load()
+		st.InFlight = l.running + l.queued
+	}
+	return st
+}
+
+// isDrained reports whether new calls must avoid the server
+func (m *Manager) isDrained(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.drained[name]
+}
@@ -0,0 +1,38 @@
+package plugin
+
+import "time"
+
+// Drift is a difference between the declared config and the running servers:
+// "missing" (declared but not running), "gave_up" (declared but stopped after
+// failing to reconnect), "unexpected" (running but not declared) or "changed"
+// (running with a different config)
+type Drift struct {
+	Server string `json:"server"`
+	Kind   string `json:"kind"`
+}
+
+// DriftReport is the outcome of a drift check
+type DriftReport struct {
+	CheckedAt time.Time `json:"checkedAt"`
+	Drift     []Drift   `json:"drift"`
+	// Repaired is set if every repairable drift found was corrected.
+	// Servers the hub gave up on are never repaired.
+	Repaired bool `json:"repaired,omitempty"`
+}
+
+// SetDriftReport records the outcome of the latest drift check
+func (m *Manager) SetDriftReport(r DriftReport) {
+	m.mu.Lock()
+	m.drift = &r
+	m.mu.Unlock()
+}
+
+// DriftReport returns the outcome of the latest drift check, if any ran
+func (m *Manager) DriftReport() (DriftReport, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.drift == nil {
+		return DriftReport{}, false
+	}
+	return *m.drift, true
+}
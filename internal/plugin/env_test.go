@@ -0,0 +1,60 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// serveEnv serves a tool "getenv" over stdio answering with the value of
+// an environment variable of the backend process
+func serveEnv() {
+	server := mcp.NewServer(&mcp.Implementation{Name: "env"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "getenv"}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+		Name string `json:"name"`
+	}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: os.Getenv(args.Name)}}}, nil, nil
+	})
+	server.Run(context.Background(), &mcp.StdioTransport{})
+}
+
+func TestBackendEnvironment(t *testing.T) {
+	t.Setenv("MCP_HUB_TEST_SECRET", "s3cret")
+	isolated := false
+	tests := []struct {
+		name        string
+		inherit     *bool
+		passthrough []string
+		want        string
+	}{
+		{"inherited by default", nil, nil, "s3cret"},
+		{"isolated", &isolated, nil, ""},
+		{"passed through", &isolated, []string{"MCP_HUB_TEST_*"}, "s3cret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newTestManager()
+			cfg := config.ServerConfig{
+				Command:        os.Args[0],
+				Env:            map[string]string{backendEnv: "env"},
+				InheritEnv:     tt.inherit,
+				EnvPassthrough: tt.passthrough,
+			}
+			startServer(t, m, "env", cfg)
+			t.Cleanup(func() { m.StopServer("env") })
+
+			args, _ := json.Marshal(map[string]string{"name": "MCP_HUB_TEST_SECRET"})
+			resp, err := m.Execute(context.Background(), "env", "getenv", args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := resultText(t, resp); got != tt.want {
+				t.Errorf("backend sees MCP_HUB_TEST_SECRET=%q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// forwardedError marks a failed call whose backend JSON-RPC error should
+// reach the client unchanged
+type forwardedError struct {
+	rpc error
+	err error
+}
+
+func (e *forwardedError) Error() string { return e.err.Error() }
+func (e *forwardedError) Unwrap() error { return e.err }
+
+// toolError is a call the tool itself reported as failed, carrying the
+// backend's result so its error content isn't lost
+type toolError struct {
+	result json.RawMessage
+	msg    string
+}
+
+func newToolError(result *mcp.CallToolResult, data json.RawMessage) *toolError {
+	var text []string
+	for _, c := range result.Content {
+		if t, ok := c.(*mcp.TextContent); ok && t.Text != "" {
+			text = append(text, t.Text)
+		}
+	}
+	msg := "tool returned error"
+	if len(text) > 0 {
+		msg += ": " + strings.Join(text, "\n")
+	}
+	return &toolError{result: data, msg: msg}
+}
+
+func (e *toolError) Error() string { return e.msg }
+
+// ToolErrorResult returns the result of a call the tool reported as failed.
+// Returned from an MCP handler, it reaches the client as a result with
+// isError set and the tool's own error content.
+func ToolErrorResult(err error) (json.RawMessage, bool) {
+	var te *toolError
+	if errors.As(err, &te) {
+		return te.result, true
+	}
+	return nil, false
+}
+
+// BackendError returns the JSON-RPC error a backend answered a call with, if
+// its server forwards backend errors. Returned from an MCP handler as is, it
+// reaches the client with the backend's code, message and data.
+func BackendError(err error) (error, bool) {
+	var fe *forwardedError
+	if errors.As(err, &fe) {
+		return fe.rpc, true
+	}
+	return nil, false
+}
+
+// rpcError finds the JSON-RPC error response in err's chain. The SDK doesn't
+// export its error type, so it is recognized by its wire form.
+func rpcError(err error) error {
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		data, merr := json.Marshal(e)
+		if merr != nil {
+			continue
+		}
+		var wire struct {
+			Code    *int64  `json:"code"`
+			Message *string `json:"message"`
+		}
+		if json.Unmarshal(data, &wire) == nil && wire.Code != nil && wire.Message != nil {
+			return e
+		}
+	}
+	return nil
+}
+
+// FailureKind classifies a failed call: "unavailable" when no server took
+// it, e.g. one not running or drained, "error" for a transport or protocol
+// failure, "toolError" when the tool reported failure and "" otherwise
+func FailureKind(err error) string {
+	var ce *callError
+	if errors.As(err, &ce) {
+		return ce.kind
+	}
+	return ""
+}
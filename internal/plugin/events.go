@@ -0,0 +1,90 @@
+package plugin
+
+import (
+	"sync"
+	"time"
+)
+
+// EventType identifies a server lifecycle transition
+type EventType string
+
+const (
+	EventStarted      EventType = "started"
+	EventStopped      EventType = "stopped"
+	EventReconnecting EventType = "reconnecting"
+	EventFailed       EventType = "failed"
+	EventReloaded     EventType = "reloaded"
+	EventUnhealthy    EventType = "unhealthy"
+)
+
+// Event describes a lifecycle change of a single MCP server
+type Event struct {
+	Type    EventType         `json:"type"`
+	Server  string            `json:"server"`
+	Time    time.Time         `json:"time"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// eventBufferSize is the per-subscriber buffer before events are dropped
+const eventBufferSize = 64
+
+// eventBus fans out lifecycle events to subscribers without blocking the emitter
+type eventBus struct {
+	mu   sync.Mutex
+	subs map[chan Event]struct{}
+}
+
+func newEventBus() *eventBus {
+	return &eventBus{subs: make(map[chan Event]struct{})}
+}
+
+func (b *eventBus) subscribe() chan Event {
+	ch := make(chan Event, eventBufferSize)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs[ch] = struct{}{}
+	return ch
+}
+
+func (b *eventBus) unsubscribe(ch chan Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[ch]; !ok {
+		return
+	}
+	delete(b.subs, ch)
+	close(ch)
+}
+
+func (b *eventBus) publish(ev Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subs {
+		// best effort non-blocking, slow consumers lose events
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// SubscribeEvents returns a channel receiving server lifecycle events.
+// The channel is buffered; events are dropped for consumers that fall behind.
+func (m *Manager) SubscribeEvents() chan Event {
+	return m.events.subscribe()
+}
+
+// UnsubscribeEvents stops delivery to ch and closes it
+func (m *Manager) UnsubscribeEvents(ch chan Event) {
+	m.events.unsubscribe(ch)
+}
+
+// emit publishes a lifecycle event for the named server
+func (m *Manager) emit(typ EventType, name string, details map[string]string) {
+	m.events.publish(Event{
+		Type:    typ,
+		Server:  name,
+		Time:    time.Now(),
+		Details: details,
+	})
+}
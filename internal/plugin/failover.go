@@ -0,0 +1,103 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/logging"
+)
+
+// defaultFailoverOn are the failures moving a call to the standby unless
+// configured otherwise
+var defaultFailoverOn = []string{"unavailable", "error", "timeout"}
+
+// failover is the standby setup of a primary server
+type failover struct {
+	standby string
+	on      []string
+	timeout time.Duration
+}
+
+func failoverFor(cfg config.ServerConfig) (failover, bool) {
+	if cfg.Standby == "" {
+		return failover{}, false
+	}
+	on := cfg.FailoverOn
+	if len(on) == 0 {
+		on = defaultFailoverOn
+	}
+	return failover{
+		standby: cfg.Standby,
+		on:      on,
+		timeout: time.Duration(cfg.FailoverTimeout) * time.Second,
+	}, true
+}
+
+// callError classifies a failed call for failover decisions
+type callError struct {
+	kind string // "unavailable", "error" or "toolError"
+	err  error
+}
+
+func (e *callError) Error() string { return e.err.Error() }
+func (e *callError) Unwrap() error { return e.err }
+
+// setFailover records the standby setup of a server, so failover works even
+// while the primary isn't running
+func (m *Manager) setFailover(name string, cfg config.ServerConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if fo, ok := failoverFor(cfg); ok {
+		m.failovers[name] = fo
+	} else {
+		delete(m.failovers, name)
+	}
+}
+
+// executeWithFailover runs the call on pluginID and, if that fails in a way
+// its standby setup covers, once more on the standby
+func (m *Manager) executeWithFailover(ctx context.Context, pluginID, toolName string, arguments json.RawMessage) (json.RawMessage, error) {
+	m.mu.Lock()
+	fo, ok := m.failovers[pluginID]
+	m.mu.Unlock()
+	if !ok {
+		return m.execute(ctx, pluginID, toolName, arguments)
+	}
+
+	// A drained primary is skipped whatever failoverOn says
+	if m.isDrained(pluginID) {
+		m.logger.Info("failover", "plugin", pluginID, "standby", fo.standby, "tool", toolName, "reason", "drained")
+		return m.execute(ctx, fo.standby, toolName, arguments)
+	}
+
+	attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+	if fo.timeout > 0 {
+		attemptCtx, cancel = context.WithTimeout(ctx, fo.timeout)
+	}
+	resp, err := m.execute(attemptCtx, pluginID, toolName, arguments)
+	timedOut := errors.Is(attemptCtx.Err(), context.DeadlineExceeded)
+	cancel()
+
+	// A call the client gave up on is not retried
+	if err == nil || ctx.Err() != nil {
+		return resp, err
+	}
+
+	kind := ""
+	var ce *callError
+	if timedOut {
+		kind = "timeout"
+	} else if errors.As(err, &ce) {
+		kind = ce.kind
+	}
+	if !slices.Contains(fo.on, kind) {
+		return resp, err
+	}
+
+	m.logger.Warn("failover", "plugin", pluginID, "standby", fo.standby, "tool", toolName, "reason", kind, "err", logging.RedactError(err, nil))
+	return m.execute(ctx, fo.standby, toolName, arguments)
+}
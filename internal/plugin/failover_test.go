@@ -0,0 +1,89 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// failingServer has an echo tool that always reports failure
+func failingServer() *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "failing"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "echo"}, func(context.Context, *mcp.CallToolRequest, struct {
+		Text string `json:"text"`
+	}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{IsError: true, Content: []mcp.Content{&mcp.TextContent{Text: "broken"}}}, nil, nil
+	})
+	return server
+}
+
+func TestFailoverOnTimeout(t *testing.T) {
+	m := newTestManager()
+	primary := newTestBackend(t, nil)
+	standby := newTestBackend(t, nil)
+	startServer(t, m, "standby", standby.config())
+	cfg := primary.config()
+	cfg.Standby = "standby"
+	cfg.FailoverTimeout = 1
+	startServer(t, m, "primary", cfg)
+	t.Cleanup(func() {
+		primary.failing.Store(false)
+		m.StopServer("primary")
+		m.StopServer("standby")
+	})
+
+	primary.failing.Store(true)
+	start := time.Now()
+	resp, err := m.Execute(context.Background(), "primary", "echo", json.RawMessage(`{"text":"hello"}`))
+	if err != nil {
+		t.Fatalf("call failed instead of failing over: %v", err)
+	}
+	if got := resultText(t, resp); got != "hello" {
+		t.Errorf("echo returned %q", got)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("failover took %v, want about the 1s failoverTimeout", elapsed)
+	}
+	if primary.count("tools/call") != 1 || standby.count("tools/call") != 1 {
+		t.Errorf("primary got %d calls and standby %d, want one each", primary.count("tools/call"), standby.count("tools/call"))
+	}
+}
+
+func TestFailoverOnToolError(t *testing.T) {
+	tests := []struct {
+		on       []string
+		failover bool
+	}{
+		{nil, false},
+		{[]string{"toolError"}, true},
+	}
+	for _, tt := range tests {
+		m := newTestManager()
+		primary := newTestBackend(t, failingServer())
+		standby := newTestBackend(t, nil)
+		startServer(t, m, "standby", standby.config())
+		cfg := primary.config()
+		cfg.Standby = "standby"
+		cfg.FailoverOn = tt.on
+		startServer(t, m, "primary", cfg)
+
+		resp, err := m.Execute(context.Background(), "primary", "echo", json.RawMessage(`{"text":"hello"}`))
+		if tt.failover {
+			if err != nil {
+				t.Errorf("failoverOn %v: %v", tt.on, err)
+			} else if got := resultText(t, resp); got != "hello" {
+				t.Errorf("failoverOn %v: echo returned %q", tt.on, got)
+			}
+		} else if err == nil {
+			t.Errorf("failoverOn %v: tool error was failed over", tt.on)
+		}
+		if n := standby.count("tools/call"); (n == 1) != tt.failover {
+			t.Errorf("failoverOn %v: standby got %d calls", tt.on, n)
+		}
+		m.StopServer("primary")
+		m.StopServer("standby")
+	}
+}
@@ -0,0 +1,92 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+)
+
+// ErrQueueFull is returned for calls arriving while a server's queue is full
+var ErrQueueFull = errors.New("server queue is full")
+
+// defaultMaxConcurrency bounds parallel servers without a maxConcurrency, so
+// a flood of calls to one slow server can't tie up the hub
+const defaultMaxConcurrency = 16
+
+// callGate admits calls to one server: at most limit run at once and at most
+// maxQueue wait for a slot (0 means no limit). The counters are
+// atomics so they can be read without contending calls.
+type callGate struct {
+	slots    chan struct{}
+	limit    int
+	maxQueue int64
+
+	running atomic.Int64
+	queued  atomic.Int64
+}
+
+func newCallGate(cfg config.ServerConfig) *callGate {
+	g := &callGate{maxQueue: int64(cfg.MaxQueue)}
+	switch {
+	case cfg.Serial():
+		g.limit = 1
+	case cfg.MaxConcurrency > 0:
+		g.limit = cfg.MaxConcurrency
+	default:
+		g.limit = defaultMaxConcurrency
+	}
+	g.slots = make(chan struct{}, g.limit)
+	return g
+}
+
+// enter waits for a slot, or fails if the queue is full or ctx is done. The
+// returned func releases the slot.
+func (g *callGate) enter(ctx context.Context) (func(), error) {
+	select {
+	case g.slots <- struct{}{}:
+		g.running.Add(1)
+		return g.leave, nil
+	default:
+	}
+
+	if n := g.queued.Add(1); g.maxQueue > 0 && n > g.maxQueue {
+		g.queued.Add(-1)
+		return nil, fmt.Errorf("%w: %d calls waiting", ErrQueueFull, g.maxQueue)
+	}
+	defer g.queued.Add(-1)
+	select {
+	case g.slots <- struct{}{}:
+		g.running.Add(1)
+		return g.leave, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (g *callGate) leave() {
+	g.running.Add(-1)
+	if g.slots != nil {
+		<-g.slots
+	}
+}
+
+// load is a snapshot of a gate's counters
+type load struct {
+	running, queued int
+	limit           int
+}
+
+func (g *callGate) load() load {
+	return load{running: int(g.running.Load()), queued: int(g.queued.Load()), limit: g.limit}
+}
+
+// saturation is the share of the limit in use, 0 without a limit
+func (l load) saturation() float64 {
+	if l.limit == 0 {
+		return 0
+	}
+	return float64(l.running) / float64(l.limit)
+}
@@ -0,0 +1,233 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestCallGateLimits(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.ServerConfig
+		want int
+	}{
+		{"stdio is serial", config.ServerConfig{Command: "server"}, 1},
+		{"docker is serial", config.ServerConfig{Image: "server"}, 1},
+		{"http has the default limit", config.ServerConfig{URL: "http://localhost"}, defaultMaxConcurrency},
+		{"sse has the default limit", config.ServerConfig{Type: "sse", URL: "http://localhost/sse"}, defaultMaxConcurrency},
+		{"http with a limit", config.ServerConfig{URL: "http://localhost", MaxConcurrency: 4}, 4},
+		{"parallel stdio", config.ServerConfig{Command: "server", ConcurrencyModel: "parallel", MaxConcurrency: 3}, 3},
+		{"serial http", config.ServerConfig{URL: "http://localhost", ConcurrencyModel: "serial"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newCallGate(tt.cfg).load().limit; got != tt.want {
+				t.Errorf("limit = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallGateQueue(t *testing.T) {
+	g := newCallGate(config.ServerConfig{URL: "http://localhost", MaxConcurrency: 2, MaxQueue: 1})
+	ctx := context.Background()
+
+	release1, err := g.enter(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release2, err := g.enter(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The third call waits for a slot
+	entered := make(chan func())
+	go func() {
+		release, err := g.enter(ctx)
+		if err != nil {
+			t.Error(err)
+			close(entered)
+			return
+		}
+		entered <- release
+	}()
+	waitFor(t, func() bool { return g.load().queued == 1 })
+
+	// With the queue full the fourth is rejected
+	if _, err := g.enter(ctx); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("call over the queue: %v, want ErrQueueFull", err)
+	}
+
+	release1()
+	release3 := <-entered
+	if l := g.load(); l.running != 2 || l.queued != 0 || l.saturation() != 1 {
+		t.Errorf("load = %+v", l)
+	}
+	release2()
+	release3()
+	if l := g.load(); l.running != 0 {
+		t.Errorf("%d calls still running", l.running)
+	}
+}
+
+func TestCallGateCancel(t *testing.T) {
+	g := newCallGate(config.ServerConfig{Command: "server"})
+	release, err := g.enter(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := g.enter(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("queued call: %v, want DeadlineExceeded", err)
+	}
+	if l := g.load(); l.queued != 0 || l.running != 1 {
+		t.Errorf("load after the cancelled call = %+v", l)
+	}
+}
+
+// waitFor polls cond until it holds or a second passed
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); !cond(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+	}
+}
+
+// concurrencyServer has a tool "hold" that takes 50ms and records how many
+// calls of it ran at once at most
+func concurrencyServer(peak *atomic.Int32) *mcp.Server {
+	var running atomic.Int32
+	server := mcp.NewServer(&mcp.Implementation{Name: "concurrency"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "hold"}, func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	return server
+}
+
+func TestConcurrencyModels(t *testing.T) {
+	tests := []struct {
+		model  string
+		serial bool
+	}{
+		{"serial", true},
+		{"parallel", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			var peak atomic.Int32
+			m := newTestManager()
+			cfg := newTestBackend(t, concurrencyServer(&peak)).config()
+			cfg.ConcurrencyModel = tt.model
+			startServer(t, m, "backend", cfg)
+			t.Cleanup(func() { m.StopServer("backend") })
+
+			var wg sync.WaitGroup
+			for range 4 {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					if _, err := m.Execute(context.Background(), "backend", "hold", nil); err != nil {
+						t.Error(err)
+					}
+				}()
+			}
+			wg.Wait()
+			if got := peak.Load(); (got == 1) != tt.serial {
+				t.Errorf("%d calls ran at once", got)
+			}
+		})
+	}
+}
+
+// blockingServer has a tool "block" answering once release is closed
+func blockingServer(release <-chan struct{}) *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "blocking"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "block"}, func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+		select {
+		case <-release:
+		case <-ctx.Done():
+		}
+		return &mcp.CallToolResult{}, nil, nil
+	})
+	return server
+}
+
+func TestSaturatedServerIsolation(t *testing.T) {
+	m := newTestManager()
+	release := make(chan struct{})
+	slow := newTestBackend(t, blockingServer(release)).config()
+	slow.ConcurrencyModel = "parallel"
+	slow.MaxConcurrency = 2
+	slow.MaxQueue = 8
+	startServer(t, m, "slow", slow)
+	startServer(t, m, "fast", newTestBackend(t, nil).config())
+	t.Cleanup(func() {
+		m.StopServer("slow")
+		m.StopServer("fast")
+	})
+
+	// A flood of calls fills the slow server's slots and queue
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Execute(ctx, "slow", "block", json.RawMessage(`{}`))
+		}()
+	}
+	status := func(name string) ServerStatus {
+		for _, st := range m.ServerStatuses() {
+			if st.Name == name {
+				return st
+			}
+		}
+		t.Fatalf("no status for %s", name)
+		return ServerStatus{}
+	}
+	waitFor(t, func() bool { st := status("slow"); return st.InFlight == 2 && st.Queued == 8 })
+
+	// The other server answers right away, without queueing
+	for range 5 {
+		start := time.Now()
+		resp, err := m.Execute(ctx, "fast", "echo", json.RawMessage(`{"text":"hi"}`))
+		if err != nil {
+			t.Fatalf("call to fast: %v", err)
+		}
+		if got := resultText(t, resp); got != "hi" {
+			t.Errorf("fast answered %q", got)
+		}
+		if d := time.Since(start); d > time.Second {
+			t.Errorf("call to fast took %v", d)
+		}
+	}
+	if st := status("fast"); st.Queued != 0 || st.Rejected != 0 {
+		t.Errorf("fast status = %+v", st)
+	}
+
+	close(release)
+	wg.Wait()
+}
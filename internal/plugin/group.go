@@ -0,0 +1,136 @@
+package plugin
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+)
+
+// refreshGroupTools re-registers the tools of the group server is or was a
+// member of from the running members' tools. A tool several members provide
+// is listed once, with the members as its backends.
+func (m *Manager) refreshGroupTools(server string) {
+	m.mu.Lock()
+	members := make(map[string][]string)
+	for name, s := range m.servers {
+		if g := s.Config().Group; g != "" {
+			members[g] = append(members[g], name)
+		}
+	}
+	for _, names := range members {
+		slices.Sort(names)
+	}
+	affected := make(map[string]bool)
+	for _, groups := range []map[string][]string{m.groups, members} {
+		for g, names := range groups {
+			if slices.Contains(names, server) {
+				affected[g] = true
+			}
+		}
+	}
+	m.groups = members
+	m.mu.Unlock()
+	if len(affected) == 0 {
+		return
+	}
+
+	all := m.reg.All()
+	for g := range affected {
+		var tools []registry.Tool
+		index := make(map[string]int)
+		for _, member := range members[g] {
+			for _, t := range all {
+				if t.PluginID != member {
+					continue
+				}
+				if i, ok := index[t.ID]; ok {
+					tools[i].Backends = append(tools[i].Backends, member)
+					continue
+				}
+				t.Backends = []string{member}
+				index[t.ID] = len(tools)
+				tools = append(tools, t)
+			}
+		}
+		m.reg.UnregisterTools(g)
+		if len(tools) > 0 {
+			m.reg.RegisterTools(g, tools)
+			m.logger.Info("group:expose", "plugin", g, "tools", len(tools), "members", len(members[g]))
+		}
+	}
+}
+
+// groupCandidate is a group member able to take a call
+type groupCandidate struct {
+	name   string
+	weight int
+}
+
+// resolveGroup picks the member of a server group to run a call on, other
+// servers are returned unchanged. Members that are drained, don't provide
+// the tool or whose breaker is open are skipped.
+func (m *Manager) resolveGroup(pluginID, toolName string) (string, error) {
+	m.mu.Lock()
+	members, ok := m.groups[pluginID]
+	if !ok {
+		m.mu.Unlock()
+		return pluginID, nil
+	}
+	var candidates []groupCandidate
+	for _, name := range members {
+		s, ok := m.servers[name]
+		if !ok || m.drained[name] {
+			continue
+		}
+		cfg := s.Config()
+		if state, _ := m.breakerStatusLocked(name, cfg); state == breakerOpen {
+			continue
+		}
+		weight := cfg.Weight
+		if weight == 0 {
+			weight = 1
+		}
+		candidates = append(candidates, groupCandidate{name, weight})
+	}
+	b, ok := m.balancers[pluginID]
+	if !ok {
+		b = &balancer{current: make(map[string]int)}
+		m.balancers[pluginID] = b
+	}
+	m.mu.Unlock()
+
+	candidates = slices.DeleteFunc(candidates, func(c groupCandidate) bool {
+		_, ok := m.reg.Tool(c.name, toolName)
+		return !ok
+	})
+	if len(candidates) == 0 {
+		return "", &callError{"unavailable", fmt.Errorf("no member of group %s can take %s", pluginID, toolName)}
+	}
+	return b.pick(candidates), nil
+}
+
+// balancer spreads a group's calls over its members in proportion to their
+// weights with smooth weighted round-robin, so of members weighted 2 and 1
+// the first gets every other call rather than two in a row
+type balancer struct {
+	mu      sync.Mutex
+	current map[string]int
+}
+
+func (b *balancer) pick(candidates []groupCandidate) string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	total := 0
+	best := ""
+	for _, c := range candidates {
+		b.current[c.name] += c.weight
+		total += c.weight
+		if best == "" || b.current[c.name] > b.current[best] {
+			best = c.name
+		}
+	}
+	b.current[best] -= total
+	return best
+}
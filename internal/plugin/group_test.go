@@ -0,0 +1,60 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBalancerPick(t *testing.T) {
+	b := &balancer{current: make(map[string]int)}
+	candidates := []groupCandidate{{"a", 2}, {"b", 1}}
+	var got []string
+	for range 6 {
+		got = append(got, b.pick(candidates))
+	}
+	// Interleaved rather than a, a, b
+	if want := []string{"a", "b", "a", "a", "b", "a"}; !slices.Equal(got, want) {
+		t.Errorf("picks = %v, want %v", got, want)
+	}
+}
+
+func TestGroupWeightedRoundRobin(t *testing.T) {
+	m := newTestManager()
+	for name, weight := range map[string]int{"a": 3, "b": 1} {
+		// versionServer answers with the member's name
+		cfg := newTestBackend(t, versionServer(name)).config()
+		cfg.Group = "pool"
+		cfg.Weight = weight
+		cfg.BreakerThreshold = 1
+		startServer(t, m, name, cfg)
+	}
+	t.Cleanup(func() { m.StopAll(context.Background()) })
+
+	calls := func(n int) map[string]int {
+		t.Helper()
+		counts := make(map[string]int)
+		for range n {
+			resp, err := m.Execute(context.Background(), "pool", "version", json.RawMessage(`{}`))
+			if err != nil {
+				t.Fatalf("call to pool: %v", err)
+			}
+			counts[resultText(t, resp)]++
+		}
+		return counts
+	}
+	if got := calls(8); got["a"] != 6 || got["b"] != 2 {
+		t.Errorf("calls per member = %v, want a 6 and b 2", got)
+	}
+
+	// A member whose breaker is open is skipped
+	now := time.Now()
+	m.mu.Lock()
+	m.breakers["b"] = &breaker{failures: 1, first: now, openedAt: now}
+	m.mu.Unlock()
+	if got := calls(4); got["a"] != 4 {
+		t.Errorf("calls per member with b's breaker open = %v, want all on a", got)
+	}
+}
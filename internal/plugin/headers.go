@@ -0,0 +1,103 @@
+package plugin
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/logging"
+	"github.com/amir-the-h/mcp-hub/internal/transport"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// headerTransport injects the configured headers into every outbound request
+// of an HTTP backend. The header set can be swapped at runtime so rotated
+// credentials apply to the next request without reconnecting.
+type headerTransport struct {
+	base     http.RoundTripper
+	mu       sync.RWMutex
+	headers  map[string]string
+	provider transport.HeaderProvider // headers resolved per request, may be nil
+}
+
+func newHeaderTransport(cfg config.ServerConfig, base http.RoundTripper) *headerTransport {
+	t := &headerTransport{base: base}
+	t.set(cfg)
+	return t
+}
+
+func (t *headerTransport) set(cfg config.ServerConfig) {
+	provider := headerProvider(cfg)
+	t.mu.Lock()
+	t.headers = maps.Clone(cfg.Headers)
+	t.provider = provider
+	t.mu.Unlock()
+}
+
+// headerProvider resolves the server's dynamicHeaders, nil without any
+func headerProvider(cfg config.ServerConfig) transport.HeaderProvider {
+	if len(cfg.DynamicHeaders) == 0 {
+		return nil
+	}
+	sources := make(map[string]transport.HeaderSource, len(cfg.DynamicHeaders))
+	for name, src := range cfg.DynamicHeaders {
+		sources[name] = transport.HeaderSource{
+			Env:     src.Env,
+			Command: src.Command,
+			Prefix:  src.Prefix,
+			TTL:     time.Duration(src.CacheSeconds) * time.Second,
+		}
+	}
+	return transport.NewHeaderProvider(sources)
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	t.mu.RLock()
+	headers, provider := t.headers, t.provider
+	t.mu.RUnlock()
+
+	req = req.Clone(req.Context())
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if provider != nil {
+		resolved, err := provider(req.Context())
+		if err != nil {
+			return nil, logging.RedactError(fmt.Errorf("failed to resolve headers: %w", err), req.Header)
+		}
+		for k, v := range resolved {
+			req.Header.Set(k, v)
+		}
+	}
+	// Continue the caller's trace on the backend
+	otel.GetTextMapPropagator().Inject(req.Context(), propagation.HeaderCarrier(req.Header))
+	return base.RoundTrip(req)
+}
+
+// UpdateHeaders applies new outbound headers (e.g. a rotated auth token) to a
+// running HTTP or SSE server in place, without tearing down its session
+func (m *Manager) UpdateHeaders(name string, cfg config.ServerConfig) error {
+	server, ok := m.GetServer(name)
+	if !ok {
+		return fmt.Errorf("server not found: %s", name)
+	}
+	if server.headers == nil {
+		return fmt.Errorf("server %s does not use an HTTP transport", name)
+	}
+
+	server.headers.set(cfg)
+	server.setConfig(cfg)
+
+	m.logger.Info("headers:updated", "plugin", name)
+	m.emit(EventReloaded, name, map[string]string{"mode": "credentials"})
+	return nil
+}
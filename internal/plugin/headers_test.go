@@ -0,0 +1,59 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestUpdateHeadersKeepsSession(t *testing.T) {
+	m := newTestManager()
+	b := newTestBackend(t, nil)
+	cfg := b.config()
+	cfg.Headers = map[string]string{"Authorization": "Bearer old"}
+	startServer(t, m, "echo", cfg)
+	t.Cleanup(func() { m.StopServer("echo") })
+
+	events := m.SubscribeEvents()
+	defer m.UnsubscribeEvents(events)
+
+	rotated := b.config()
+	rotated.Headers = map[string]string{"Authorization": "Bearer new"}
+	if err := m.UpdateHeaders("echo", rotated); err != nil {
+		t.Fatalf("update headers: %v", err)
+	}
+	if ev := nextEvent(t, events, EventReloaded); ev.Details["mode"] != "credentials" {
+		t.Errorf("reloaded event = %+v", ev)
+	}
+
+	resp, err := m.Execute(context.Background(), "echo", "echo", json.RawMessage(`{"text":"hi"}`))
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if got := resultText(t, resp); got != "hi" {
+		t.Errorf("echo returned %q", got)
+	}
+	if got := b.header("tools/call").Get("Authorization"); got != "Bearer new" {
+		t.Errorf("call sent Authorization %q, want the rotated token", got)
+	}
+
+	// The session was kept: no second handshake, no stop or start
+	if n := b.count("initialize"); n != 1 {
+		t.Errorf("%d initialize requests, want 1", n)
+	}
+	if _, ok := m.reg.Tool("echo", "echo"); !ok {
+		t.Error("tools dropped by the update")
+	}
+	timeout := time.After(50 * time.Millisecond)
+	for {
+		select {
+		case ev := <-events:
+			if ev.Type == EventStopped || ev.Type == EventStarted {
+				t.Errorf("unexpected %s event", ev.Type)
+			}
+		case <-timeout:
+			return
+		}
+	}
+}
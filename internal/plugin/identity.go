@@ -0,0 +1,41 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"path"
+)
+
+// ErrForbidden is returned when the calling client may not use a tool
+var ErrForbidden = errors.New("forbidden")
+
+// Identity is an authenticated client of the hub and the tools it may call
+type Identity struct {
+	Name string
+	// Allow holds glob patterns matched against "<plugin>:<tool>"
+	Allow []string
+}
+
+// Allows reports whether the client may call toolName on pluginID
+func (id *Identity) Allows(pluginID, toolName string) bool {
+	name := pluginID + ":" + toolName
+	for _, pattern := range id.Allow {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a context carrying the calling client, Execute then
+// enforces the client's access policy
+func WithIdentity(ctx context.Context, id *Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+func identityFrom(ctx context.Context) *Identity {
+	id, _ := ctx.Value(identityKey{}).(*Identity)
+	return id
+}
@@ -0,0 +1,71 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+// initCapture keeps the initialize result exactly as the backend sent it.
+// The SDK decodes it into a struct, dropping fields it doesn't know. As the
+// last of a server's wire rewriters it sees the result before any other
+// rewriter changes it.
+type initCapture struct {
+	mu       sync.Mutex
+	id       jsonrpc.ID
+	sent     bool
+	answered bool
+	result   json.RawMessage // nil if the initialize call failed
+}
+
+// outgoing notes the ID of the initialize request as sent
+func (c *initCapture) outgoing(req *jsonrpc.Request) (*jsonrpc.Request, bool) {
+	if req.Method == "initialize" {
+		c.mu.Lock()
+		c.id, c.sent = req.ID, true
+		c.mu.Unlock()
+	}
+	return req, false
+}
+
+// incoming keeps the result of the response to the initialize request
+func (c *initCapture) incoming(resp *jsonrpc.Response) (*jsonrpc.Response, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.sent && !c.answered && resp.ID == c.id {
+		c.answered = true
+		c.result = bytes.Clone(resp.Result)
+	}
+	return resp, false
+}
+
+// done reports whether the initialize request was answered
+func (c *initCapture) done() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.answered
+}
+
+// raw returns the captured result, if any
+func (c *initCapture) raw() json.RawMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.result
+}
+
+// InitializeResult returns the initialize result a running server sent when
+// it connected, including fields the SDK doesn't know
+func (m *Manager) InitializeResult(name string) (json.RawMessage, error) {
+	server, ok := m.GetServer(name)
+	if !ok {
+		return nil, fmt.Errorf("server not found: %s", name)
+	}
+	if server.initialize != nil {
+		return server.initialize, nil
+	}
+	// Not captured, e.g. over a transport framing it unexpectedly
+	return json.Marshal(server.session.InitializeResult())
+}
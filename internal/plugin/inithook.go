@@ -0,0 +1,89 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/logging"
+	"github.com/amir-the-h/mcp-hub/internal/transport"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+const (
+	defaultInitTimeout = 30 * time.Second
+	// maxInitOutput bounds how much hook output ends up in errors
+	maxInitOutput = 500
+)
+
+// runInitHook runs the server's setup step, if any
+func (m *Manager) runInitHook(ctx context.Context, name string, session *mcp.ClientSession, cfg config.ServerConfig) error {
+	hook := cfg.Init
+	if hook == nil {
+		return nil
+	}
+
+	timeout := defaultInitTimeout
+	if hook.Timeout > 0 {
+		timeout = time.Duration(hook.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	var err error
+	if len(hook.Command) > 0 {
+		err = runInitCommand(ctx, hook.Command, cfg)
+	} else {
+		err = runInitTool(ctx, session, hook.Tool, hook.Arguments)
+	}
+	if err != nil {
+		m.logger.Warn("init:fail", "plugin", name, "duration", time.Since(start), "err", logging.RedactError(err, nil))
+		return fmt.Errorf("init failed: %w", err)
+	}
+	m.logger.Info("init:ok", "plugin", name, "duration", time.Since(start))
+	return nil
+}
+
+// runInitCommand runs a local command with the environment the server's own
+// process would get
+func runInitCommand(ctx context.Context, argv []string, cfg config.ServerConfig) error {
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Env = transport.Environ(cfg.InheritsEnv() && cfg.TransportType() == "stdio", cfg.EnvPassthrough, cfg.Env)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := truncate(strings.TrimSpace(string(out)), maxInitOutput); msg != "" {
+			return fmt.Errorf("%s: %w: %s", argv[0], err, msg)
+		}
+		return fmt.Errorf("%s: %w", argv[0], err)
+	}
+	return nil
+}
+
+// runInitTool calls a tool on the server, a tool error fails the hook
+func runInitTool(ctx context.Context, session *mcp.ClientSession, tool string, args map[string]any) error {
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: tool, Arguments: args})
+	if err != nil {
+		return fmt.Errorf("tool %s: %w", tool, err)
+	}
+	if result.IsError {
+		var texts []string
+		for _, c := range result.Content {
+			if t, ok := c.(*mcp.TextContent); ok {
+				texts = append(texts, t.Text)
+			}
+		}
+		return fmt.Errorf("tool %s returned error: %s", tool, truncate(strings.Join(texts, " "), maxInitOutput))
+	}
+	return nil
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
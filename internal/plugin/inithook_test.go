@@ -0,0 +1,53 @@
+package plugin
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+)
+
+func TestInitHookRunsBeforeTools(t *testing.T) {
+	m := newTestManager()
+	b := newTestBackend(t, nil)
+	cfg := b.config()
+	cfg.Init = &config.InitHook{Tool: "echo", Arguments: map[string]any{"text": "warm up"}}
+	startServer(t, m, "echo", cfg)
+	t.Cleanup(func() { m.StopServer("echo") })
+
+	methods := b.methods()
+	call, list := slices.Index(methods, "tools/call"), slices.Index(methods, "tools/list")
+	if call < 0 || list < 0 || call > list {
+		t.Errorf("requests %v, want the init call before tools/list", methods)
+	}
+	if _, ok := m.reg.Tool("echo", "echo"); !ok {
+		t.Error("tools not registered after init")
+	}
+}
+
+func TestInitHookFailureAbortsStart(t *testing.T) {
+	m := newTestManager()
+	b := newTestBackend(t, nil)
+	cfg := b.config()
+	cfg.Init = &config.InitHook{Command: []string{"sh", "-c", "echo login expired >&2; exit 1"}}
+
+	err := m.StartServer(context.Background(), "echo", cfg)
+	if err == nil {
+		m.StopServer("echo")
+		t.Fatal("start succeeded despite the failing init")
+	}
+	if !strings.Contains(err.Error(), "login expired") {
+		t.Errorf("error = %v, want the hook's output", err)
+	}
+	if _, ok := m.GetServer("echo"); ok {
+		t.Error("server active after a failed init")
+	}
+	if n := b.count("tools/list"); n != 0 {
+		t.Errorf("tools listed %d times despite the failing init", n)
+	}
+	if len(m.reg.List()) != 0 {
+		t.Errorf("tools registered: %v", m.reg.List())
+	}
+}
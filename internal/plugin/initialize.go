@@ -0,0 +1,112 @@
+package plugin
+
+import (
+	"encoding/json"
+	"sync/atomic"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+)
+
+// initializePatch rewrites the params of the outgoing initialize request.
+// The SDK client builds its own InitializeParams, so per-server handshake
+// customizations are applied on the wire instead, by a patchRewriter.
+type initializePatch func(params map[string]any)
+
+// experimentalPatch merges experimental capabilities into the initialize params
+func experimentalPatch(experimental map[string]any) initializePatch {
+	return func(params map[string]any) {
+		caps, _ := params["capabilities"].(map[string]any)
+		if caps == nil {
+			caps = make(map[string]any)
+		}
+		exp, _ := caps["experimental"].(map[string]any)
+		if exp == nil {
+			exp = make(map[string]any)
+		}
+		for k, v := range experimental {
+			exp[k] = v
+		}
+		caps["experimental"] = exp
+		params["capabilities"] = caps
+	}
+}
+
+// extraPatch merges arbitrary fields into the initialize params, recursing
+// into objects so e.g. extra clientInfo fields keep the standard ones
+func extraPatch(extra map[string]any) initializePatch {
+	return func(params map[string]any) {
+		mergeObject(params, extra)
+	}
+}
+
+// mergeObject merges src into dst, recursing into objects present in both
+func mergeObject(dst, src map[string]any) {
+	for k, v := range src {
+		if sub, ok := v.(map[string]any); ok {
+			if existing, ok := dst[k].(map[string]any); ok {
+				mergeObject(existing, sub)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
+// apply runs the patch against raw params, returning the original on failure
+func (p initializePatch) apply(raw json.RawMessage) json.RawMessage {
+	params := make(map[string]any)
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return raw
+		}
+	}
+	p(params)
+	out, err := json.Marshal(params)
+	if err != nil {
+		return raw
+	}
+	return out
+}
+
+// patchRewriter applies an initialize patch to the initialize request
+type patchRewriter struct {
+	patch initializePatch
+	sent  atomic.Bool
+}
+
+func (p *patchRewriter) outgoing(req *jsonrpc.Request) (*jsonrpc.Request, bool) {
+	if req.Method != "initialize" {
+		return req, false
+	}
+	p.sent.Store(true)
+	out := *req
+	out.Params = p.patch.apply(req.Params)
+	return &out, true
+}
+
+func (p *patchRewriter) incoming(resp *jsonrpc.Response) (*jsonrpc.Response, bool) {
+	return resp, false
+}
+
+func (p *patchRewriter) done() bool { return p.sent.Load() }
+
+// initializePatchFor builds the initialize patch for a server, or nil if none.
+// initializeExtra is applied last so it can override anything.
+func initializePatchFor(cfg config.ServerConfig) initializePatch {
+	var patches []initializePatch
+	if len(cfg.ExperimentalCapabilities) > 0 {
+		patches = append(patches, experimentalPatch(cfg.ExperimentalCapabilities))
+	}
+	if extra := cfg.InitializeExtraObject(); len(extra) > 0 {
+		patches = append(patches, extraPatch(extra))
+	}
+	if len(patches) == 0 {
+		return nil
+	}
+	return func(params map[string]any) {
+		for _, p := range patches {
+			p(params)
+		}
+	}
+}
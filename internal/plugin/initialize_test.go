@@ -0,0 +1,68 @@
+package plugin
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// initializeParams returns the params of the initialize request b received
+func initializeParams(t *testing.T, b *testBackend) map[string]any {
+	t.Helper()
+	req := b.request("initialize")
+	if req == nil {
+		t.Fatal("no initialize request")
+	}
+	var params map[string]any
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		t.Fatal(err)
+	}
+	return params
+}
+
+func TestInitializeExperimentalCapabilities(t *testing.T) {
+	m := newTestManager()
+	backend := newTestBackend(t, nil)
+	cfg := backend.config()
+	cfg.ExperimentalCapabilities = map[string]any{
+		"acme/auth": map[string]any{"scheme": "bearer"},
+		"streaming": true,
+	}
+	startServer(t, m, "echo", cfg)
+
+	caps, _ := initializeParams(t, backend)["capabilities"].(map[string]any)
+	if !reflect.DeepEqual(caps["experimental"], cfg.ExperimentalCapabilities) {
+		t.Errorf("experimental capabilities = %v, want %v", caps["experimental"], cfg.ExperimentalCapabilities)
+	}
+}
+
+func TestInitializeWithoutPatch(t *testing.T) {
+	m := newTestManager()
+	backend := newTestBackend(t, nil)
+	startServer(t, m, "echo", backend.config())
+
+	caps, _ := initializeParams(t, backend)["capabilities"].(map[string]any)
+	if _, ok := caps["experimental"]; ok {
+		t.Errorf("experimental capabilities sent unconfigured: %v", caps)
+	}
+}
+
+func TestInitializeExtra(t *testing.T) {
+	m := newTestManager()
+	backend := newTestBackend(t, nil)
+	cfg := backend.config()
+	cfg.InitializeExtra = json.RawMessage(`{"clientInfo":{"vendor":"acme"},"acme/session":"abc"}`)
+	startServer(t, m, "echo", cfg)
+
+	params := initializeParams(t, backend)
+	if params["acme/session"] != "abc" {
+		t.Errorf("acme/session = %v, want abc", params["acme/session"])
+	}
+	info, _ := params["clientInfo"].(map[string]any)
+	if info["vendor"] != "acme" || info["name"] == nil {
+		t.Errorf("clientInfo = %v, want the standard fields and vendor", info)
+	}
+	if params["protocolVersion"] == nil {
+		t.Errorf("extra fields replaced the standard params: %v", params)
+	}
+}
@@ -0,0 +1,86 @@
+package plugin
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/logging"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// keepaliveFailures is how many pings in a row may fail before the session
+// is given up on
+const keepaliveFailures = 3
+
+// keepalive periodically pings a server. A failed ping marks the server
+// degraded until a ping succeeds again, and after keepaliveFailures in a row
+// the connection is torn down, so a backend that is running but wedged
+// gets reconnected by watchSession. Each ping must answer within one interval.
+func (m *Manager) keepalive(server *MCPServer, interval time.Duration, method string) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	failures := 0
+	for {
+		select {
+		case <-server.done:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		err := ping(ctx, server.session, method)
+		cancel()
+
+		select {
+		case <-server.done:
+			return
+		default:
+		}
+		if err == nil {
+			if failures > 0 {
+				m.logger.Info("keepalive:recovered", "plugin", server.name, "method", method, "failures", failures)
+				m.transition(server.name, StateConnected, "keepalive recovered")
+			}
+			failures = 0
+			continue
+		}
+
+		failures++
+		m.logger.Warn("keepalive:fail", "plugin", server.name, "method", method, "failures", failures, "err", logging.RedactError(err, nil))
+		m.emit(EventUnhealthy, server.name, map[string]string{
+			"check":    "keepalive",
+			"failures": strconv.Itoa(failures),
+			"error":    logging.RedactError(err, nil).Error(),
+		})
+		if failures < keepaliveFailures {
+			m.transition(server.name, StateDegraded, "keepalive failed")
+			continue
+		}
+		// Closing the session would wait for the unanswered pings
+		server.abort()
+		return
+	}
+}
+
+// ping sends a single keepalive request using method. It returns when ctx is
+// done even if the request is still being written, as the SDK writes HTTP
+// requests under the session's context rather than the call's.
+func ping(ctx context.Context, session *mcp.ClientSession, method string) error {
+	done := make(chan error, 1)
+	go func() {
+		if method == "tools/list" {
+			_, err := session.ListTools(ctx, &mcp.ListToolsParams{})
+			done <- err
+			return
+		}
+		done <- session.Ping(ctx, nil)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,36 @@
+package plugin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+)
+
+func TestLoadFromConfigLenient(t *testing.T) {
+	m := newTestManager()
+	cfg := &config.Config{
+		Hub: config.HubConfig{Validation: "lenient"},
+		MCPServers: map[string]config.ServerConfig{
+			"one":    newTestBackend(t, nil).config(),
+			"two":    newTestBackend(t, nil).config(),
+			"broken": {Type: "http"},
+		},
+	}
+	if err := m.LoadFromConfig(context.Background(), cfg); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	t.Cleanup(func() { m.StopAll(context.Background()) })
+
+	for _, name := range []string{"one", "two"} {
+		if st := m.State(name); st != StateConnected {
+			t.Errorf("%s is %s, want %s", name, st, StateConnected)
+		}
+	}
+	if _, ok := m.GetServer("broken"); ok {
+		t.Error("invalid server was started")
+	}
+	if reason := m.InvalidServers()["broken"]; reason == "" {
+		t.Errorf("invalid servers = %v, want broken", m.InvalidServers())
+	}
+}
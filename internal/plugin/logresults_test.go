@@ -0,0 +1,52 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// secretServer has a lookup tool returning a token in its structured content
+func secretServer() *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "secrets"}, nil)
+	server.AddTool(&mcp.Tool{Name: "lookup", InputSchema: map[string]any{"type": "object"}}, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{
+			Content:           []mcp.Content{&mcp.TextContent{Text: "found it"}},
+			StructuredContent: map[string]any{"user": "alice", "token": "s3cret"},
+		}, nil
+	})
+	return server
+}
+
+func TestLogResults(t *testing.T) {
+	for _, enabled := range []bool{false, true} {
+		var logs bytes.Buffer
+		m := NewManager(registry.New(), WithLogger(slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))))
+		b := newTestBackend(t, secretServer())
+		cfg := b.config()
+		cfg.LogResults = enabled
+		startServer(t, m, "secrets", cfg)
+
+		if _, err := m.Execute(context.Background(), "secrets", "lookup", json.RawMessage(`{}`)); err != nil {
+			t.Fatal(err)
+		}
+		m.StopServer("secrets")
+
+		out := logs.String()
+		if got := strings.Contains(out, "exec:result"); got != enabled {
+			t.Errorf("logResults %v: result logged = %v", enabled, got)
+		}
+		if enabled && (!strings.Contains(out, "found it") || !strings.Contains(out, "***")) {
+			t.Errorf("logResults %v: redacted result missing from the log:\n%s", enabled, out)
+		}
+		if strings.Contains(out, "s3cret") {
+			t.Errorf("logResults %v: log leaks the token:\n%s", enabled, out)
+		}
+	}
+}
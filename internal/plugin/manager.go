@@ -3,54 +3,180 @@ package plugin
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os/exec"
+	"slices"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/logging"
 	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/amir-the-h/mcp-hub/internal/transport"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // MCPServer represents a connected MCP server using the official SDK
 type MCPServer struct {
-	name    string
-	client  *mcp.Client
-	session *mcp.ClientSession
-	mu      sync.Mutex
+	name      string
+	client    *mcp.Client
+	session   *mcp.ClientSession
+	headers   *headerTransport // nil for non-HTTP transports
+	container *container       // nil for non-docker transports
+	done      chan struct{}    // closed once the session has ended
+	cancel    func()           // releases the connect context once the session has ended
+	gate      *callGate        // admits calls within the server's limits
+	transport *abortTransport  // tears the session down when closing it hangs
+	calls     sync.WaitGroup   // calls dispatched to this instance, see ReplaceServers
+	active    atomic.Int32     // number of calls counted in calls
+
+	// initialize result as the backend sent it
+	initialize json.RawMessage
+
+	cfgMu sync.RWMutex
+	cfg   config.ServerConfig
+}
+
+// connect connects client over transport, returning as soon as ctx is done.
+// The SDK finishes writing the initialize request even after ctx is canceled,
+// so a session connecting too late is closed in the background.
+func connect(ctx context.Context, client *mcp.Client, transport mcp.Transport) (*mcp.ClientSession, error) {
+	type result struct {
+		session *mcp.ClientSession
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		session, err := client.Connect(ctx, transport, nil)
+		done <- result{session, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.session, r.err
+	case <-ctx.Done():
+		go func() {
+			if r := <-done; r.session != nil {
+				r.session.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
 }
 
+// defaultStartupTimeout bounds connecting to a server and listing its tools
+// when the server sets no timeout
+const defaultStartupTimeout = 30 * time.Second
+
 // Manager manages MCP servers using the official SDK
 type Manager struct {
-	reg     *registry.Registry
-	mu      sync.Mutex
-	servers map[string]*MCPServer
+	reg        *registry.Registry
+	mu         sync.Mutex
+	servers    map[string]*MCPServer
+	states     map[string]ServerState
+	invalid    map[string]error
+	reconnects map[string]reconnectHandle
+	failovers  map[string]failover
+	drained    map[string]bool
+	gaveUp     map[string]bool // stopped after failing to reconnect
+	virtuals   map[string]config.VirtualServer
+	groups     map[string][]string // running members of each server group
+	balancers  map[string]*balancer
+	ops        map[string]chan struct{}
+	starting   map[string]struct{} // servers connecting, counted against maxServers
+	maxServers int
+	drift      *DriftReport
+	// capabilities advertised on each server's latest connection
+	capabilities map[string][]string
+	events       *eventBus
+	streams      *streams
+	schemas      *schemaCache
+	cache        *resultCache
+	tracer       trace.Tracer
+	limiter      atomic.Pointer[rateLimiter]
+	breakers     map[string]*breaker
+	metrics      managerMetrics
+	logger       *slog.Logger
+}
+
+// Option configures a Manager
+type Option func(*Manager)
+
+// WithLogger sets the logger for server lifecycle and tool call events
+func WithLogger(logger *slog.Logger) Option {
+	return func(m *Manager) {
+		if logger != nil {
+			m.logger = logger
+		}
+	}
 }
 
 // NewManager creates a new plugin manager
-func NewManager(reg *registry.Registry) *Manager {
-	return &Manager{
-		reg:     reg,
-		servers: make(map[string]*MCPServer),
+func NewManager(reg *registry.Registry, opts ...Option) *Manager {
+	m := &Manager{
+		reg:          reg,
+		servers:      make(map[string]*MCPServer),
+		states:       make(map[string]ServerState),
+		reconnects:   make(map[string]reconnectHandle),
+		failovers:    make(map[string]failover),
+		drained:      make(map[string]bool),
+		gaveUp:       make(map[string]bool),
+		ops:          make(map[string]chan struct{}),
+		starting:     make(map[string]struct{}),
+		capabilities: make(map[string][]string),
+		breakers:     make(map[string]*breaker),
+		balancers:    make(map[string]*balancer),
+		events:       newEventBus(),
+		streams:      newStreams(),
+		schemas:      newSchemaCache(),
+		cache:        newResultCache(defaultResultCacheSize),
+		tracer:       otel.Tracer(instrumentationName),
+		logger:       logging.Default(),
+	}
+	for _, opt := range opts {
+		opt(m)
 	}
+	m.metrics = newManagerMetrics(m)
+	return m
 }
 
 // LoadFromConfig loads and starts servers from configuration
 func (m *Manager) LoadFromConfig(ctx context.Context, cfg *config.Config) error {
-	if err := cfg.Validate(); err != nil {
+	enabledServers, invalid, err := cfg.ActiveServers()
+	if err != nil {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
+	m.SetInvalidServers(invalid)
+	m.SetVirtualServers(cfg.VirtualServers)
+	m.SetMaxServers(cfg.Hub.MaxServers)
+	m.SetRateLimit(cfg.Hub.RateLimit)
+	m.SetResultCacheSize(cfg.Hub.ResultCacheSize)
+	m.warnDockerUnavailable(ctx, enabledServers)
 
-	enabledServers := cfg.GetEnabledServers()
+	// Start in name order, so a server limit always keeps the same servers
+	names := make([]string, 0, len(enabledServers))
+	for name := range enabledServers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
 
-	for name, srvCfg := range enabledServers {
+	for _, name := range names {
+		srvCfg := enabledServers[name]
 		if err := m.StartServer(ctx, name, srvCfg); err != nil {
-			log.Printf("warning: failed to start server %s: %v", name, err)
+			if srvCfg.Optional && errors.Is(err, ErrDockerUnavailable) {
+				m.logger.Info("start:skip", "plugin", name, "reason", "optional", "err", logging.RedactError(err, nil))
+				continue
+			}
+			m.logger.Warn("start:fail", "plugin", name, "err", logging.RedactError(err, nil))
 		} else {
-			log.Printf("loaded MCP server: %s (%s transport)", name, srvCfg.TransportType())
+			m.logger.Info("start:ok", "plugin", name, "transport", srvCfg.TransportType())
 		}
 	}
 
@@ -59,53 +185,176 @@ func (m *Manager) LoadFromConfig(ctx context.Context, cfg *config.Config) error
 
 // StartServer starts a single MCP server based on configuration
 func (m *Manager) StartServer(ctx context.Context, name string, cfg config.ServerConfig) error {
+	defer m.lockServer(name)()
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return m.startServer(ctx, name, cfg)
+}
+
+func (m *Manager) startServer(ctx context.Context, name string, cfg config.ServerConfig) error {
+	return m.startWithinLimit(name, func() error { return m.connectWithRetry(ctx, name, cfg) })
+}
+
+func (m *Manager) connectServer(ctx context.Context, name string, cfg config.ServerConfig) error {
 	m.mu.Lock()
 	if _, exists := m.servers[name]; exists {
 		m.mu.Unlock()
 		return fmt.Errorf("server %s already started", name)
 	}
 	m.mu.Unlock()
+	m.setFailover(name, cfg)
+
+	m.transition(name, StateConnecting, "start")
+	d, err := m.dialServer(ctx, name, cfg)
+	if err != nil {
+		return m.startFailed(name, err)
+	}
+	m.activateServer(d)
+	return nil
+}
+
+// dialedServer is a connected server whose tools, resources and prompts are
+// listed but not yet registered
+type dialedServer struct {
+	server    *MCPServer
+	tools     []registry.Tool
+	resources []registry.Resource
+	prompts   []registry.Prompt
+}
 
+// startError is a failed start and the state it leaves the server in
+type startError struct {
+	state  ServerState
+	reason string
+	err    error
+}
+
+func (e *startError) Error() string { return e.err.Error() }
+func (e *startError) Unwrap() error { return e.err }
+
+// startFailed records a failed dial in the server's state and returns the
+// underlying error. A stopped server failed before connecting and gets no
+// failed event.
+func (m *Manager) startFailed(name string, err error) error {
+	var se *startError
+	if !errors.As(err, &se) {
+		return err
+	}
+	m.transition(name, se.state, se.reason)
+	if se.state != StateStopped {
+		m.emit(EventFailed, name, map[string]string{"error": se.err.Error()})
+	}
+	return se.err
+}
+
+// dialServer connects to a server and lists what it exposes without touching
+// the registry or the server's state, so a replacement can be brought up
+// alongside a running server
+func (m *Manager) dialServer(ctx context.Context, name string, cfg config.ServerConfig) (*dialedServer, error) {
+	ctx, span := m.startConnectSpan(ctx, name, cfg)
+	d, err := m.dial(ctx, name, cfg)
+	endConnectSpan(span, d, err)
+	return d, err
+}
+
+// dial does the work of dialServer inside its span
+func (m *Manager) dial(ctx context.Context, name string, cfg config.ServerConfig) (*dialedServer, error) {
 	// Create MCP client
+	// listChanged is signalled when the backend announces new tools
+	listChanged := make(chan struct{}, 1)
 	client := mcp.NewClient(&mcp.Implementation{
 		Name:    "mcp-hub",
 		Version: "0.1.0",
-	}, nil)
+	}, &mcp.ClientOptions{
+		ProgressNotificationHandler: m.streams.handleProgress,
+		ToolListChangedHandler: func(context.Context, *mcp.ToolListChangedRequest) {
+			select {
+			case listChanged <- struct{}{}:
+			default:
+			}
+		},
+	})
+
+	// Answer the server's roots/list with the configured roots
+	for _, root := range cfg.Roots {
+		client.AddRoots(&mcp.Root{URI: root.URI, Name: root.Name})
+	}
 
 	// Create appropriate transport
 	var transport mcp.Transport
+	var headers *headerTransport
+	var ctr *container
 
 	switch cfg.TransportType() {
 	case "stdio":
 		// For stdio, use CommandTransport
-		cmd := exec.Command(cfg.Command, cfg.Args...)
-		if cfg.Env != nil {
-			cmd.Env = append(cmd.Env, envMapToSlice(cfg.Env)...)
-		}
-		transport = &mcp.CommandTransport{Command: cmd}
+		transport = &mcp.CommandTransport{Command: stdioCommand(cfg)}
 
 	case "docker":
-		// For Docker, build docker run command
-		args := buildDockerArgs(cfg)
-		cmd := exec.Command("docker", args...)
-		transport = &mcp.CommandTransport{Command: cmd}
+		// Fail early and clearly if docker itself is missing
+		if err := checkDocker(ctx, cfg.ContainerRuntime()); err != nil {
+			return nil, &startError{StateStopped, "docker unavailable", err}
+		}
+
+		// For Docker, build the run command, which podman and nerdctl
+		// accept unchanged
+		ctr = newContainer(name, cfg)
+		args := buildDockerArgs(cfg, ctr)
+		cmd := exec.Command(cfg.ContainerRuntime(), args...)
+		transport = &containerTransport{Transport: &mcp.CommandTransport{Command: cmd}, container: ctr}
 
 	case "http":
 		// For HTTP/Streamable HTTP, use StreamableClientTransport
+		headers = newHeaderTransport(cfg, m.rateLimitTransport(cfg))
 		transport = &mcp.StreamableClientTransport{
 			Endpoint:   cfg.URL,
-			HTTPClient: &http.Client{},
+			HTTPClient: &http.Client{Transport: &cancelRoundTripper{base: headers}},
 		}
 
 	case "sse":
 		// For legacy SSE, use SSEClientTransport
+		headers = newHeaderTransport(cfg, m.rateLimitTransport(cfg))
 		transport = &mcp.SSEClientTransport{
 			Endpoint:   cfg.URL,
-			HTTPClient: &http.Client{},
+			HTTPClient: &http.Client{Transport: &cancelRoundTripper{base: headers}},
 		}
 
+	case "ws":
+		// The SDK has no WebSocket client, headers are sent on the upgrade
+		transport = &wsTransport{cfg: cfg}
+
 	default:
-		return fmt.Errorf("unsupported transport type: %s", cfg.TransportType())
+		return nil, &startError{StateDisconnected, "unsupported transport", fmt.Errorf("unsupported transport type: %s", cfg.TransportType())}
+	}
+
+	// Apply per-server initialize customizations, adapt the SDK's messages
+	// to backends expecting them differently and capture the raw
+	// initialize result
+	wire := m.wireHookFor(name, cfg)
+	transport = withWireHook(transport, wire)
+	abort := &abortTransport{Transport: transport}
+	transport = abort
+
+	// Bound connecting and the initial listing. The SDK ties HTTP
+	// connections to the connect context, so the deadline cancels it from a
+	// timer that is stopped once the server is up, rather than expiring the
+	// context under a healthy session.
+	startupTimeout := defaultStartupTimeout
+	if cfg.Timeout > 0 {
+		startupTimeout = time.Duration(cfg.Timeout) * time.Second
+	}
+	connCtx, cancelConn := context.WithCancel(ctx)
+	startup := time.AfterFunc(startupTimeout, cancelConn)
+	startupErr := func(err error) error {
+		if connCtx.Err() != nil && ctx.Err() == nil {
+			return fmt.Errorf("server did not start within %s: %w", startupTimeout, err)
+		}
+		return err
+	}
+	fail := func() {
+		startup.Stop()
+		cancelConn()
 	}
 
 	// Attempt to connect to the server
@@ -124,72 +373,254 @@ func (m *Manager) StartServer(ctx context.Context, name string, cfg config.Serve
 	// Note: "streamable-http" is normalized to "http" in config, so it uses the same code path
 	//
 	// TODO: Update to newer SDK version when available that fixes this issue
-	log.Printf("connect:attempt server=%s transport=%s", name, cfg.TransportType())
-	session, err := client.Connect(ctx, transport, nil)
+	m.logger.Info("connect:attempt", "plugin", name, "transport", cfg.TransportType())
+	session, err := connect(connCtx, client, transport)
 	if err != nil {
-		log.Printf("connect:fail server=%s transport=%s err=%v", name, cfg.TransportType(), err)
-		return fmt.Errorf("failed to connect: %w", err)
+		err = startupErr(err)
+		fail()
+		m.logger.Warn("connect:fail", "plugin", name, "transport", cfg.TransportType(), "err", logging.RedactError(err, nil))
+		return nil, &startError{StateDisconnected, "connect failed", fmt.Errorf("failed to connect: %w", err)}
+	}
+
+	m.logger.Info("connect:ok", "plugin", name, "transport", cfg.TransportType())
+	if ctr != nil {
+		m.logger.Info("connect:container", "plugin", name, "container", ctr.name, "containerId", ctr.ID())
 	}
 
-	log.Printf("connect:ok server=%s transport=%s", name, cfg.TransportType())
-	
 	// For HTTP and Streamable HTTP transports, log a warning about potential notification errors
 	// These errors are harmless and don't affect functionality
 	// Note: "streamable-http" is normalized to "http" in config, so it's covered by this check
 	if cfg.TransportType() == "http" || cfg.TransportType() == "sse" {
-		log.Printf("warning: HTTP/Streamable HTTP transport detected for server %s. If the server reports listChanged: true, "+
-			"you may see 'rejected by transport: undelivered message' errors in logs. "+
-			"This is a known SDK limitation and doesn't affect functionality.", name)
+		m.logger.Debug("connect:http-notifications", "plugin", name,
+			"note", "if the server reports listChanged, 'rejected by transport: undelivered message' errors are a known, harmless SDK limitation")
 	}
 
 	// Create server instance
 	server := &MCPServer{
-		name:    name,
-		client:  client,
-		session: session,
+		name:       name,
+		client:     client,
+		session:    session,
+		headers:    headers,
+		container:  ctr,
+		done:       make(chan struct{}),
+		gate:       newCallGate(cfg),
+		transport:  abort,
+		initialize: wire.capture.raw(),
+		cfg:        cfg,
+	}
+
+	// Run the setup step before tools are listed and registered
+	if err := m.runInitHook(ctx, name, session, cfg); err != nil {
+		session.Close()
+		fail()
+		return nil, &startError{StateDisconnected, "init failed", err}
 	}
 
 	// List tools
-	toolsResult, err := session.ListTools(ctx, &mcp.ListToolsParams{})
+	tools, err := listTools(connCtx, session)
+	if err != nil {
+		if connCtx.Err() != nil {
+			// Closing waits for the stuck request, don't hold up startup
+			go session.Close()
+		} else {
+			session.Close()
+		}
+		err = startupErr(err)
+		fail()
+		return nil, &startError{StateDisconnected, "list tools failed", fmt.Errorf("failed to list tools: %w", err)}
+	}
+
+	// Some backends announce further tools shortly after the initial list
+	if cfg.DiscoveryWindow > 0 {
+		window := time.Duration(cfg.DiscoveryWindow) * time.Millisecond
+		tools = m.collectLateTools(connCtx, name, session, tools, listChanged, window)
+	}
+
+	// A backend listing the same tool twice has a bug, surface it
+	tools, err = m.dedupeTools(name, tools, cfg.DuplicateTools)
 	if err != nil {
 		session.Close()
-		return fmt.Errorf("failed to list tools: %w", err)
+		fail()
+		return nil, &startError{StateDisconnected, "duplicate tools", err}
 	}
 
-	log.Printf("MCP server %s: discovered %d tools", name, len(toolsResult.Tools))
+	// Only expose the tools the config selects
+	tools = slices.DeleteFunc(tools, func(t *mcp.Tool) bool { return !cfg.ExposesTool(t.Name) })
+	tools = m.checkInputSchemas(name, tools, cfg.MissingInputSchema)
 
-	// Register tools in registry
-	registryTools := make([]registry.Tool, len(toolsResult.Tools))
-	for i, tool := range toolsResult.Tools {
-		registryTools[i] = registry.Tool{
-			ID:          tool.Name,
-			Name:        tool.Name,
-			Description: tool.Description,
-			PluginID:    name,
-		}
+	m.logger.Info("discover", "plugin", name, "tools", len(tools))
+
+	registryTools := toRegistryTools(name, tools)
+	d := &dialedServer{
+		server:    server,
+		tools:     registryTools,
+		resources: m.listResources(connCtx, name, session),
+		prompts:   m.listPrompts(connCtx, name, session),
 	}
-	m.reg.RegisterTools(name, registryTools)
+
+	// The timer fired after the last step, the connection is gone
+	if !startup.Stop() {
+		session.Close()
+		return nil, &startError{StateDisconnected, "startup timeout", startupErr(context.Canceled)}
+	}
+	server.cancel = cancelConn
+	return d, nil
+}
+
+// activateServer registers a dialed server's tools, resources and prompts
+// and makes it the running instance of its name
+func (m *Manager) activateServer(d *dialedServer) {
+	server := d.server
+	name := server.name
+	cfg := server.Config()
+
+	// Members of a group are listed under the group's name only
+	m.reg.SetHidden(name, cfg.Group != "")
+	m.reg.RegisterTools(name, d.tools)
+	m.refreshVirtualTools(name)
+	m.reg.RegisterResources(name, d.resources)
+	m.reg.RegisterPrompts(name, d.prompts)
 
 	// Store server
 	m.mu.Lock()
 	m.servers[name] = server
 	m.mu.Unlock()
+	m.refreshGroupTools(name)
 
-	return nil
+	m.recordCapabilities(name, capabilityNames(server.session))
+	m.transition(name, StateConnected, "")
+	go m.watchSession(server)
+	if cfg.PingInterval > 0 {
+		go m.keepalive(server, time.Duration(cfg.PingInterval)*time.Second, cfg.PingMethod)
+	}
+
+	m.emit(EventStarted, name, map[string]string{
+		"transport": cfg.TransportType(),
+		"tools":     fmt.Sprintf("%d", len(d.tools)),
+	})
 }
 
 // Execute executes a tool on an MCP server
 func (m *Manager) Execute(ctx context.Context, pluginID string, toolName string, arguments json.RawMessage) (json.RawMessage, error) {
+	ctx, span := m.startCallSpan(ctx, pluginID, toolName)
+	start := time.Now()
+	resp, err := m.authorizedExecute(ctx, pluginID, toolName, arguments)
+	dur := time.Since(start)
+	server, tool := m.metricNames(pluginID, toolName)
+	endCallSpan(ctx, span, server, tool, len(resp), dur, err)
+	m.recordCall(server, tool, dur, err)
+	return resp, err
+}
+
+// authorizedExecute enforces the calling client's access policy on the
+// requested tool, a failover to a standby is covered by the same decision
+func (m *Manager) authorizedExecute(ctx context.Context, pluginID string, toolName string, arguments json.RawMessage) (json.RawMessage, error) {
+	if id := identityFrom(ctx); id != nil && !id.Allows(pluginID, toolName) {
+		m.logger.Warn("exec:deny", "client", id.Name, "plugin", pluginID, "tool", toolName)
+		return nil, fmt.Errorf("%w: client %s may not call %s:%s", ErrForbidden, id.Name, pluginID, toolName)
+	}
+	if err := m.checkRateLimit(ctx, pluginID, toolName); err != nil {
+		return nil, err
+	}
+	pluginID, err := m.resolveVirtual(pluginID, toolName)
+	if err != nil {
+		return nil, err
+	}
+	pluginID, err = m.resolveGroup(pluginID, toolName)
+	if err != nil {
+		return nil, err
+	}
+	return m.executeWithFailover(ctx, pluginID, toolName, arguments)
+}
+
+// execute performs a tool call on a single server
+func (m *Manager) execute(ctx context.Context, pluginID string, toolName string, arguments json.RawMessage) (json.RawMessage, error) {
+	// Counted under the lock, so a replacement swapping the server out
+	// afterwards waits for this call
 	m.mu.Lock()
 	server, ok := m.servers[pluginID]
+	if ok {
+		server.calls.Add(1)
+		server.active.Add(1)
+	}
 	m.mu.Unlock()
 
 	if !ok {
-		return nil, fmt.Errorf("server not found: %s", pluginID)
+		return nil, &callError{"unavailable", fmt.Errorf("server not found: %s", pluginID)}
+	}
+	defer func() {
+		server.active.Add(-1)
+		server.calls.Done()
+	}()
+	if m.isDrained(pluginID) {
+		return nil, &callError{"unavailable", fmt.Errorf("%w: %s", ErrDrained, pluginID)}
+	}
+
+	cfg := server.Config()
+	if !cfg.ExposesTool(toolName) {
+		return nil, fmt.Errorf("tool %s is not exposed by server %s", toolName, pluginID)
 	}
 
-	server.mu.Lock()
-	defer server.mu.Unlock()
+	// Answer repeated calls of a cached tool without the backend
+	ttl := time.Duration(cfg.CacheTTLFor(toolName)) * time.Second
+	var key string
+	if ttl > 0 {
+		key = cacheKey(pluginID, toolName, arguments)
+		if resp, ok := m.cache.get(key, time.Now()); ok {
+			m.logger.Info("exec:cache-hit", "plugin", pluginID, "tool", toolName, "resultBytes", len(resp))
+			m.metrics.cacheHits.WithLabelValues(pluginID, toolName).Inc()
+			return resp, nil
+		}
+	}
+
+	// Let the tool's input transform rewrite the arguments
+	original := arguments
+	arguments, err := transformArguments(ctx, cfg, pluginID, toolName, arguments)
+	if err != nil {
+		m.logger.Warn("exec:transform-fail", "plugin", pluginID, "tool", toolName, "stage", "input", "err", err)
+		return nil, fmt.Errorf("input transform failed: %w", err)
+	}
+
+	// Reject oversized input before it reaches the backend
+	if limit := cfg.MaxArgumentsSize; limit > 0 && len(arguments) > limit {
+		m.logger.Warn("exec:reject", "plugin", pluginID, "tool", toolName, "argsBytes", len(arguments), "max", limit)
+		return nil, fmt.Errorf("arguments too large: %d bytes exceeds the %d byte limit of server %s", len(arguments), limit, pluginID)
+	}
+
+	// Catch malformed calls without a round trip to the backend
+	if cfg.ValidateArgs {
+		if err := m.validateArguments(pluginID, toolName, arguments); err != nil {
+			m.logger.Info("exec:reject", "plugin", pluginID, "tool", toolName, "err", err)
+			return nil, err
+		}
+	}
+
+	// Fail fast while the server keeps failing
+	bc, err := m.enterBreaker(pluginID, cfg)
+	if err != nil {
+		m.logger.Info("exec:reject", "plugin", pluginID, "tool", toolName, "err", err)
+		return nil, &callError{"unavailable", err}
+	}
+	defer bc.end()
+
+	timing := timingFrom(ctx)
+	if timing == nil {
+		timing = &Timing{}
+	}
+
+	// Serial backends get one call at a time
+	queued := time.Now()
+	release, err := server.gate.enter(ctx)
+	if err != nil {
+		if errors.Is(err, ErrQueueFull) {
+			m.logger.Warn("exec:reject", "plugin", pluginID, "tool", toolName, "err", err)
+			m.metrics.rejections.WithLabelValues(pluginID).Inc()
+			return nil, &callError{"unavailable", err}
+		}
+		return nil, err
+	}
+	defer release()
+	timing.Queue = time.Since(queued)
 
 	// Parse arguments
 	var args map[string]any
@@ -201,77 +632,187 @@ func (m *Manager) Execute(ctx context.Context, pluginID string, toolName string,
 
 	// Call tool (log start/end with duration and sizes)
 	reqID := time.Now().UnixNano()
-	argStr := ""
-	if len(arguments) > 0 {
-		if len(arguments) > 200 {
-			argStr = string(arguments[:200]) + "..."
-		} else {
-			argStr = string(arguments)
-		}
-	}
-	log.Printf("exec:start id=%d plugin=%s tool=%s args=%s", reqID, pluginID, toolName, argStr)
+	setCallRequestID(ctx, reqID)
+	m.logger.Info("exec:start", "reqID", reqID, "plugin", pluginID, "tool", toolName, "args", logSnippet(logging.Redact(arguments)))
 	start := time.Now()
 
-	result, err := server.session.CallTool(ctx, &mcp.CallToolParams{
+	params := &mcp.CallToolParams{
 		Name:      toolName,
 		Arguments: args,
-	})
+	}
+
+	// Apply the server's call timeout, if any
+	callCtx := ctx
+	timeout := time.Duration(cfg.Timeout) * time.Second
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	// Stream incremental output to the caller if it asked for it, and keep
+	// it if a timed out call should return what it produced
+	sink := chunkFuncFrom(ctx)
+	var partial *partialOutput
+	if timeout > 0 && cfg.OnTimeout == "partial" {
+		partial = &partialOutput{}
+		sink = partial.collect(sink)
+	}
+	if sink != nil {
+		token, release := m.streams.open(pluginID, sink)
+		defer release()
+		// SetProgressToken drops the token when Meta is nil
+		params.Meta = mcp.Meta{}
+		params.SetProgressToken(token)
+	}
+
+	result, err := server.session.CallTool(callCtx, params)
 	dur := time.Since(start)
+	timing.Call = dur
+	if err != nil && partial != nil && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+		if r, ok := partial.result(timeout); ok {
+			m.logger.Info("exec:partial", "reqID", reqID, "plugin", pluginID, "tool", toolName, "duration", dur)
+			result, err = r, nil
+			ttl = 0 // a partial result isn't worth reusing
+		}
+	}
 	if err != nil {
-		log.Printf("exec:fail id=%d plugin=%s tool=%s duration=%s err=%v", reqID, pluginID, toolName, dur, err)
-		return nil, fmt.Errorf("tool call failed: %w", err)
+		m.logger.Warn("exec:fail", "reqID", reqID, "plugin", pluginID, "tool", toolName, "duration", dur, "err", logging.RedactError(err, nil))
+		if countsAsFailure(ctx) {
+			bc.failed()
+		}
+		err = fmt.Errorf("tool call failed: %w", err)
+		if rpc := rpcError(err); rpc != nil && cfg.ForwardErrors {
+			err = &forwardedError{rpc: rpc, err: err}
+		}
+		return nil, &callError{"error", err}
+	}
+
+	bc.succeeded()
+
+	// Void tools may answer with a null result or no content, which is a
+	// successful call with nothing to show
+	if result == nil {
+		result = &mcp.CallToolResult{}
+	}
+	if result.Content == nil {
+		result.Content = []mcp.Content{}
 	}
 
 	// Marshal result for returning and for logging
+	processed := time.Now()
 	respBytes, merr := json.Marshal(result)
+	timing.Process = time.Since(processed)
 	if merr != nil {
-		log.Printf("exec:fail id=%d plugin=%s tool=%s duration=%s err=%v", reqID, pluginID, toolName, dur, merr)
+		m.logger.Warn("exec:fail", "reqID", reqID, "plugin", pluginID, "tool", toolName, "duration", dur, "err", logging.RedactError(merr, nil))
 		return nil, fmt.Errorf("failed to marshal tool result: %w", merr)
 	}
 
-	log.Printf("exec:done id=%d plugin=%s tool=%s duration=%s resultBytes=%d isError=%v", reqID, pluginID, toolName, dur, len(respBytes), result.IsError)
+	m.logger.Info("exec:done", "reqID", reqID, "plugin", pluginID, "tool", toolName, "duration", dur, "resultBytes", len(respBytes), "isError", result.IsError)
+	if cfg.LogResults {
+		m.logger.Debug("exec:result", "reqID", reqID, "plugin", pluginID, "tool", toolName, "result", logSnippet(logging.Redact(respBytes)))
+	}
 
 	if result.IsError {
-		return nil, fmt.Errorf("tool returned error")
+		return nil, &callError{"toolError", newToolError(result, respBytes)}
+	}
+
+	// Let the tool's output transform rewrite the result
+	respBytes, err = transformResult(ctx, cfg, pluginID, toolName, original, respBytes)
+	if err != nil {
+		m.logger.Warn("exec:transform-fail", "reqID", reqID, "plugin", pluginID, "tool", toolName, "stage", "output", "err", err)
+		return nil, fmt.Errorf("output transform failed: %w", err)
 	}
 
+	if ttl > 0 {
+		m.cache.put(key, respBytes, time.Now().Add(ttl))
+	}
 	return respBytes, nil
 }
 
 // StopServer stops a single MCP server
 func (m *Manager) StopServer(name string) error {
+	// A server that is reconnecting has no session to close. Cancelling
+	// before queueing also aborts a reconnect attempt holding the lock.
+	reconnecting := m.cancelReconnect(name)
+	defer m.lockServer(name)()
+	return m.stopServer(name, reconnecting)
+}
+
+func (m *Manager) stopServer(name string, reconnecting bool) error {
+	m.setFailover(name, config.ServerConfig{})
+
 	m.mu.Lock()
 	server, ok := m.servers[name]
 	if !ok {
 		m.mu.Unlock()
+		if reconnecting {
+			m.transition(name, StateStopped, "")
+			m.emit(EventStopped, name, nil)
+			return nil
+		}
 		return fmt.Errorf("server not found: %s", name)
 	}
 	delete(m.servers, name)
 	m.mu.Unlock()
 
-	// Unregister tools from registry
+	// Unregister tools, resources and prompts from registry
 	m.reg.UnregisterTools(name)
+	m.refreshVirtualTools(name)
+	m.refreshGroupTools(name)
+	m.reg.UnregisterResources(name)
+	m.reg.UnregisterPrompts(name)
 
-	// Close session
-	if err := server.session.Close(); err != nil {
+	// No new calls reach the server, close its session once the running
+	// ones have finished
+	if n := server.active.Load(); n > 0 {
+		m.logger.Info("stop:draining", "plugin", name, "inFlight", n)
+		go m.drainAndClose(server)
+	} else if err := server.session.Close(); err != nil {
 		return fmt.Errorf("failed to close server %s: %w", name, err)
 	}
 
-	log.Printf("stopped MCP server: %s", name)
+	m.logger.Info("stop", "plugin", name)
+	m.transition(name, StateStopped, "")
+	m.emit(EventStopped, name, nil)
 	return nil
 }
 
 // ReloadServer stops and restarts a server with new configuration
 func (m *Manager) ReloadServer(ctx context.Context, name string, cfg config.ServerConfig) error {
+	// A reload replaces any pending reconnect with a fresh start
+	m.cancelReconnect(name)
+	defer m.lockServer(name)()
+
 	// Stop existing server if it exists
 	if _, exists := m.GetServer(name); exists {
-		if err := m.StopServer(name); err != nil {
+		if err := m.stopServer(name, false); err != nil {
 			return fmt.Errorf("failed to stop server for reload: %w", err)
 		}
 	}
 
 	// Start with new configuration
-	return m.StartServer(ctx, name, cfg)
+	if err := m.startServer(ctx, name, cfg); err != nil {
+		return err
+	}
+
+	m.emit(EventReloaded, name, nil)
+	return nil
+}
+
+// lockServer serializes starting, stopping and reloading the named server
+// and returns the unlock function. Blocked callers are served in order.
+func (m *Manager) lockServer(name string) func() {
+	m.mu.Lock()
+	op, ok := m.ops[name]
+	if !ok {
+		op = make(chan struct{}, 1)
+		m.ops[name] = op
+	}
+	m.mu.Unlock()
+
+	op <- struct{}{}
+	return func() { <-op }
 }
 
 // StopAll stops all running servers
@@ -281,13 +822,97 @@ func (m *Manager) StopAll(ctx context.Context) {
 	for _, s := range m.servers {
 		servers = append(servers, s)
 	}
+	m.servers = make(map[string]*MCPServer)
+	reconnects := m.reconnects
+	m.reconnects = make(map[string]reconnectHandle)
 	m.mu.Unlock()
 
+	for name, h := range reconnects {
+		h.cancel()
+		m.transition(name, StateStopped, "")
+	}
+
+	// Let running calls finish until ctx ends, then close regardless
+	var wg sync.WaitGroup
 	for _, s := range servers {
-		if err := s.session.Close(); err != nil {
-			log.Printf("error closing server %s: %v", s.name, err)
-		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !s.waitIdle(ctx) {
+				m.logger.Warn("stop:drain-timeout", "plugin", s.name, "inFlight", s.active.Load())
+			}
+			if err := s.session.Close(); err != nil {
+				m.logger.Warn("stop:close-fail", "plugin", s.name, "err", err)
+			}
+			m.transition(s.name, StateStopped, "")
+			m.emit(EventStopped, s.name, nil)
+		}()
 	}
+	wg.Wait()
+}
+
+// watchSession marks a server disconnected when its session ends without
+// the manager having stopped it (e.g. a crashed stdio process), removes its
+// tools and starts reconnecting unless its restart policy is never
+func (m *Manager) watchSession(server *MCPServer) {
+	err := server.session.Wait()
+	close(server.done)
+	if server.cancel != nil {
+		server.cancel()
+	}
+
+	m.mu.Lock()
+	current, ok := m.servers[server.name]
+	if ok && current == server {
+		delete(m.servers, server.name)
+	}
+	m.mu.Unlock()
+	if !ok || current != server {
+		return
+	}
+
+	reason := "session closed"
+	if err != nil {
+		reason = err.Error()
+	}
+	m.reg.UnregisterTools(server.name)
+	m.refreshVirtualTools(server.name)
+	m.refreshGroupTools(server.name)
+	m.reg.UnregisterResources(server.name)
+	m.reg.UnregisterPrompts(server.name)
+
+	cfg := server.Config()
+	if cfg.RestartPolicy == "never" {
+		m.logger.Info("exit:no-restart", "plugin", server.name, "reason", reason)
+		m.transition(server.name, StateStopped, reason)
+		m.emit(EventStopped, server.name, map[string]string{"reason": reason})
+		return
+	}
+	m.transition(server.name, StateDisconnected, reason)
+	m.beginReconnect(server.name, cfg)
+}
+
+// SetInvalidServers records servers skipped because their configuration is
+// invalid, replacing any previous set
+func (m *Manager) SetInvalidServers(invalid map[string]error) {
+	for name, err := range invalid {
+		m.logger.Warn("config:invalid-server", "plugin", name, "err", err)
+	}
+	m.mu.Lock()
+	m.invalid = invalid
+	m.mu.Unlock()
+}
+
+// InvalidServers returns the validation error of each server skipped in
+// lenient validation mode
+func (m *Manager) InvalidServers() map[string]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[string]string, len(m.invalid))
+	for name, err := range m.invalid {
+		out[name] = err.Error()
+	}
+	return out
 }
 
 // GetServer returns server information
@@ -298,6 +923,29 @@ func (m *Manager) GetServer(name string) (*MCPServer, bool) {
 	return server, ok
 }
 
+// Config returns the configuration the server is running with
+func (s *MCPServer) Config() config.ServerConfig {
+	s.cfgMu.RLock()
+	defer s.cfgMu.RUnlock()
+	return s.cfg
+}
+
+func (s *MCPServer) setConfig(cfg config.ServerConfig) {
+	s.cfgMu.Lock()
+	s.cfg = cfg
+	s.cfgMu.Unlock()
+}
+
+// Labels returns the custom metric labels configured for the server
+func (s *MCPServer) Labels() map[string]string {
+	return s.Config().Labels
+}
+
+// Priority returns the configured priority of the server
+func (s *MCPServer) Priority() int {
+	return s.Config().Priority
+}
+
 // ListServers returns list of running servers
 func (m *Manager) ListServers() []string {
 	m.mu.Lock()
@@ -312,18 +960,152 @@ func (m *Manager) ListServers() []string {
 
 // Helper functions
 
-func envMapToSlice(m map[string]string) []string {
-	result := make([]string, 0, len(m))
-	for k, v := range m {
-		result = append(result, fmt.Sprintf("%s=%s", k, v))
+// listTools lists all tools of a session, following pagination cursors
+func listTools(ctx context.Context, session *mcp.ClientSession) ([]*mcp.Tool, error) {
+	var tools []*mcp.Tool
+	for tool, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, tool)
 	}
-	return result
+	return tools, nil
 }
 
-func buildDockerArgs(cfg config.ServerConfig) []string {
-	args := []string{"run", "--rm", "-i"}
+// permissiveSchema stands in for a missing input schema, accepting any
+// arguments object
+var permissiveSchema = map[string]any{"type": "object"}
 
-	// Add environment variables
+// toRegistryTools converts a server's listed tools for the registry
+func toRegistryTools(name string, tools []*mcp.Tool) []registry.Tool {
+	out := make([]registry.Tool, len(tools))
+	for i, tool := range tools {
+		out[i] = registry.Tool{
+			ID:          tool.Name,
+			Name:        tool.Name,
+			Description: tool.Description,
+			PluginID:    name,
+		}
+		if tool.InputSchema != nil {
+			if schema, err := json.Marshal(tool.InputSchema); err == nil {
+				out[i].InputSchema = schema
+			}
+		}
+	}
+	return out
+}
+
+// checkInputSchemas applies the missingInputSchema policy to tools listed
+// without an input schema: "object" (default) substitutes permissiveSchema,
+// "reject" drops the tool and "passthrough" keeps it as is
+func (m *Manager) checkInputSchemas(name string, tools []*mcp.Tool, policy string) []*mcp.Tool {
+	if policy == "passthrough" {
+		return tools
+	}
+	return slices.DeleteFunc(tools, func(t *mcp.Tool) bool {
+		if t.InputSchema != nil {
+			return false
+		}
+		if policy == "reject" {
+			m.logger.Warn("discover:no-schema", "plugin", name, "tool", t.Name, "action", "rejected")
+			return true
+		}
+		m.logger.Debug("discover:no-schema", "plugin", name, "tool", t.Name, "action", "substituted")
+		t.InputSchema = permissiveSchema
+		return false
+	})
+}
+
+// dedupeTools resolves tools listed more than once by a single backend
+// according to policy: "first" (default) keeps the first definition, "last"
+// the last one and "error" rejects the tool list
+func (m *Manager) dedupeTools(name string, tools []*mcp.Tool, policy string) ([]*mcp.Tool, error) {
+	index := make(map[string]int, len(tools))
+	out := make([]*mcp.Tool, 0, len(tools))
+	for _, tool := range tools {
+		i, dup := index[tool.Name]
+		if !dup {
+			index[tool.Name] = len(out)
+			out = append(out, tool)
+			continue
+		}
+
+		switch policy {
+		case "error":
+			return nil, fmt.Errorf("server %s listed tool %q more than once", name, tool.Name)
+		case "last":
+			out[i] = tool
+		}
+		m.logger.Warn("discover:duplicate-tool", "plugin", name, "tool", tool.Name, "kept", dedupeKept(policy))
+	}
+	return out, nil
+}
+
+func dedupeKept(policy string) string {
+	if policy == "last" {
+		return "last"
+	}
+	return "first"
+}
+
+// collectLateTools waits up to window for tools/list_changed notifications
+// and re-lists after each one, so backends with asynchronous discovery have
+// their complete tool set registered
+func (m *Manager) collectLateTools(ctx context.Context, name string, session *mcp.ClientSession, tools []*mcp.Tool, changed <-chan struct{}, window time.Duration) []*mcp.Tool {
+	timer := time.NewTimer(window)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-changed:
+			latest, err := listTools(ctx, session)
+			if err != nil {
+				m.logger.Warn("discover:relist-fail", "plugin", name, "err", err)
+				continue
+			}
+			tools = latest
+		case <-timer.C:
+			return tools
+		case <-ctx.Done():
+			return tools
+		}
+	}
+}
+
+// rateLimitTransport returns the transport applying the server's rate-limit
+// hint policy below the configured headers, nil to ignore hints
+func (m *Manager) rateLimitTransport(cfg config.ServerConfig) http.RoundTripper {
+	switch cfg.RateLimitHints {
+	case "ignore":
+		return nil
+	case "throttle":
+		rt := transport.NewRateLimitTransport(nil, true)
+		rt.Logger = m.logger
+		return rt
+	default:
+		rt := transport.NewRateLimitTransport(nil, false)
+		rt.Logger = m.logger
+		return rt
+	}
+}
+
+// stdioCommand builds the process of a stdio server, which gets the host
+// environment, or only PATH and envPassthrough without inheritEnv, and its
+// configured env
+func stdioCommand(cfg config.ServerConfig) *exec.Cmd {
+	cmd := exec.Command(cfg.Command, cfg.Args...)
+	cmd.Env = transport.Environ(cfg.InheritsEnv(), cfg.EnvPassthrough, cfg.Env)
+	return cmd
+}
+
+func buildDockerArgs(cfg config.ServerConfig, ctr *container) []string {
+	args := []string{"run", "--rm", "-i", "--name", ctr.name, "--cidfile", ctr.cidfile}
+
+	// Add environment variables, inherited ones by name so docker copies the
+	// host value, configured values win
+	for _, name := range transport.HostEnvNames(cfg.EnvPassthrough) {
+		args = append(args, "-e", name)
+	}
 	for k, v := range cfg.Env {
 		args = append(args, "-e", fmt.Sprintf("%s=%s", k, v))
 	}
@@ -338,6 +1120,9 @@ func buildDockerArgs(cfg config.ServerConfig) []string {
 		args = append(args, "--network", cfg.Network)
 	}
 
+	// Add resource limits
+	args = append(args, transport.LimitArgs(cfg.Memory, cfg.CPUs, cfg.PidsLimit)...)
+
 	// Add image
 	args = append(args, cfg.Image)
 
@@ -346,3 +1131,14 @@ func buildDockerArgs(cfg config.ServerConfig) []string {
 
 	return args
 }
+
+// maxLogSnippet is how much of a call's arguments or result is logged
+const maxLogSnippet = 200
+
+// logSnippet shortens a JSON payload for logging
+func logSnippet(data []byte) string {
+	if len(data) > maxLogSnippet {
+		return string(data[:maxLogSnippet]) + "..."
+	}
+	return string(data)
+}
@@ -0,0 +1,234 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// newTestManager returns a manager that doesn't log
+func newTestManager() *Manager {
+	return NewManager(registry.New(), WithLogger(testLogger))
+}
+
+// testBackend serves an MCP server over streamable HTTP, recording the
+// requests it receives. While failing is set it holds requests back, like a
+// wedged backend.
+type testBackend struct {
+	URL     string
+	failing atomic.Bool
+	handler http.Handler
+
+	mu       sync.Mutex
+	requests []*jsonrpc.Request
+	headers  []http.Header // of each request
+}
+
+// newTestBackend serves server, the echo server if nil
+func newTestBackend(t *testing.T, server *mcp.Server) *testBackend {
+	t.Helper()
+	if server == nil {
+		server = echoServer()
+	}
+	b := &testBackend{handler: mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil)}
+	srv := httptest.NewServer(b)
+	t.Cleanup(func() {
+		// The SDK may leave its event stream open after closing a session
+		srv.CloseClientConnections()
+		srv.Close()
+	})
+	b.URL = srv.URL
+	return b
+}
+
+func (b *testBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Reading the body up front lets the server notice a client giving up
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if msg, err := jsonrpc.DecodeMessage(body); err == nil {
+		if req, ok := msg.(*jsonrpc.Request); ok {
+			b.mu.Lock()
+			b.requests = append(b.requests, req)
+			b.headers = append(b.headers, r.Header.Clone())
+			b.mu.Unlock()
+		}
+	}
+	for b.failing.Load() {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	b.handler.ServeHTTP(w, r)
+}
+
+// config returns the configuration of a server connecting to b
+func (b *testBackend) config() config.ServerConfig {
+	return config.ServerConfig{Type: "http", URL: b.URL}
+}
+
+// request returns the last request of method b received, nil if none
+func (b *testBackend) request(method string) *jsonrpc.Request {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i := len(b.requests) - 1; i >= 0; i-- {
+		if b.requests[i].Method == method {
+			return b.requests[i]
+		}
+	}
+	return nil
+}
+
+// header returns the HTTP headers of the last request of method b received
+func (b *testBackend) header(method string) http.Header {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for i := len(b.requests) - 1; i >= 0; i-- {
+		if b.requests[i].Method == method {
+			return b.headers[i]
+		}
+	}
+	return nil
+}
+
+// count returns how many requests of method b received
+func (b *testBackend) count(method string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	n := 0
+	for _, req := range b.requests {
+		if req.Method == method {
+			n++
+		}
+	}
+	return n
+}
+
+// methods returns the methods of the requests b received, in order
+func (b *testBackend) methods() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var out []string
+	for _, req := range b.requests {
+		out = append(out, req.Method)
+	}
+	return out
+}
+
+// startServer starts the named server or fails the test
+func startServer(t *testing.T, m *Manager, name string, cfg config.ServerConfig) {
+	t.Helper()
+	if err := m.StartServer(context.Background(), name, cfg); err != nil {
+		t.Fatalf("start %s: %v", name, err)
+	}
+}
+
+// nextEvent returns the next event of type typ from ch, skipping others
+func nextEvent(t *testing.T, ch chan Event, typ EventType) Event {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev := <-ch:
+			if ev.Type == typ {
+				return ev
+			}
+		case <-timeout:
+			t.Fatalf("no %s event", typ)
+		}
+	}
+}
+
+func TestEventsStartStop(t *testing.T) {
+	m := newTestManager()
+	events := m.SubscribeEvents()
+	defer m.UnsubscribeEvents(events)
+
+	startServer(t, m, "echo", newTestBackend(t, nil).config())
+	ev := nextEvent(t, events, EventStarted)
+	if ev.Server != "echo" || ev.Details["transport"] != "http" || ev.Details["tools"] != "1" {
+		t.Errorf("started event = %+v", ev)
+	}
+
+	if err := m.StopServer("echo"); err != nil {
+		t.Fatal(err)
+	}
+	if ev := nextEvent(t, events, EventStopped); ev.Server != "echo" {
+		t.Errorf("stopped event for %s", ev.Server)
+	}
+
+	// Unsubscribing closes the channel
+	m.UnsubscribeEvents(events)
+	for range events {
+	}
+}
+
+func TestEventsSlowConsumer(t *testing.T) {
+	m := newTestManager()
+	events := m.SubscribeEvents()
+	defer m.UnsubscribeEvents(events)
+
+	// Nobody reads, yet emitting never blocks
+	done := make(chan struct{})
+	go func() {
+		for range 10 * eventBufferSize {
+			m.emit(EventReloaded, "echo", nil)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("emit blocked on a full subscriber")
+	}
+	if len(events) != eventBufferSize {
+		t.Errorf("%d events buffered, want %d", len(events), eventBufferSize)
+	}
+}
+
+// echoServer has a single tool "echo" returning its text argument
+func echoServer() *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "echo"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "echo"}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+		Text string `json:"text"`
+	}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: args.Text}}}, nil, nil
+	})
+	return server
+}
+
+// resultText returns the text of the first content block of a tool result
+func resultText(t *testing.T, resp []byte) string {
+	t.Helper()
+	var result struct {
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	if err := json.Unmarshal(resp, &result); err != nil {
+		t.Fatalf("decode result %s: %v", resp, err)
+	}
+	if len(result.Content) == 0 {
+		t.Fatalf("result without content: %s", resp)
+	}
+	return result.Content[0].Text
+}
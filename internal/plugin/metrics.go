@@ -0,0 +1,239 @@
+package plugin
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// unknownLabel stands in for the server and tool of calls that didn't name
+// a registered tool, so callers can't create series at will
+const unknownLabel = "unknown"
+
+// callBuckets are histogram buckets in seconds suited to tool call latencies
+var callBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+// managerMetrics are the Prometheus metrics kept by the manager, next to the
+// OpenTelemetry instruments
+type managerMetrics struct {
+	registry   *prometheus.Registry
+	calls      *labeledSeries[prometheus.Counter]
+	duration   *labeledSeries[prometheus.Histogram]
+	rejections *prometheus.CounterVec
+	throttled  *prometheus.CounterVec
+	cacheHits  *prometheus.CounterVec
+}
+
+func newManagerMetrics(m *Manager) managerMetrics {
+	mm := managerMetrics{
+		registry: prometheus.NewRegistry(),
+		calls: newLabeledSeries(func(labels prometheus.Labels) prometheus.Counter {
+			return prometheus.NewCounter(prometheus.CounterOpts{
+				Name:        "mcp_hub_tool_calls_total",
+				Help:        "Tool calls forwarded to MCP servers",
+				ConstLabels: labels,
+			})
+		}),
+		duration: newLabeledSeries(func(labels prometheus.Labels) prometheus.Histogram {
+			return prometheus.NewHistogram(prometheus.HistogramOpts{
+				Name:        "mcp_hub_tool_call_duration_seconds",
+				Help:        "Duration of tool calls forwarded to MCP servers",
+				Buckets:     callBuckets,
+				ConstLabels: labels,
+			})
+		}),
+		rejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mcp_hub_server_queue_rejections_total",
+			Help: "Tool calls rejected because the server's queue was full",
+		}, []string{"plugin"}),
+		throttled: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mcp_hub_rate_limited_calls_total",
+			Help: "Tool calls rejected because the client exceeded its rate limit",
+		}, []string{"client", "plugin", "tool"}),
+		cacheHits: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "mcp_hub_tool_cache_hits_total",
+			Help: "Tool calls answered from the result cache",
+		}, []string{"plugin", "tool"}),
+	}
+	mm.registry.MustRegister(
+		mm.calls,
+		mm.duration,
+		mm.rejections,
+		mm.throttled,
+		mm.cacheHits,
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "mcp_hub_connected_servers",
+			Help: "MCP servers currently connected",
+		}, func() float64 {
+			return float64(m.connectedCount())
+		}),
+		&loadCollector{m: m},
+		&stateCollector{m: m},
+	)
+	return mm
+}
+
+// Metrics returns the manager's Prometheus registry, served over HTTP with
+// promhttp
+func (m *Manager) Metrics() *prometheus.Registry {
+	return m.metrics.registry
+}
+
+// metricNames returns the server and tool a call is counted under: its own
+// if it named a registered tool, unknownLabel otherwise
+func (m *Manager) metricNames(pluginID, toolName string) (string, string) {
+	if _, ok := m.reg.Tool(pluginID, toolName); !ok {
+		return unknownLabel, unknownLabel
+	}
+	return pluginID, toolName
+}
+
+// recordCall counts a finished tool call, labeled with the server's custom
+// labels
+func (m *Manager) recordCall(pluginID, toolName string, dur time.Duration, err error) {
+	labels := prometheus.Labels{}
+	if s, ok := m.GetServer(pluginID); ok {
+		maps.Copy(labels, s.Labels())
+	}
+	labels["plugin"] = pluginID
+	labels["tool"] = toolName
+	m.metrics.duration.with(labels).Observe(dur.Seconds())
+
+	labels["status"] = "ok"
+	if err != nil {
+		labels["status"] = "error"
+	}
+	m.metrics.calls.with(labels).Inc()
+}
+
+// rejectedCalls returns how many calls a full queue of the server rejected
+func (m *Manager) rejectedCalls(name string) int {
+	var out dto.Metric
+	if err := m.metrics.rejections.WithLabelValues(name).Write(&out); err != nil {
+		return 0
+	}
+	return int(out.GetCounter().GetValue())
+}
+
+// labeledSeries is a metric family whose series carry the custom labels of
+// their server besides the fixed ones. Servers differ in their label names,
+// which a vector can't express, so every label set gets its own metric.
+type labeledSeries[M prometheus.Metric] struct {
+	newMetric func(prometheus.Labels) M
+	mu        sync.Mutex
+	series    map[string]M
+}
+
+func newLabeledSeries[M prometheus.Metric](newMetric func(prometheus.Labels) M) *labeledSeries[M] {
+	return &labeledSeries[M]{newMetric: newMetric, series: make(map[string]M)}
+}
+
+// with returns the series of labels, creating it on first use
+func (s *labeledSeries[M]) with(labels prometheus.Labels) M {
+	names := slices.Sorted(maps.Keys(labels))
+	var key strings.Builder
+	for _, name := range names {
+		key.WriteString(name)
+		key.WriteByte(0)
+		key.WriteString(labels[name])
+		key.WriteByte(0)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	metric, ok := s.series[key.String()]
+	if !ok {
+		metric = s.newMetric(maps.Clone(labels))
+		s.series[key.String()] = metric
+	}
+	return metric
+}
+
+// Describe sends nothing, which makes the family an unchecked collector
+// whose series may differ in their label names
+func (s *labeledSeries[M]) Describe(chan<- *prometheus.Desc) {}
+
+func (s *labeledSeries[M]) Collect(ch chan<- prometheus.Metric) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, metric := range s.series {
+		ch <- metric
+	}
+}
+
+var (
+	inflightDesc = prometheus.NewDesc("mcp_hub_server_inflight_calls",
+		"Tool calls currently running on each server", []string{"plugin"}, nil)
+	queuedDesc = prometheus.NewDesc("mcp_hub_server_queued_calls",
+		"Tool calls waiting for a busy server", []string{"plugin"}, nil)
+	saturationDesc = prometheus.NewDesc("mcp_hub_server_saturation",
+		"Running calls divided by the concurrency limit, for servers with a limit", []string{"plugin"}, nil)
+)
+
+// loadCollector reports the load of every running server at scrape time
+type loadCollector struct {
+	m *Manager
+}
+
+func (c *loadCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- inflightDesc
+	ch <- queuedDesc
+	ch <- saturationDesc
+}
+
+func (c *loadCollector) Collect(ch chan<- prometheus.Metric) {
+	c.m.mu.Lock()
+	defer c.m.mu.Unlock()
+	for name, s := range c.m.servers {
+		l := s.gate.load()
+		ch <- prometheus.MustNewConstMetric(inflightDesc, prometheus.GaugeValue, float64(l.running), name)
+		ch <- prometheus.MustNewConstMetric(queuedDesc, prometheus.GaugeValue, float64(l.queued), name)
+		if l.limit > 0 {
+			ch <- prometheus.MustNewConstMetric(saturationDesc, prometheus.GaugeValue, l.saturation(), name)
+		}
+	}
+}
+
+var stateDesc = prometheus.NewDesc("mcp_hub_server_state",
+	"Connection state of each server, 1 for the current state and 0 for the others", []string{"plugin", "state"}, nil)
+
+// stateCollector reports the connection state of every server the manager
+// knows of, one series per state so transitions show as steps
+type stateCollector struct {
+	m *Manager
+}
+
+func (c *stateCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- stateDesc
+}
+
+func (c *stateCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, current := range c.m.States() {
+		for _, st := range AllStates {
+			v := 0.0
+			if st == current {
+				v = 1
+			}
+			ch <- prometheus.MustNewConstMetric(stateDesc, prometheus.GaugeValue, v, name, string(st))
+		}
+	}
+}
+
+// connectedCount returns how many servers are connected, degraded ones
+// included since they still serve calls
+func (m *Manager) connectedCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := 0
+	for _, st := range m.states {
+		if st == StateConnected || st == StateDegraded {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,155 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestRecordCallCollapsesUnknownTools(t *testing.T) {
+	reg := registry.New()
+	reg.RegisterTools("github", []registry.Tool{{ID: "search", Name: "search"}})
+	m := NewManager(reg)
+	ctx := context.Background()
+
+	for i := range 50 {
+		m.Execute(ctx, fmt.Sprintf("server-%d", i), "tool", nil)
+		m.Execute(ctx, "github", fmt.Sprintf("tool-%d", i), nil)
+	}
+	// Not connected, so the call fails but is counted under its name
+	m.Execute(ctx, "github", "search", nil)
+
+	want := `
+# HELP mcp_hub_tool_calls_total Tool calls forwarded to MCP servers
+# TYPE mcp_hub_tool_calls_total counter
+mcp_hub_tool_calls_total{plugin="github",status="error",tool="search"} 1
+mcp_hub_tool_calls_total{plugin="unknown",status="error",tool="unknown"} 100
+`
+	if err := testutil.GatherAndCompare(m.Metrics(), strings.NewReader(want), "mcp_hub_tool_calls_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLabeledSeriesMixedLabelNames(t *testing.T) {
+	calls := newLabeledSeries(func(labels prometheus.Labels) prometheus.Counter {
+		return prometheus.NewCounter(prometheus.CounterOpts{Name: "calls_total", Help: "Calls", ConstLabels: labels})
+	})
+	r := prometheus.NewRegistry()
+	r.MustRegister(calls)
+
+	calls.with(prometheus.Labels{"plugin": "a", "team": "search"}).Inc()
+	calls.with(prometheus.Labels{"plugin": "a", "team": "search"}).Inc()
+	calls.with(prometheus.Labels{"plugin": "b"}).Inc()
+
+	want := `
+# HELP calls_total Calls
+# TYPE calls_total counter
+calls_total{plugin="a",team="search"} 2
+calls_total{plugin="b"} 1
+`
+	if err := testutil.GatherAndCompare(r, strings.NewReader(want)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRejectedCalls(t *testing.T) {
+	m := NewManager(registry.New())
+	m.metrics.rejections.WithLabelValues("github").Inc()
+	m.metrics.rejections.WithLabelValues("github").Inc()
+	if got := m.rejectedCalls("github"); got != 2 {
+		t.Errorf("rejectedCalls(github) = %d, want 2", got)
+	}
+	if got := m.rejectedCalls("slack"); got != 0 {
+		t.Errorf("rejectedCalls(slack) = %d, want 0", got)
+	}
+}
+
+func TestRecordCallServerLabels(t *testing.T) {
+	m := newTestManager()
+	labeled := newTestBackend(t, nil).config()
+	labeled.Labels = map[string]string{"team": "search", "env": "prod"}
+	startServer(t, m, "labeled", labeled)
+	startServer(t, m, "plain", newTestBackend(t, nil).config())
+
+	ctx := context.Background()
+	for _, name := range []string{"labeled", "plain"} {
+		if _, err := m.Execute(ctx, name, "echo", []byte(`{"text":"hi"}`)); err != nil {
+			t.Fatalf("call %s: %v", name, err)
+		}
+	}
+
+	want := `
+# HELP mcp_hub_tool_calls_total Tool calls forwarded to MCP servers
+# TYPE mcp_hub_tool_calls_total counter
+mcp_hub_tool_calls_total{env="prod",plugin="labeled",status="ok",team="search",tool="echo"} 1
+mcp_hub_tool_calls_total{plugin="plain",status="ok",tool="echo"} 1
+`
+	if err := testutil.GatherAndCompare(m.Metrics(), strings.NewReader(want), "mcp_hub_tool_calls_total"); err != nil {
+		t.Error(err)
+	}
+	if n := testutil.CollectAndCount(m.metrics.duration, "mcp_hub_tool_call_duration_seconds"); n != 2 {
+		t.Errorf("%d duration series, want 2", n)
+	}
+}
+
+func TestSaturationMetrics(t *testing.T) {
+	m := newTestManager()
+	release := make(chan struct{})
+	cfg := newTestBackend(t, blockingServer(release)).config()
+	cfg.MaxConcurrency = 2
+	cfg.MaxQueue = 1
+	startServer(t, m, "slow", cfg)
+	t.Cleanup(func() { m.StopServer("slow") })
+
+	// Two calls run and one waits, filling the queue
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Execute(ctx, "slow", "block", json.RawMessage(`{}`))
+		}()
+	}
+	status := func() ServerStatus { return m.ServerStatuses()[0] }
+	waitFor(t, func() bool { st := status(); return st.InFlight == 2 && st.Queued == 1 })
+
+	if _, err := m.Execute(ctx, "slow", "block", json.RawMessage(`{}`)); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("call over the queue: err = %v", err)
+	}
+	if st := status(); st.Limit != 2 || st.Saturation != 1 || st.Rejected != 1 {
+		t.Errorf("status = %+v, want limit 2, saturation 1 and one rejection", st)
+	}
+	want := `
+# HELP mcp_hub_server_inflight_calls Tool calls currently running on each server
+# TYPE mcp_hub_server_inflight_calls gauge
+mcp_hub_server_inflight_calls{plugin="slow"} 2
+# HELP mcp_hub_server_queue_rejections_total Tool calls rejected because the server's queue was full
+# TYPE mcp_hub_server_queue_rejections_total counter
+mcp_hub_server_queue_rejections_total{plugin="slow"} 1
+# HELP mcp_hub_server_queued_calls Tool calls waiting for a busy server
+# TYPE mcp_hub_server_queued_calls gauge
+mcp_hub_server_queued_calls{plugin="slow"} 1
+# HELP mcp_hub_server_saturation Running calls divided by the concurrency limit, for servers with a limit
+# TYPE mcp_hub_server_saturation gauge
+mcp_hub_server_saturation{plugin="slow"} 1
+`
+	names := []string{"mcp_hub_server_inflight_calls", "mcp_hub_server_queued_calls", "mcp_hub_server_saturation", "mcp_hub_server_queue_rejections_total"}
+	if err := testutil.GatherAndCompare(m.Metrics(), strings.NewReader(want), names...); err != nil {
+		t.Error(err)
+	}
+
+	close(release)
+	wg.Wait()
+	if st := status(); st.InFlight != 0 || st.Queued != 0 || st.Saturation != 0 {
+		t.Errorf("status after the calls = %+v", st)
+	}
+}
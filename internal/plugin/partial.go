@@ -0,0 +1,49 @@
+package plugin
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// partialOutput collects the output a backend streamed for a call, so a call
+// that times out can still return what it produced
+type partialOutput struct {
+	mu       sync.Mutex
+	messages []string
+}
+
+// collect returns a ChunkFunc recording chunks before passing them on to
+// next, if any
+func (p *partialOutput) collect(next ChunkFunc) ChunkFunc {
+	return func(c Chunk) {
+		if c.Message != "" {
+			p.mu.Lock()
+			p.messages = append(p.messages, c.Message)
+			p.mu.Unlock()
+		}
+		if next != nil {
+			next(c)
+		}
+	}
+}
+
+// result builds a tool result from the collected output, marked as cut off
+// by the timeout. It reports false if nothing was collected.
+func (p *partialOutput) result(timeout time.Duration) (*mcp.CallToolResult, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.messages) == 0 {
+		return nil, false
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: strings.Join(p.messages, "\n")},
+			&mcp.TextContent{Text: fmt.Sprintf("[timed out after %s, result is partial]", timeout)},
+		},
+		Meta: mcp.Meta{"mcp-hub/partial": true},
+	}, true
+}
@@ -0,0 +1,57 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// listPrompts fetches the prompts of a backend that supports them. A failure
+// only costs the prompts, the server's tools still work.
+func (m *Manager) listPrompts(ctx context.Context, name string, session *mcp.ClientSession) []registry.Prompt {
+	if init := session.InitializeResult(); init == nil || init.Capabilities == nil || init.Capabilities.Prompts == nil {
+		return nil
+	}
+	var prompts []registry.Prompt
+	for prompt, err := range session.Prompts(ctx, nil) {
+		if err != nil {
+			m.logger.Warn("prompts:list-fail", "plugin", name, "err", err)
+			return nil
+		}
+		p := registry.Prompt{
+			Name:        prompt.Name,
+			Description: prompt.Description,
+			PluginID:    name,
+		}
+		for _, arg := range prompt.Arguments {
+			p.Arguments = append(p.Arguments, registry.PromptArgument{
+				Name:        arg.Name,
+				Description: arg.Description,
+				Required:    arg.Required,
+			})
+		}
+		prompts = append(prompts, p)
+	}
+	return prompts
+}
+
+// GetPrompt renders a prompt on the server owning it, subject to the calling
+// client's access policy like tool calls
+func (m *Manager) GetPrompt(ctx context.Context, pluginID, name string, arguments map[string]string) (*mcp.GetPromptResult, error) {
+	if id := identityFrom(ctx); id != nil && !id.Allows(pluginID, name) {
+		m.logger.Warn("prompt:deny", "client", id.Name, "plugin", pluginID, "prompt", name)
+		return nil, fmt.Errorf("%w: client %s may not get prompt %s:%s", ErrForbidden, id.Name, pluginID, name)
+	}
+
+	server, ok := m.GetServer(pluginID)
+	if !ok {
+		return nil, fmt.Errorf("server not found: %s", pluginID)
+	}
+	res, err := server.session.GetPrompt(ctx, &mcp.GetPromptParams{Name: name, Arguments: arguments})
+	if err != nil {
+		return nil, fmt.Errorf("get prompt failed: %w", err)
+	}
+	return res, nil
+}
@@ -0,0 +1,145 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+)
+
+// ErrRateLimited is returned for calls over the client's rate limit
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// rateLimitError is a call rejected by the rate limiter
+type rateLimitError struct {
+	name string // "<plugin>:<tool>"
+	wait time.Duration
+}
+
+func (e *rateLimitError) Error() string {
+	return fmt.Sprintf("%v: %s, retry in %s", ErrRateLimited, e.name, e.wait.Round(time.Millisecond))
+}
+
+func (e *rateLimitError) Unwrap() error { return ErrRateLimited }
+
+// RetryAfter returns how long a rate limited call should wait before it is
+// retried
+func RetryAfter(err error) (time.Duration, bool) {
+	var re *rateLimitError
+	if errors.As(err, &re) {
+		return re.wait, true
+	}
+	return 0, false
+}
+
+// bucketSweepInterval is how often buckets that filled up again are dropped
+const bucketSweepInterval = time.Minute
+
+// rateLimiter keeps a token bucket per client and tool. Calls over the limit
+// are rejected rather than queued, so the lock is only held to count.
+type rateLimiter struct {
+	cfg       *config.RateLimitConfig
+	mu        sync.Mutex
+	buckets   map[bucketKey]*bucket
+	lastSweep time.Time
+}
+
+type bucketKey struct {
+	client, pluginID, toolName string
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+	full   time.Time // when the bucket has refilled, a new one is the same
+}
+
+func newRateLimiter(cfg *config.RateLimitConfig) *rateLimiter {
+	return &rateLimiter{cfg: cfg, buckets: make(map[bucketKey]*bucket)}
+}
+
+// take spends a token of the client's bucket for the tool, or returns how
+// long until one is available
+func (l *rateLimiter) take(client, pluginID, toolName string, now time.Time) (time.Duration, bool) {
+	limit := l.cfg.For(pluginID, toolName)
+	if limit.RPS <= 0 {
+		return 0, true
+	}
+	burst := float64(limit.Burst)
+	if burst == 0 {
+		burst = math.Max(1, math.Ceil(limit.RPS))
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweepLocked(now)
+	key := bucketKey{client, pluginID, toolName}
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*limit.RPS)
+	b.last = now
+	ok = b.tokens >= 1
+	if ok {
+		b.tokens--
+	}
+	b.full = now.Add(time.Duration((burst - b.tokens) / limit.RPS * float64(time.Second)))
+	if ok {
+		return 0, true
+	}
+	return time.Duration((1 - b.tokens) / limit.RPS * float64(time.Second)), false
+}
+
+// sweepLocked drops the buckets that refilled since they were last used, so
+// clients that stopped calling don't keep theirs
+func (l *rateLimiter) sweepLocked(now time.Time) {
+	if now.Sub(l.lastSweep) < bucketSweepInterval {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if !now.Before(b.full) {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// SetRateLimit sets the per client and tool call limits, nil for none.
+// Buckets start full again.
+func (m *Manager) SetRateLimit(cfg *config.RateLimitConfig) {
+	if cfg == nil {
+		m.limiter.Store(nil)
+		return
+	}
+	m.limiter.Store(newRateLimiter(cfg))
+}
+
+// checkRateLimit takes a token for the calling client, unauthenticated
+// callers share the buckets of client "". Calls of tools that aren't
+// registered fail anyway, they get no bucket.
+func (m *Manager) checkRateLimit(ctx context.Context, pluginID, toolName string) error {
+	l := m.limiter.Load()
+	if l == nil {
+		return nil
+	}
+	if _, ok := m.reg.Tool(pluginID, toolName); !ok {
+		return nil
+	}
+	client := ""
+	if id := identityFrom(ctx); id != nil {
+		client = id.Name
+	}
+	wait, ok := l.take(client, pluginID, toolName, time.Now())
+	if ok {
+		return nil
+	}
+	m.logger.Warn("exec:throttle", "client", client, "plugin", pluginID, "tool", toolName, "retryAfter", wait)
+	m.metrics.throttled.WithLabelValues(client, pluginID, toolName).Inc()
+	return &rateLimitError{name: pluginID + ":" + toolName, wait: wait}
+}
@@ -0,0 +1,102 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+)
+
+func TestRateLimiterTake(t *testing.T) {
+	l := newRateLimiter(&config.RateLimitConfig{
+		RateLimit: config.RateLimit{RPS: 2, Burst: 3},
+		Tools:     map[string]config.RateLimit{"slow:*": {RPS: 0.5}},
+	})
+	now := time.Unix(1000, 0)
+
+	for i := range 3 {
+		if _, ok := l.take("alice", "fast", "search", now); !ok {
+			t.Fatalf("call %d within the burst rejected", i+1)
+		}
+	}
+	wait, ok := l.take("alice", "fast", "search", now)
+	if ok {
+		t.Fatal("call over the burst admitted")
+	}
+	if wait != 500*time.Millisecond {
+		t.Errorf("wait = %s, want 500ms", wait)
+	}
+
+	// Other clients and tools have their own buckets
+	if _, ok := l.take("bob", "fast", "search", now); !ok {
+		t.Error("other client rejected")
+	}
+	if _, ok := l.take("alice", "fast", "fetch", now); !ok {
+		t.Error("other tool rejected")
+	}
+
+	// One token refills every 1/rps
+	if _, ok := l.take("alice", "fast", "search", now.Add(500*time.Millisecond)); !ok {
+		t.Error("call after refill rejected")
+	}
+	if _, ok := l.take("alice", "fast", "search", now.Add(500*time.Millisecond)); ok {
+		t.Error("second call after a single refill admitted")
+	}
+
+	// Overrides without a burst allow ceil(rps) calls at once, at least one
+	if _, ok := l.take("alice", "slow", "scan", now); !ok {
+		t.Error("first call of the override rejected")
+	}
+	if wait, ok := l.take("alice", "slow", "scan", now); ok || wait != 2*time.Second {
+		t.Errorf("second call of the override: ok %v, wait %s", ok, wait)
+	}
+}
+
+func TestRateLimiterSweepsIdleBuckets(t *testing.T) {
+	l := newRateLimiter(&config.RateLimitConfig{RateLimit: config.RateLimit{RPS: 1, Burst: 2}})
+	now := time.Unix(1000, 0)
+	l.take("alice", "a", "t", now)
+	l.take("bob", "a", "t", now)
+	l.take("bob", "a", "t", now)
+
+	// alice's bucket is full again after a second, bob's after two
+	l.take("carol", "a", "t", now.Add(bucketSweepInterval))
+	if len(l.buckets) != 1 {
+		t.Fatalf("%d buckets after the sweep, want carol's only", len(l.buckets))
+	}
+	if _, ok := l.buckets[bucketKey{"carol", "a", "t"}]; !ok {
+		t.Error("bucket in use swept")
+	}
+}
+
+func TestCheckRateLimitUnknownTools(t *testing.T) {
+	reg := registry.New()
+	reg.RegisterTools("github", []registry.Tool{{ID: "search", Name: "search"}})
+	m := NewManager(reg)
+	m.SetRateLimit(&config.RateLimitConfig{RateLimit: config.RateLimit{RPS: 1}})
+	ctx := context.Background()
+
+	for i := range 100 {
+		if err := m.checkRateLimit(ctx, "github", fmt.Sprintf("unknown-%d", i)); err != nil {
+			t.Fatalf("unknown tool limited: %v", err)
+		}
+	}
+	if n := len(m.limiter.Load().buckets); n != 0 {
+		t.Errorf("%d buckets for unknown tools", n)
+	}
+
+	if err := m.checkRateLimit(ctx, "github", "search"); err != nil {
+		t.Fatalf("first call limited: %v", err)
+	}
+	err := m.checkRateLimit(ctx, "github", "search")
+	if !errors.Is(err, ErrRateLimited) {
+		t.Fatalf("second call: %v, want ErrRateLimited", err)
+	}
+	if wait, ok := RetryAfter(err); !ok || wait <= 0 {
+		t.Errorf("RetryAfter = %s, %v", wait, ok)
+	}
+}
@@ -0,0 +1,135 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/logging"
+)
+
+const (
+	reconnectBaseDelay = time.Second
+	reconnectMaxDelay  = time.Minute
+)
+
+// EventGaveUp is emitted when a server kept failing for longer than its
+// giveUpAfter threshold and was removed from the active set
+const EventGaveUp EventType = "gave_up"
+
+// reconnect restarts a server whose session ended unexpectedly, backing off
+// between attempts. If cfg.GiveUpAfter is set and the server has been failing
+// continuously for that long, or cfg.MaxRestarts attempts failed, the hub
+// gives up until the next reload.
+func (m *Manager) reconnect(ctx context.Context, name string, cfg config.ServerConfig) {
+	defer m.endReconnect(name, ctx)
+
+	giveUp := time.Duration(cfg.GiveUpAfter) * time.Second
+	failingSince := time.Now()
+	delay, maxDelay := restartDelays(cfg)
+
+	for attempt := 1; ; attempt++ {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+
+		// Started meanwhile, e.g. by a config reload
+		if _, ok := m.GetServer(name); ok {
+			return
+		}
+
+		m.transition(name, StateReconnecting, fmt.Sprintf("attempt %d", attempt))
+		m.emit(EventReconnecting, name, map[string]string{"attempt": fmt.Sprintf("%d", attempt)})
+
+		err := m.StartServer(ctx, name, cfg)
+		if err == nil {
+			m.logger.Info("reconnect:ok", "plugin", name, "attempt", attempt)
+			return
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		failing := time.Since(failingSince)
+		if (giveUp > 0 && failing >= giveUp) || (cfg.MaxRestarts > 0 && attempt >= cfg.MaxRestarts) {
+			m.logger.Error("reconnect:give-up", "plugin", name, "attempts", attempt, "failing", failing.Round(time.Second), "err", logging.RedactError(err, nil))
+			m.mu.Lock()
+			m.gaveUp[name] = true
+			m.mu.Unlock()
+			m.transition(name, StateStopped, "gave up")
+			m.emit(EventGaveUp, name, map[string]string{
+				"attempts": fmt.Sprintf("%d", attempt),
+				"failing":  failing.Round(time.Second).String(),
+				"error":    err.Error(),
+			})
+			return
+		}
+
+		delay = min(delay*2, maxDelay)
+		m.logger.Warn("reconnect:fail", "plugin", name, "attempt", attempt, "next", delay, "err", logging.RedactError(err, nil))
+	}
+}
+
+// GaveUp reports whether the hub stopped reconnecting the named server, and
+// hasn't started it since
+func (m *Manager) GaveUp(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.gaveUp[name]
+}
+
+// restartDelays returns the first and the largest delay between restart
+// attempts for cfg
+func restartDelays(cfg config.ServerConfig) (base, limit time.Duration) {
+	base, limit = reconnectBaseDelay, reconnectMaxDelay
+	if cfg.RestartBaseDelay > 0 {
+		base = time.Duration(cfg.RestartBaseDelay) * time.Second
+	}
+	if cfg.RestartMaxDelay > 0 {
+		limit = time.Duration(cfg.RestartMaxDelay) * time.Second
+	}
+	return base, limit
+}
+
+// beginReconnect starts reconnecting the named server unless it already is
+func (m *Manager) beginReconnect(name string, cfg config.ServerConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.reconnects[name]; ok {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.reconnects[name] = reconnectHandle{ctx: ctx, cancel: cancel}
+	go m.reconnect(ctx, name, cfg)
+}
+
+// endReconnect forgets the reconnect loop owning ctx
+func (m *Manager) endReconnect(name string, ctx context.Context) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if h, ok := m.reconnects[name]; ok && h.ctx == ctx {
+		delete(m.reconnects, name)
+	}
+}
+
+// cancelReconnect stops a pending reconnect loop for the named server and
+// reports whether there was one
+func (m *Manager) cancelReconnect(name string) bool {
+	m.mu.Lock()
+	h, ok := m.reconnects[name]
+	delete(m.reconnects, name)
+	m.mu.Unlock()
+	if ok {
+		h.cancel()
+	}
+	return ok
+}
+
+// reconnectHandle identifies a running reconnect loop
+type reconnectHandle struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+}
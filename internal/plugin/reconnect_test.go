@@ -0,0 +1,53 @@
+package plugin
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestReconnectGivesUp(t *testing.T) {
+	m := newTestManager()
+	events := m.SubscribeEvents()
+	defer m.UnsubscribeEvents(events)
+
+	server := echoServer()
+	backend := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil))
+	cfg := config.ServerConfig{Type: "http", URL: backend.URL, RestartBaseDelay: 1, GiveUpAfter: 1}
+	startServer(t, m, "flaky", cfg)
+
+	// The backend goes away for good and the session ends
+	backend.CloseClientConnections()
+	backend.Close()
+	s, _ := m.GetServer("flaky")
+	s.session.Close()
+
+	nextEvent(t, events, EventReconnecting)
+	ev := nextEvent(t, events, EventGaveUp)
+	if ev.Server != "flaky" || ev.Details["attempts"] != "1" || ev.Details["error"] == "" {
+		t.Errorf("gave up event = %+v", ev)
+	}
+	if st := m.State("flaky"); st != StateStopped || !m.GaveUp("flaky") {
+		t.Errorf("state %s, gave up %v, want %s after giving up", st, m.GaveUp("flaky"), StateStopped)
+	}
+	if _, ok := m.GetServer("flaky"); ok {
+		t.Error("server still active")
+	}
+	if _, ok := m.reg.Tool("flaky", "echo"); ok {
+		t.Error("tools still registered")
+	}
+
+	// A reload starts it again once the backend is back
+	cfg.URL = newTestBackend(t, nil).URL
+	startServer(t, m, "flaky", cfg)
+	t.Cleanup(func() { m.StopServer("flaky") })
+	if _, ok := m.reg.Tool("flaky", "echo"); !ok {
+		t.Error("tools not registered after restart")
+	}
+	if m.GaveUp("flaky") {
+		t.Error("restarted server still reported as given up on")
+	}
+}
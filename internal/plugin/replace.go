@@ -0,0 +1,174 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+)
+
+// drainTimeout bounds how long a replaced or stopped connection
+// waits for its in-flight calls before it is closed anyway
+const drainTimeout = time.Minute
+
+// ReplaceServers moves the hub to a new set of servers without dropping
+// calls. The servers in start connect alongside the running ones, then the
+// tools of all of them and the removal of those in stop are published as
+// one change. Replaced connections finish their in-flight calls before they
+// close. If any server fails to connect, nothing changes.
+func (m *Manager) ReplaceServers(ctx context.Context, start map[string]config.ServerConfig, stop []string) error {
+	names := make([]string, 0, len(start))
+	for name := range start {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	// Lock in name order, so concurrent replacements can't deadlock
+	locked := slices.Concat(names, stop)
+	slices.Sort(locked)
+	locked = slices.Compact(locked)
+	for _, name := range locked {
+		defer m.lockServer(name)()
+	}
+	if err := m.checkReplaceLimit(names, stop); err != nil {
+		return err
+	}
+
+	// Bring up the new set next to the old one
+	dialed := make([]*dialedServer, len(names))
+	errs := make([]error, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cfg := start[name]
+			err := m.retryStart(ctx, name, cfg, func() (err error) {
+				dialed[i], err = m.dialServer(ctx, name, cfg)
+				return err
+			})
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", name, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
+		for _, d := range dialed {
+			if d != nil {
+				d.server.session.Close()
+				d.server.cancel()
+			}
+		}
+		m.logger.Warn("replace:abort", "servers", len(names), "err", err)
+		return err
+	}
+
+	// A pending reconnect would restart a server with its old config
+	for _, name := range locked {
+		m.cancelReconnect(name)
+	}
+
+	// Swap to the new set, subscribers see it in one snapshot
+	var old []*MCPServer
+	m.reg.Hold()
+	for _, name := range stop {
+		m.setFailover(name, config.ServerConfig{})
+		m.mu.Lock()
+		server, ok := m.servers[name]
+		delete(m.servers, name)
+		m.mu.Unlock()
+
+		m.reg.UnregisterTools(name)
+		m.refreshVirtualTools(name)
+		m.refreshGroupTools(name)
+		m.reg.UnregisterResources(name)
+		m.reg.UnregisterPrompts(name)
+		if ok {
+			old = append(old, server)
+		}
+		m.logger.Info("stop", "plugin", name)
+		m.transition(name, StateStopped, "")
+		m.emit(EventStopped, name, nil)
+	}
+	for i, name := range names {
+		m.mu.Lock()
+		server, replaced := m.servers[name]
+		m.mu.Unlock()
+
+		m.setFailover(name, start[name])
+		m.reg.UnregisterTools(name)
+		m.activateServer(dialed[i])
+		if replaced {
+			old = append(old, server)
+			m.emit(EventReloaded, name, nil)
+		}
+	}
+	m.reg.Release()
+	m.logger.Info("replace:ok", "started", len(names), "stopped", len(stop), "draining", len(old))
+
+	// The old connections are no longer reachable, close each once idle
+	for _, server := range old {
+		go m.drainAndClose(server)
+	}
+	return nil
+}
+
+// checkReplaceLimit reports whether the servers running after a replacement
+// fit under maxServers
+func (m *Manager) checkReplaceLimit(start, stop []string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.maxServers == 0 {
+		return nil
+	}
+	running := len(m.servers)
+	for _, name := range start {
+		if _, ok := m.servers[name]; !ok {
+			running++
+		}
+	}
+	for _, name := range stop {
+		if _, ok := m.servers[name]; ok {
+			running--
+		}
+	}
+	if running > m.maxServers {
+		return fmt.Errorf("%w: %d servers after the replacement, maxServers is %d", ErrServerLimit, running, m.maxServers)
+	}
+	return nil
+}
+
+// drainAndClose closes a server's session once the calls dispatched to it
+// have finished, or after drainTimeout
+func (m *Manager) drainAndClose(server *MCPServer) {
+	ctx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+	defer cancel()
+	if !server.waitIdle(ctx) {
+		m.logger.Warn("drain:timeout", "plugin", server.name, "inFlight", server.active.Load())
+	}
+	if err := server.session.Close(); err != nil {
+		m.logger.Warn("stop:close-fail", "plugin", server.name, "err", err)
+	}
+	m.logger.Info("drain:closed", "plugin", server.name)
+}
+
+// waitIdle waits until the calls dispatched to the server have finished,
+// reporting false if ctx ends first
+func (s *MCPServer) waitIdle(ctx context.Context) bool {
+	idle := make(chan struct{})
+	go func() {
+		s.calls.Wait()
+		close(idle)
+	}()
+	select {
+	case <-idle:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
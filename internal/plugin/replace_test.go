@@ -0,0 +1,113 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// versionServer has a tool "version" taking 20ms to answer with version
+func versionServer(version string) *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "version"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "version"}, func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+		time.Sleep(20 * time.Millisecond)
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: version}}}, nil, nil
+	})
+	return server
+}
+
+func TestReplaceServersUnderLoad(t *testing.T) {
+	m := newTestManager()
+	for _, name := range []string{"a", "b", "c"} {
+		startServer(t, m, name, newTestBackend(t, versionServer("v1")).config())
+	}
+	t.Cleanup(func() { m.StopAll(context.Background()) })
+
+	// Every published tool list has a and b
+	snapshots := m.reg.Subscribe()
+	var gaps []string
+	watched := make(chan struct{})
+	go func() {
+		defer close(watched)
+		for snapshot := range snapshots {
+			has := func(name string) bool {
+				return slices.ContainsFunc(snapshot, func(tool registry.Tool) bool { return tool.PluginID == name })
+			}
+			if !has("a") || !has("b") {
+				gaps = append(gaps, fmt.Sprintf("%d tools", len(snapshot)))
+			}
+		}
+	}()
+
+	// Callers keep both servers busy throughout the reload
+	ctx := context.Background()
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	var calls, failures atomic.Int32
+	var v2 sync.Map
+	for _, name := range []string{"a", "b", "a", "b"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				resp, err := m.Execute(ctx, name, "version", json.RawMessage(`{}`))
+				calls.Add(1)
+				if err != nil {
+					failures.Add(1)
+					t.Errorf("call to %s: %v", name, err)
+					continue
+				}
+				if resultText(t, resp) == "v2" {
+					v2.Store(name, true)
+				}
+			}
+		}()
+	}
+	waitFor(t, func() bool { return calls.Load() >= 8 })
+
+	start := map[string]config.ServerConfig{
+		"a": newTestBackend(t, versionServer("v2")).config(),
+		"b": newTestBackend(t, versionServer("v2")).config(),
+	}
+	if err := m.ReplaceServers(ctx, start, []string{"c"}); err != nil {
+		t.Fatalf("replace: %v", err)
+	}
+
+	// Calls reach the new set shortly after
+	waitFor(t, func() bool {
+		_, a := v2.Load("a")
+		_, b := v2.Load("b")
+		return a && b
+	})
+	close(stop)
+	wg.Wait()
+	m.reg.Unsubscribe(snapshots)
+	<-watched
+
+	if n := failures.Load(); n > 0 {
+		t.Errorf("%d of %d calls failed", n, calls.Load())
+	}
+	if len(gaps) > 0 {
+		t.Errorf("tool lists without a or b: %v", gaps)
+	}
+	if _, ok := m.GetServer("c"); ok {
+		t.Error("c still running")
+	}
+	if _, ok := m.reg.Tool("c", "version"); ok {
+		t.Error("c's tool still registered")
+	}
+}
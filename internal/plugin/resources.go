@@ -0,0 +1,51 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// listResources fetches the resources of a backend that supports them. Like
+// prompts, a failure only costs the resources.
+func (m *Manager) listResources(ctx context.Context, name string, session *mcp.ClientSession) []registry.Resource {
+	if init := session.InitializeResult(); init == nil || init.Capabilities == nil || init.Capabilities.Resources == nil {
+		return nil
+	}
+	var resources []registry.Resource
+	for res, err := range session.Resources(ctx, nil) {
+		if err != nil {
+			m.logger.Warn("resources:list-fail", "plugin", name, "err", err)
+			return nil
+		}
+		resources = append(resources, registry.Resource{
+			URI:         res.URI,
+			Name:        res.Name,
+			Description: res.Description,
+			MIMEType:    res.MIMEType,
+			PluginID:    name,
+		})
+	}
+	return resources
+}
+
+// ReadResource reads a resource from the server owning it. Access is checked
+// against <plugin>:<name>, the resource's name rather than its URI.
+func (m *Manager) ReadResource(ctx context.Context, pluginID, name, uri string) (*mcp.ReadResourceResult, error) {
+	if id := identityFrom(ctx); id != nil && !id.Allows(pluginID, name) {
+		m.logger.Warn("resource:deny", "client", id.Name, "plugin", pluginID, "resource", name)
+		return nil, fmt.Errorf("%w: client %s may not read resource %s:%s", ErrForbidden, id.Name, pluginID, name)
+	}
+
+	server, ok := m.GetServer(pluginID)
+	if !ok {
+		return nil, fmt.Errorf("server not found: %s", pluginID)
+	}
+	res, err := server.session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+	if err != nil {
+		return nil, fmt.Errorf("read resource failed: %w", err)
+	}
+	return res, nil
+}
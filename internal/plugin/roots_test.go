@@ -0,0 +1,51 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// rootsServer has a roots tool asking the client for its roots and
+// answering with them as "<name>=<uri>" lines
+func rootsServer() *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "roots"}, nil)
+	server.AddTool(&mcp.Tool{Name: "roots", InputSchema: map[string]any{"type": "object"}}, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		res, err := req.Session.ListRoots(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		var lines []string
+		for _, root := range res.Roots {
+			lines = append(lines, root.Name+"="+root.URI)
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: strings.Join(lines, "\n")}}}, nil
+	})
+	return server
+}
+
+func TestConfiguredRoots(t *testing.T) {
+	m := newTestManager()
+	b := newTestBackend(t, rootsServer())
+	cfg := b.config()
+	cfg.Roots = []config.Root{
+		{URI: "file:///srv/repo", Name: "repo"},
+		{URI: "file:///srv/docs", Name: "docs"},
+	}
+	startServer(t, m, "roots", cfg)
+	t.Cleanup(func() { m.StopServer("roots") })
+
+	resp, err := m.Execute(context.Background(), "roots", "roots", json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The SDK lists roots by URI
+	want := "docs=file:///srv/docs\nrepo=file:///srv/repo"
+	if got := resultText(t, resp); got != want {
+		t.Errorf("backend got roots %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,79 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/logging"
+)
+
+const (
+	defaultStartRetryDelay = 500 * time.Millisecond
+	maxStartRetryDelay     = 10 * time.Second
+)
+
+// connectWithRetry runs connectServer, retrying the whole connect and list
+// sequence up to cfg.StartRetries times while it fails transiently
+func (m *Manager) connectWithRetry(ctx context.Context, name string, cfg config.ServerConfig) error {
+	return m.retryStart(ctx, name, cfg, func() error { return m.connectServer(ctx, name, cfg) })
+}
+
+// retryStart runs start until it succeeds, fails permanently or
+// cfg.StartRetries retries are used up
+func (m *Manager) retryStart(ctx context.Context, name string, cfg config.ServerConfig, start func() error) error {
+	delay := defaultStartRetryDelay
+	if cfg.StartRetryDelay > 0 {
+		delay = time.Duration(cfg.StartRetryDelay) * time.Millisecond
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := start()
+		if err == nil || attempt > cfg.StartRetries || ctx.Err() != nil || !transientStartErr(err) {
+			return err
+		}
+
+		m.logger.Warn("start:retry", "plugin", name, "attempt", attempt, "next", delay, "err", logging.RedactError(err, nil))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxStartRetryDelay)
+	}
+}
+
+// transientStartErr reports whether a failed start may succeed when retried:
+// timeouts, network failures and backend errors other than those rejecting
+// the request itself, such as method not found
+func transientStartErr(err error) bool {
+	if e := rpcError(err); e != nil {
+		var wire struct {
+			Code int64 `json:"code"`
+		}
+		data, _ := json.Marshal(e)
+		_ = json.Unmarshal(data, &wire)
+		switch wire.Code {
+		case -32700, -32600, -32601, -32602: // parse error, invalid request, method not found, invalid params
+			return false
+		}
+		return true
+	}
+
+	// The startup timer cancels the connect context, the caller's context
+	// was checked before
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED)
+}
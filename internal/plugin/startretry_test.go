@@ -0,0 +1,76 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// busyListBackend serves the echo server, answering its first failures
+// tools/list requests with a JSON-RPC error of the given code
+func busyListBackend(t *testing.T, failures int32, code int) (string, *atomic.Int32) {
+	t.Helper()
+	server := echoServer()
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil)
+	var lists atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if json.Unmarshal(body, &req) == nil && req.Method == "tools/list" && lists.Add(1) <= failures {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":%d,"message":"busy"}}`, req.ID, code)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(func() {
+		srv.CloseClientConnections()
+		srv.Close()
+	})
+	return srv.URL, &lists
+}
+
+func TestStartRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      int
+		retries   int
+		wantErr   bool
+		wantLists int32
+	}{
+		{"transient failure retried", -32603, 2, false, 2},
+		{"no retries configured", -32603, 0, true, 1},
+		{"method not found not retried", -32601, 2, true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url, lists := busyListBackend(t, 1, tt.code)
+			m := newTestManager()
+			cfg := config.ServerConfig{Type: "http", URL: url, StartRetries: tt.retries, StartRetryDelay: 1}
+			err := m.StartServer(context.Background(), "busy", cfg)
+			t.Cleanup(func() { m.StopServer("busy") })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("start: err = %v, want error %v", err, tt.wantErr)
+			}
+			if got := lists.Load(); got != tt.wantLists {
+				t.Errorf("%d tools/list requests, want %d", got, tt.wantLists)
+			}
+			if _, ok := m.reg.Tool("busy", "echo"); ok == tt.wantErr {
+				t.Errorf("echo registered = %v", ok)
+			}
+		})
+	}
+}
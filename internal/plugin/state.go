@@ -0,0 +1,74 @@
+package plugin
+
+// ServerState is the connection state of a single MCP server
+type ServerState string
+
+const (
+	StateDisconnected ServerState = "disconnected"
+	StateConnecting   ServerState = "connecting"
+	StateConnected    ServerState = "connected"
+	StateDegraded     ServerState = "degraded"
+	StateReconnecting ServerState = "reconnecting"
+	StateStopped      ServerState = "stopped"
+)
+
+// AllStates lists every server state, e.g. for exporting a state gauge
+var AllStates = []ServerState{
+	StateDisconnected,
+	StateConnecting,
+	StateConnected,
+	StateDegraded,
+	StateReconnecting,
+	StateStopped,
+}
+
+// EventStateChanged is emitted on every server state transition with "from"
+// and "to" details
+const EventStateChanged EventType = "state_changed"
+
+// State returns the current connection state of the named server
+func (m *Manager) State(name string) ServerState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if st, ok := m.states[name]; ok {
+		return st
+	}
+	return StateDisconnected
+}
+
+// States returns the connection state of every server the manager knows of
+func (m *Manager) States() map[string]ServerState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make(map[string]ServerState, len(m.states))
+	for name, st := range m.states {
+		out[name] = st
+	}
+	return out
+}
+
+// transition moves the named server to state to, emitting a state change
+// event if the state actually changed
+func (m *Manager) transition(name string, to ServerState, reason string) {
+	m.mu.Lock()
+	from, ok := m.states[name]
+	if !ok {
+		from = StateDisconnected
+	}
+	if from == to && ok {
+		m.mu.Unlock()
+		return
+	}
+	m.states[name] = to
+	if to != StateStopped {
+		delete(m.gaveUp, name)
+	}
+	m.mu.Unlock()
+
+	m.logger.Info("state", "plugin", name, "from", from, "to", to, "reason", reason)
+	details := map[string]string{"from": string(from), "to": string(to)}
+	if reason != "" {
+		details["reason"] = reason
+	}
+	m.emit(EventStateChanged, name, details)
+}
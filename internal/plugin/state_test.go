@@ -0,0 +1,104 @@
+package plugin
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// nextTransition returns the next state change of the named server
+func nextTransition(t *testing.T, ch chan Event) (ServerState, ServerState) {
+	t.Helper()
+	ev := nextEvent(t, ch, EventStateChanged)
+	return ServerState(ev.Details["from"]), ServerState(ev.Details["to"])
+}
+
+func TestStateTransitions(t *testing.T) {
+	m := newTestManager()
+	events := m.SubscribeEvents()
+	defer m.UnsubscribeEvents(events)
+
+	backend := newTestBackend(t, nil)
+	startServer(t, m, "echo", backend.config())
+	server, _ := m.GetServer("echo")
+	go m.keepalive(server, 100*time.Millisecond, "ping")
+
+	want := []struct{ from, to ServerState }{
+		{StateDisconnected, StateConnecting},
+		{StateConnecting, StateConnected},
+	}
+	for _, w := range want {
+		if from, to := nextTransition(t, events); from != w.from || to != w.to {
+			t.Fatalf("transition %s -> %s, want %s -> %s", from, to, w.from, w.to)
+		}
+	}
+
+	// A failed keepalive degrades the server, the next good one restores it
+	backend.failing.Store(true)
+	ev := nextEvent(t, events, EventUnhealthy)
+	backend.failing.Store(false)
+	if ev.Details["check"] != "keepalive" || ev.Details["failures"] != "1" {
+		t.Errorf("unhealthy event = %+v", ev)
+	}
+	if from, to := nextTransition(t, events); from != StateConnected || to != StateDegraded {
+		t.Fatalf("transition %s -> %s, want connected -> degraded", from, to)
+	}
+	if st := m.State("echo"); st != StateDegraded {
+		t.Errorf("state = %s, want degraded", st)
+	}
+	gauge := `
+# HELP mcp_hub_server_state Connection state of each server, 1 for the current state and 0 for the others
+# TYPE mcp_hub_server_state gauge
+mcp_hub_server_state{plugin="echo",state="connected"} 0
+mcp_hub_server_state{plugin="echo",state="connecting"} 0
+mcp_hub_server_state{plugin="echo",state="degraded"} 1
+mcp_hub_server_state{plugin="echo",state="disconnected"} 0
+mcp_hub_server_state{plugin="echo",state="reconnecting"} 0
+mcp_hub_server_state{plugin="echo",state="stopped"} 0
+`
+	if err := testutil.GatherAndCompare(m.Metrics(), strings.NewReader(gauge), "mcp_hub_server_state"); err != nil {
+		t.Error(err)
+	}
+	if from, to := nextTransition(t, events); from != StateDegraded || to != StateConnected {
+		t.Fatalf("transition %s -> %s, want degraded -> connected", from, to)
+	}
+
+	if err := m.StopServer("echo"); err != nil {
+		t.Fatal(err)
+	}
+	if from, to := nextTransition(t, events); from != StateConnected || to != StateStopped {
+		t.Fatalf("transition %s -> %s, want connected -> stopped", from, to)
+	}
+}
+
+func TestKeepaliveGivesUp(t *testing.T) {
+	m := newTestManager()
+	events := m.SubscribeEvents()
+	defer m.UnsubscribeEvents(events)
+
+	backend := newTestBackend(t, nil)
+	cfg := backend.config()
+	startServer(t, m, "echo", cfg)
+	cfg.RestartPolicy = "never"
+	server, _ := m.GetServer("echo")
+	server.setConfig(cfg)
+	backend.failing.Store(true)
+	go m.keepalive(server, 50*time.Millisecond, "ping")
+
+	for i := range keepaliveFailures {
+		if ev := nextEvent(t, events, EventUnhealthy); ev.Details["failures"] != strconv.Itoa(i+1) {
+			t.Errorf("unhealthy event %d = %+v", i+1, ev)
+		}
+	}
+	// Let the backend answer the session's closing
+	backend.failing.Store(false)
+	if ev := nextEvent(t, events, EventStopped); ev.Server != "echo" {
+		t.Errorf("stopped event for %s", ev.Server)
+	}
+	if st := m.State("echo"); st != StateStopped {
+		t.Errorf("state = %s, want stopped", st)
+	}
+}
@@ -0,0 +1,61 @@
+package plugin
+
+import (
+	"sort"
+)
+
+// ServerStatus summarizes one server the manager knows of
+type ServerStatus struct {
+	Name      string      `json:"name"`
+	Transport string      `json:"transport,omitempty"` // only known while running
+	Group     string      `json:"group,omitempty"`
+	State     ServerState `json:"state"`
+	Tools     int         `json:"tools"`
+	// ContainerID of a running docker server
+	ContainerID string `json:"containerId,omitempty"`
+	// Capabilities advertised on the latest connection
+	Capabilities []string `json:"capabilities,omitempty"`
+
+	// Load, while running. Saturation is InFlight divided by Limit.
+	InFlight   int     `json:"inFlight"`
+	Queued     int     `json:"queued"`
+	Limit      int     `json:"limit"`
+	Saturation float64 `json:"saturation"`
+	Rejected   int     `json:"rejected"`
+
+	// Circuit breaker state: "closed", "open" or "half-open", for running
+	// servers with a breakerThreshold
+	Breaker         string `json:"breaker,omitempty"`
+	BreakerFailures int    `json:"breakerFailures,omitempty"`
+}
+
+// ServerStatuses returns the status of every server the manager knows of,
+// running or not, sorted by name
+func (m *Manager) ServerStatuses() []ServerStatus {
+	tools := make(map[string]int)
+	for _, t := range m.reg.All() {
+		tools[t.PluginID]++
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]ServerStatus, 0, len(m.states))
+	for name, state := range m.states {
+		st := ServerStatus{Name: name, State: state, Tools: tools[name], Capabilities: m.capabilities[name]}
+		if s, ok := m.servers[name]; ok {
+			cfg := s.Config()
+			st.Transport = cfg.TransportType()
+			st.Group = cfg.Group
+			if s.container != nil {
+				st.ContainerID = s.container.ID()
+			}
+			l := s.gate.load()
+			st.InFlight, st.Queued, st.Limit, st.Saturation = l.running, l.queued, l.limit, l.saturation()
+			st.Breaker, st.BreakerFailures = m.breakerStatusLocked(name, cfg)
+		}
+		st.Rejected = m.rejectedCalls(name)
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -0,0 +1,98 @@
+package plugin
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// backendEnv makes the test binary serve a stdio backend or act as a
+// transform process instead of running the tests, see TestMain
+const backendEnv = "MCP_HUB_TEST_BACKEND"
+
+func TestMain(m *testing.M) {
+	switch os.Getenv(backendEnv) {
+	case "wedged":
+		serveWedged()
+	case "upper":
+		upperTransform()
+	case "env":
+		serveEnv()
+	default:
+		os.Exit(m.Run())
+	}
+}
+
+// serveWedged serves the echo server over stdio, except that pings are
+// swallowed unanswered, like by a process that is running but stuck
+func serveWedged() {
+	echoServer().Run(context.Background(), &dropTransport{Transport: &mcp.StdioTransport{}, method: "ping"})
+}
+
+// dropTransport discards incoming requests of method
+type dropTransport struct {
+	mcp.Transport
+	method string
+}
+
+func (t *dropTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	conn, err := t.Transport.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &dropConn{Connection: conn, method: t.method}, nil
+}
+
+type dropConn struct {
+	mcp.Connection
+	method string
+}
+
+func (c *dropConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	for {
+		msg, err := c.Connection.Read(ctx)
+		if req, ok := msg.(*jsonrpc.Request); ok && req.Method == c.method {
+			continue
+		}
+		return msg, err
+	}
+}
+
+func TestKeepaliveStdioWedged(t *testing.T) {
+	m := newTestManager()
+	events := m.SubscribeEvents()
+	defer m.UnsubscribeEvents(events)
+
+	cfg := config.ServerConfig{
+		Command:       os.Args[0],
+		Env:           map[string]string{backendEnv: "wedged"},
+		RestartPolicy: "never",
+	}
+	startServer(t, m, "wedged", cfg)
+	t.Cleanup(func() { m.StopServer("wedged") })
+
+	// The process is alive and answers calls, only pings go unanswered
+	if _, err := m.Execute(context.Background(), "wedged", "echo", []byte(`{"text":"hi"}`)); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	server, _ := m.GetServer("wedged")
+	go m.keepalive(server, 50*time.Millisecond, "ping")
+
+	for i := range keepaliveFailures {
+		if ev := nextEvent(t, events, EventUnhealthy); ev.Details["failures"] != strconv.Itoa(i+1) {
+			t.Errorf("unhealthy event %d = %+v", i+1, ev)
+		}
+	}
+	if ev := nextEvent(t, events, EventStopped); ev.Server != "wedged" {
+		t.Errorf("stopped event for %s", ev.Server)
+	}
+	if _, ok := m.GetServer("wedged"); ok {
+		t.Error("wedged server still active")
+	}
+}
@@ -0,0 +1,80 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Chunk is a piece of incremental output reported by a backend while a tool
+// call is still running. MCP delivers it as a progress notification.
+type Chunk struct {
+	Progress float64
+	Total    float64
+	Message  string
+}
+
+// ChunkFunc receives incremental output for an in-flight tool call
+type ChunkFunc func(Chunk)
+
+type chunkFuncKey struct{}
+
+// WithChunkFunc returns a context that asks Execute to stream incremental
+// output to fn as it arrives instead of only returning the final result
+func WithChunkFunc(ctx context.Context, fn ChunkFunc) context.Context {
+	return context.WithValue(ctx, chunkFuncKey{}, fn)
+}
+
+func chunkFuncFrom(ctx context.Context) ChunkFunc {
+	fn, _ := ctx.Value(chunkFuncKey{}).(ChunkFunc)
+	return fn
+}
+
+// streams routes progress notifications from backends to in-flight calls
+type streams struct {
+	mu    sync.Mutex
+	sinks map[string]ChunkFunc
+	next  uint64 // makes tokens unique even for calls started at once
+}
+
+func newStreams() *streams {
+	return &streams{sinks: make(map[string]ChunkFunc)}
+}
+
+// open registers fn under a new progress token and returns the token along
+// with a function releasing it. The SDK handles notifications apart from
+// responses, so progress sent right before the result may be handled after
+// the call returned and is dropped.
+func (s *streams) open(pluginID string, fn ChunkFunc) (string, func()) {
+	s.mu.Lock()
+	s.next++
+	token := fmt.Sprintf("mcp-hub-%s-%d", pluginID, s.next)
+	s.sinks[token] = fn
+	s.mu.Unlock()
+	return token, func() {
+		s.mu.Lock()
+		delete(s.sinks, token)
+		s.mu.Unlock()
+	}
+}
+
+// handleProgress is installed as the client progress handler for every backend
+func (s *streams) handleProgress(ctx context.Context, req *mcp.ProgressNotificationClientRequest) {
+	token, ok := req.Params.ProgressToken.(string)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	fn := s.sinks[token]
+	s.mu.Unlock()
+	if fn == nil {
+		return
+	}
+	fn(Chunk{
+		Progress: req.Params.Progress,
+		Total:    req.Params.Total,
+		Message:  req.Params.Message,
+	})
+}
@@ -0,0 +1,117 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// streamingServer has a count tool reporting each step as progress while it
+// works, then answering or stalling if asked to
+func streamingServer() *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "streaming"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "count"}, func(ctx context.Context, req *mcp.CallToolRequest, args struct {
+		N     int  `json:"n,omitempty"`
+		Stall bool `json:"stall,omitempty"`
+	}) (*mcp.CallToolResult, any, error) {
+		token := req.Params.GetProgressToken()
+		for i := 1; i <= args.N && token != nil; i++ {
+			err := req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+				ProgressToken: token,
+				Progress:      float64(i),
+				Total:         float64(args.N),
+				Message:       fmt.Sprintf("step %d", i),
+			})
+			if err != nil {
+				return nil, nil, err
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		if args.Stall {
+			<-ctx.Done()
+			return nil, nil, ctx.Err()
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil, nil
+	})
+	return server
+}
+
+func TestExecuteStreamsChunks(t *testing.T) {
+	m := newTestManager()
+	startServer(t, m, "streaming", newTestBackend(t, streamingServer()).config())
+
+	var mu sync.Mutex
+	var chunks []Chunk
+	ctx := WithChunkFunc(context.Background(), func(c Chunk) {
+		mu.Lock()
+		chunks = append(chunks, c)
+		mu.Unlock()
+	})
+	resp, err := m.Execute(ctx, "streaming", "count", []byte(`{"n":3}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resultText(t, resp); got != "done" {
+		t.Errorf("result %q", got)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(chunks) != 3 {
+		t.Fatalf("%d chunks, want 3", len(chunks))
+	}
+	for i, c := range chunks {
+		if c.Progress != float64(i+1) || c.Total != 3 || c.Message != fmt.Sprintf("step %d", i+1) {
+			t.Errorf("chunk %d = %+v", i, c)
+		}
+	}
+
+	// Without a chunk func the call only returns the result
+	if _, err := m.Execute(context.Background(), "streaming", "count", []byte(`{"n":3}`)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPartialResultOnTimeout(t *testing.T) {
+	for _, onTimeout := range []string{"", "partial"} {
+		m := newTestManager()
+		cfg := newTestBackend(t, streamingServer()).config()
+		cfg.Timeout = 1
+		cfg.OnTimeout = onTimeout
+		startServer(t, m, "streaming", cfg)
+
+		resp, err := m.Execute(context.Background(), "streaming", "count", []byte(`{"n":2,"stall":true}`))
+		m.StopServer("streaming")
+		if onTimeout != "partial" {
+			if err == nil {
+				t.Errorf("onTimeout %q: stalled call succeeded", onTimeout)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("onTimeout %q: %v", onTimeout, err)
+		}
+
+		var result struct {
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+			Meta map[string]any `json:"_meta"`
+		}
+		if err := json.Unmarshal(resp, &result); err != nil {
+			t.Fatal(err)
+		}
+		if len(result.Content) != 2 || result.Content[0].Text != "step 1\nstep 2" || !strings.Contains(result.Content[1].Text, "result is partial") {
+			t.Errorf("onTimeout %q: content = %+v, want the streamed steps marked as partial", onTimeout, result.Content)
+		}
+		if result.Meta["mcp-hub/partial"] != true {
+			t.Errorf("onTimeout %q: _meta = %v", onTimeout, result.Meta)
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package plugin
+
+import (
+	"context"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/amir-the-h/mcp-hub/internal/plugin"
+
+// Instruments come from the global providers, which are no-ops unless
+// telemetry is configured
+var (
+	meter = otel.Meter(instrumentationName)
+
+	callCounter, _ = meter.Int64Counter("mcp_hub.tool.calls",
+		metric.WithDescription("Tool calls forwarded to MCP servers"))
+	callDuration, _ = meter.Float64Histogram("mcp_hub.tool.duration",
+		metric.WithDescription("Duration of tool calls forwarded to MCP servers"),
+		metric.WithUnit("s"))
+)
+
+// WithTracerProvider sets where tool call and connect spans go instead of
+// the global provider, which is a no-op unless telemetry is configured
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(m *Manager) {
+		if tp != nil {
+			m.tracer = tp.Tracer(instrumentationName)
+		}
+	}
+}
+
+// callSpanName names every tool call span alike, the server and tool are
+// attributes so span names stay few
+const callSpanName = "mcp.tool.call"
+
+// startCallSpan starts the span covering a single tool call
+func (m *Manager) startCallSpan(ctx context.Context, pluginID, toolName string) (context.Context, trace.Span) {
+	return m.tracer.Start(ctx, callSpanName,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("mcp.server", pluginID),
+			attribute.String("mcp.tool", toolName),
+		))
+}
+
+// endCallSpan finishes the call span and records the call metrics
+func endCallSpan(ctx context.Context, span trace.Span, pluginID, toolName string, resultBytes int, dur time.Duration, err error) {
+	span.SetAttributes(attribute.Int("mcp.tool.result_size", resultBytes))
+	status := "ok"
+	if err != nil {
+		status = "error"
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+
+	attrs := metric.WithAttributes(
+		attribute.String("server", pluginID),
+		attribute.String("tool", toolName),
+		attribute.String("status", status),
+	)
+	callCounter.Add(ctx, 1, attrs)
+	callDuration.Record(ctx, dur.Seconds(), attrs)
+}
+
+// setCallRequestID ties the call span to the exec:* log lines of an attempt.
+// After a failover it names the standby's attempt.
+func setCallRequestID(ctx context.Context, reqID int64) {
+	trace.SpanFromContext(ctx).SetAttributes(attribute.Int64("mcp.request_id", reqID))
+}
+
+// startConnectSpan starts the span covering connecting to a server and
+// listing its tools, resources and prompts
+func (m *Manager) startConnectSpan(ctx context.Context, name string, cfg config.ServerConfig) (context.Context, trace.Span) {
+	return m.tracer.Start(ctx, "connect "+name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("mcp.server", name),
+			attribute.String("mcp.transport", cfg.TransportType()),
+		))
+}
+
+// endConnectSpan finishes the connect span
+func endConnectSpan(span trace.Span, d *dialedServer, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetAttributes(attribute.Int("mcp.tools", len(d.tools)))
+	}
+	span.End()
+}
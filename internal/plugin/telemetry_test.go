@@ -0,0 +1,75 @@
+package plugin
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestCallSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	m := NewManager(registry.New(), WithTracerProvider(tp))
+
+	m.Execute(context.Background(), "github", "search", nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("%d spans ended, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "mcp.tool.call" {
+		t.Errorf("span name = %q", span.Name())
+	}
+	attrs := map[attribute.Key]string{}
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+	if attrs["mcp.server"] != "github" || attrs["mcp.tool"] != "search" {
+		t.Errorf("attributes = %v", attrs)
+	}
+}
+
+func TestCallSpanPropagation(t *testing.T) {
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTextMapPropagator(prev) })
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	m := NewManager(registry.New(), WithLogger(testLogger), WithTracerProvider(tp))
+	b := newTestBackend(t, nil)
+	startServer(t, m, "echo", b.config())
+	t.Cleanup(func() { m.StopServer("echo") })
+
+	// The incoming request's trace continues through the hub to the backend
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "client")
+	if _, err := m.Execute(ctx, "echo", "echo", []byte(`{"text":"hi"}`)); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	parent.End()
+
+	traceID := parent.SpanContext().TraceID()
+	var call sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == callSpanName {
+			call = span
+		}
+	}
+	if call == nil {
+		t.Fatal("no call span recorded")
+	}
+	if call.Parent().SpanID() != parent.SpanContext().SpanID() || call.SpanContext().TraceID() != traceID {
+		t.Error("call span isn't a child of the incoming request's span")
+	}
+	if got := b.header("tools/call").Get("Traceparent"); !strings.Contains(got, traceID.String()) {
+		t.Errorf("backend got traceparent %q, want trace %s", got, traceID)
+	}
+}
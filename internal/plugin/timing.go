@@ -0,0 +1,26 @@
+package plugin
+
+import (
+	"context"
+	"time"
+)
+
+// Timing is the phase breakdown of a single Execute call
+type Timing struct {
+	Queue   time.Duration // waiting for the server to accept the call
+	Call    time.Duration // the backend CallTool round trip
+	Process time.Duration // encoding the backend result
+}
+
+type timingKey struct{}
+
+// WithTiming returns a context that makes Execute record its phase
+// breakdown into t
+func WithTiming(ctx context.Context, t *Timing) context.Context {
+	return context.WithValue(ctx, timingKey{}, t)
+}
+
+func timingFrom(ctx context.Context) *Timing {
+	t, _ := ctx.Value(timingKey{}).(*Timing)
+	return t
+}
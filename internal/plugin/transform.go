@@ -0,0 +1,86 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+)
+
+const defaultTransformTimeout = 10 * time.Second
+
+// transformInput is the document a transform process reads from stdin. Input
+// transforms write the new arguments object, output transforms the new
+// CallToolResult.
+type transformInput struct {
+	Server    string          `json:"server"`
+	Tool      string          `json:"tool"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+	Result    json.RawMessage `json:"result,omitempty"`
+}
+
+// runTransform pipes in through the argv command and returns its output,
+// which must be a JSON document
+func runTransform(ctx context.Context, argv []string, timeout int, in transformInput) (json.RawMessage, error) {
+	d := defaultTransformTimeout
+	if timeout > 0 {
+		d = time.Duration(timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+
+	payload, err := json.Marshal(in)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transform input: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := truncate(strings.TrimSpace(stderr.String()), maxInitOutput); msg != "" {
+			return nil, fmt.Errorf("transform %s: %w: %s", argv[0], err, msg)
+		}
+		return nil, fmt.Errorf("transform %s: %w", argv[0], err)
+	}
+
+	out := bytes.TrimSpace(stdout.Bytes())
+	if !json.Valid(out) {
+		return nil, fmt.Errorf("transform %s: output is not valid JSON", argv[0])
+	}
+	return json.RawMessage(out), nil
+}
+
+// transformArguments applies the tool's input transform, if any
+func transformArguments(ctx context.Context, cfg config.ServerConfig, pluginID, toolName string, arguments json.RawMessage) (json.RawMessage, error) {
+	tr, ok := cfg.Transforms[toolName]
+	if !ok || len(tr.Input) == 0 {
+		return arguments, nil
+	}
+	return runTransform(ctx, tr.Input, tr.Timeout, transformInput{
+		Server:    pluginID,
+		Tool:      toolName,
+		Arguments: arguments,
+	})
+}
+
+// transformResult applies the tool's output transform, if any
+func transformResult(ctx context.Context, cfg config.ServerConfig, pluginID, toolName string, arguments, result json.RawMessage) (json.RawMessage, error) {
+	tr, ok := cfg.Transforms[toolName]
+	if !ok || len(tr.Output) == 0 {
+		return result, nil
+	}
+	return runTransform(ctx, tr.Output, tr.Timeout, transformInput{
+		Server:    pluginID,
+		Tool:      toolName,
+		Arguments: arguments,
+		Result:    result,
+	})
+}
@@ -0,0 +1,77 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+)
+
+// upperTransform is an input transform uppercasing every string argument
+func upperTransform() {
+	var in transformInput
+	if err := json.NewDecoder(os.Stdin).Decode(&in); err != nil {
+		os.Exit(1)
+	}
+	var args map[string]any
+	if err := json.Unmarshal(in.Arguments, &args); err != nil {
+		os.Exit(1)
+	}
+	for k, v := range args {
+		if s, ok := v.(string); ok {
+			args[k] = strings.ToUpper(s)
+		}
+	}
+	json.NewEncoder(os.Stdout).Encode(args)
+}
+
+func TestInputTransform(t *testing.T) {
+	t.Setenv(backendEnv, "upper")
+	m := newTestManager()
+	b := newTestBackend(t, nil)
+	cfg := b.config()
+	cfg.Transforms = map[string]config.ToolTransform{
+		"echo": {Input: []string{os.Args[0]}},
+	}
+	startServer(t, m, "echo", cfg)
+	t.Cleanup(func() { m.StopServer("echo") })
+
+	resp, err := m.Execute(context.Background(), "echo", "echo", json.RawMessage(`{"text":"hello"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resultText(t, resp); got != "HELLO" {
+		t.Errorf("echo returned %q, want the transformed argument", got)
+	}
+	var params struct {
+		Arguments map[string]string `json:"arguments"`
+	}
+	if err := json.Unmarshal(b.request("tools/call").Params, &params); err != nil {
+		t.Fatal(err)
+	}
+	if got := params.Arguments["text"]; got != "HELLO" {
+		t.Errorf("backend got text %q, want HELLO", got)
+	}
+}
+
+func TestInputTransformFailure(t *testing.T) {
+	m := newTestManager()
+	b := newTestBackend(t, nil)
+	cfg := b.config()
+	cfg.Transforms = map[string]config.ToolTransform{
+		"echo": {Input: []string{"false"}},
+	}
+	startServer(t, m, "echo", cfg)
+	t.Cleanup(func() { m.StopServer("echo") })
+
+	_, err := m.Execute(context.Background(), "echo", "echo", json.RawMessage(`{"text":"hello"}`))
+	if err == nil || !strings.Contains(err.Error(), "input transform failed") {
+		t.Fatalf("err = %v, want the transform failure", err)
+	}
+	if n := b.count("tools/call"); n != 0 {
+		t.Errorf("backend got %d calls after a failed transform", n)
+	}
+}
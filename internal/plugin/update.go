@@ -0,0 +1,52 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// UpdateConfig applies a config change that doesn't affect the connection to
+// a running server, without tearing down its session. Per-call settings take
+// effect on the next call, restart settings on the next reconnect, and the
+// tool list is listed and filtered again so tool selection and prefix
+// changes show up at once.
+func (m *Manager) UpdateConfig(ctx context.Context, name string, cfg config.ServerConfig) error {
+	defer m.lockServer(name)()
+	server, ok := m.GetServer(name)
+	if !ok {
+		return fmt.Errorf("server not found: %s", name)
+	}
+
+	tools, err := listTools(ctx, server.session)
+	if err != nil {
+		return fmt.Errorf("failed to list tools: %w", err)
+	}
+	tools, err = m.dedupeTools(name, tools, cfg.DuplicateTools)
+	if err != nil {
+		return err
+	}
+	tools = slices.DeleteFunc(tools, func(t *mcp.Tool) bool { return !cfg.ExposesTool(t.Name) })
+	tools = m.checkInputSchemas(name, tools, cfg.MissingInputSchema)
+
+	if server.headers != nil {
+		server.headers.set(cfg)
+	}
+	server.setConfig(cfg)
+	m.setFailover(name, cfg)
+
+	// Subscribers see the new tool list in one step
+	m.reg.Hold()
+	m.reg.UnregisterTools(name)
+	m.reg.RegisterTools(name, toRegistryTools(name, tools))
+	m.refreshVirtualTools(name)
+	m.refreshGroupTools(name)
+	m.reg.Release()
+
+	m.logger.Info("config:updated", "plugin", name, "tools", len(tools))
+	m.emit(EventReloaded, name, map[string]string{"mode": "live"})
+	return nil
+}
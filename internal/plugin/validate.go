@@ -0,0 +1,79 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// ErrInvalidArguments is returned for calls whose arguments don't match the
+// tool's input schema, on servers with validateArgs set
+var ErrInvalidArguments = errors.New("invalid arguments")
+
+// schemaCache keeps the compiled input schema of each tool, recompiled when
+// the backend lists a changed schema
+type schemaCache struct {
+	mu    sync.Mutex
+	tools map[string]compiledSchema
+}
+
+type compiledSchema struct {
+	raw      string
+	resolved *jsonschema.Resolved
+	err      error
+}
+
+func newSchemaCache() *schemaCache {
+	return &schemaCache{tools: make(map[string]compiledSchema)}
+}
+
+// get returns the compiled schema for a tool, and whether it was compiled
+// by this call
+func (c *schemaCache) get(key string, raw json.RawMessage) (compiledSchema, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cs, ok := c.tools[key]; ok && cs.raw == string(raw) {
+		return cs, false
+	}
+	cs := compiledSchema{raw: string(raw)}
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		cs.err = err
+	} else {
+		cs.resolved, cs.err = schema.Resolve(nil)
+	}
+	c.tools[key] = cs
+	return cs, true
+}
+
+// validateArguments checks a call's arguments against the input schema the
+// backend listed for the tool. Tools without a schema, or with one that
+// can't be compiled, e.g. for a remote $ref, are let through.
+func (m *Manager) validateArguments(pluginID, toolName string, arguments json.RawMessage) error {
+	tool, ok := m.reg.Tool(pluginID, toolName)
+	if !ok || len(tool.InputSchema) == 0 {
+		return nil
+	}
+	cs, compiled := m.schemas.get(pluginID+":"+toolName, tool.InputSchema)
+	if cs.err != nil {
+		if compiled {
+			m.logger.Warn("exec:schema-invalid", "plugin", pluginID, "tool", toolName, "err", cs.err)
+		}
+		return nil
+	}
+
+	// Missing arguments are an empty object to the backend too
+	instance := any(map[string]any{})
+	if len(arguments) > 0 {
+		if err := json.Unmarshal(arguments, &instance); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+		}
+	}
+	if err := cs.resolved.Validate(instance); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidArguments, err)
+	}
+	return nil
+}
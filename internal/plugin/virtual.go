@@ -0,0 +1,82 @@
+package plugin
+
+import (
+	"fmt"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+)
+
+// SetVirtualServers replaces the configured virtual servers and registers
+// their tools for every backend that is running
+func (m *Manager) SetVirtualServers(virtuals map[string]config.VirtualServer) {
+	m.mu.Lock()
+	old := m.virtuals
+	m.virtuals = virtuals
+	m.mu.Unlock()
+
+	for name := range old {
+		if _, ok := virtuals[name]; !ok {
+			m.reg.UnregisterTools(name)
+		}
+	}
+	backends := make(map[string]bool)
+	for _, v := range virtuals {
+		backends[v.Server] = true
+	}
+	for backend := range backends {
+		m.refreshVirtualTools(backend)
+	}
+}
+
+// refreshVirtualTools re-registers the tools of every virtual server backed
+// by backend from the backend's current tools, none if it isn't running
+func (m *Manager) refreshVirtualTools(backend string) {
+	m.mu.Lock()
+	var names []string
+	for name, v := range m.virtuals {
+		if v.Server == backend {
+			names = append(names, name)
+		}
+	}
+	virtuals := m.virtuals
+	m.mu.Unlock()
+	if len(names) == 0 {
+		return
+	}
+
+	var tools []registry.Tool
+	for _, t := range m.reg.All() {
+		if t.PluginID == backend {
+			tools = append(tools, t)
+		}
+	}
+	for _, name := range names {
+		m.reg.UnregisterTools(name)
+		var exposed []registry.Tool
+		for _, t := range tools {
+			if virtuals[name].Exposes(t.Name) {
+				exposed = append(exposed, t)
+			}
+		}
+		if len(exposed) > 0 {
+			m.reg.RegisterTools(name, exposed)
+			m.logger.Info("virtual:expose", "plugin", name, "tools", len(exposed), "backend", backend)
+		}
+	}
+}
+
+// resolveVirtual maps a call to a virtual server onto its backend, other
+// servers are returned unchanged
+func (m *Manager) resolveVirtual(pluginID, toolName string) (string, error) {
+	m.mu.Lock()
+	v, ok := m.virtuals[pluginID]
+	m.mu.Unlock()
+	if !ok {
+		return pluginID, nil
+	}
+	if !v.Exposes(toolName) {
+		return "", fmt.Errorf("tool %s is not exposed by virtual server %s", toolName, pluginID)
+	}
+	return v.Server, nil
+}
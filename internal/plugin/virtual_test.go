@@ -0,0 +1,73 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// namedToolsServer has tools answering with their own name
+func namedToolsServer(names ...string) *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "tools"}, nil)
+	for _, name := range names {
+		server.AddTool(&mcp.Tool{Name: name, InputSchema: map[string]any{"type": "object"}}, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: name}}}, nil
+		})
+	}
+	return server
+}
+
+func TestVirtualServers(t *testing.T) {
+	reg := registry.New()
+	m := NewManager(reg, WithLogger(testLogger))
+	m.SetVirtualServers(map[string]config.VirtualServer{
+		"github-issues": {Server: "github", Tools: []string{"issue_*"}},
+		"github-repos":  {Server: "github", Tools: []string{"repo_*"}},
+	})
+	b := newTestBackend(t, namedToolsServer("issue_create", "issue_list", "repo_create"))
+	startServer(t, m, "github", b.config())
+	t.Cleanup(func() { m.StopServer("github") })
+
+	exposed := make(map[string][]string)
+	for _, tool := range reg.List() {
+		exposed[tool.PluginID] = append(exposed[tool.PluginID], tool.Name)
+	}
+	for name, want := range map[string][]string{
+		"github-issues": {"issue_create", "issue_list"},
+		"github-repos":  {"repo_create"},
+	} {
+		got := exposed[name]
+		slices.Sort(got)
+		if !slices.Equal(got, want) {
+			t.Errorf("%s exposes %v, want %v", name, got, want)
+		}
+	}
+
+	ctx := context.Background()
+	for _, call := range [][2]string{{"github-issues", "issue_list"}, {"github-repos", "repo_create"}} {
+		resp, err := m.Execute(ctx, call[0], call[1], json.RawMessage(`{}`))
+		if err != nil {
+			t.Fatalf("%s:%s: %v", call[0], call[1], err)
+		}
+		if got := resultText(t, resp); got != call[1] {
+			t.Errorf("%s:%s answered by %q", call[0], call[1], got)
+		}
+	}
+	if n := b.count("tools/call"); n != 2 {
+		t.Errorf("backend got %d calls, want 2", n)
+	}
+
+	_, err := m.Execute(ctx, "github-issues", "repo_create", json.RawMessage(`{}`))
+	if err == nil || !strings.Contains(err.Error(), "not exposed by virtual server") {
+		t.Errorf("call outside the subset: err = %v", err)
+	}
+	if n := b.count("tools/call"); n != 2 {
+		t.Errorf("call outside the subset reached the backend")
+	}
+}
@@ -0,0 +1,139 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/gorilla/websocket"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// wsWriteTimeout bounds writing one frame to a stalled connection
+const wsWriteTimeout = 30 * time.Second
+
+// wsTransport connects to a backend serving MCP over a WebSocket, one
+// JSON-RPC message per text frame. The SDK has no WebSocket client.
+type wsTransport struct {
+	cfg config.ServerConfig
+}
+
+func (t *wsTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	header := http.Header{}
+	for k, v := range t.cfg.Headers {
+		header.Set(k, v)
+	}
+	if provider := headerProvider(t.cfg); provider != nil {
+		resolved, err := provider(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve headers: %w", err)
+		}
+		for k, v := range resolved {
+			header.Set(k, v)
+		}
+	}
+
+	dialer := &websocket.Dialer{
+		Proxy:        http.ProxyFromEnvironment,
+		Subprotocols: []string{"mcp"},
+	}
+	conn, resp, err := dialer.DialContext(ctx, t.cfg.URL, header)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("websocket handshake failed with status %d: %w", resp.StatusCode, err)
+		}
+		return nil, fmt.Errorf("failed to connect to websocket: %w", err)
+	}
+
+	c := &wsConn{conn: conn, incoming: make(chan jsonrpc.Message), closed: make(chan struct{})}
+	go c.readLoop()
+	return c, nil
+}
+
+// wsConn is an mcp.Connection over a WebSocket
+type wsConn struct {
+	conn     *websocket.Conn
+	writeMu  sync.Mutex
+	incoming chan jsonrpc.Message
+
+	closeOnce sync.Once
+	closed    chan struct{}
+	err       error // why the read loop ended, set before closed is closed
+}
+
+// readLoop decodes frames for Read until the connection fails or closes
+func (c *wsConn) readLoop() {
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			c.shutdown(fmt.Errorf("websocket read failed: %w", err))
+			return
+		}
+		msg, err := jsonrpc.DecodeMessage(data)
+		if err != nil {
+			c.shutdown(fmt.Errorf("invalid message from backend: %w", err))
+			return
+		}
+		select {
+		case c.incoming <- msg:
+		case <-c.closed:
+			return
+		}
+	}
+}
+
+func (c *wsConn) shutdown(err error) {
+	c.closeOnce.Do(func() {
+		c.err = err
+		close(c.closed)
+		c.conn.Close()
+	})
+}
+
+func (c *wsConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	select {
+	case msg := <-c.incoming:
+		return msg, nil
+	case <-c.closed:
+		return nil, c.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (c *wsConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	data, err := jsonrpc.EncodeMessage(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %w", err)
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	select {
+	case <-c.closed:
+		return c.err
+	default:
+	}
+	deadline := time.Now().Add(wsWriteTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	c.conn.SetWriteDeadline(deadline)
+	return c.conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// Close sends a close frame, then drops the connection
+func (c *wsConn) Close() error {
+	c.writeMu.Lock()
+	msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	_ = c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+	c.writeMu.Unlock()
+	c.shutdown(mcp.ErrConnectionClosed)
+	return nil
+}
+
+func (c *wsConn) SessionID() string { return "" }
@@ -0,0 +1,412 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// sdkProtocolVersions are the protocol versions the SDK client can talk
+var sdkProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// wireRewriter adapts one aspect of the messages the SDK client exchanges
+// with a backend, for handshake customizations and backends that don't
+// accept the messages as the SDK writes them. It returns the message it is
+// given, and false, unless it rewrote it, and never modifies that message.
+type wireRewriter interface {
+	// outgoing returns a request as it is sent to the backend
+	outgoing(req *jsonrpc.Request) (*jsonrpc.Request, bool)
+	// incoming returns a response as the SDK reads it
+	incoming(resp *jsonrpc.Response) (*jsonrpc.Response, bool)
+	// done reports that no further message needs the rewriter
+	done() bool
+}
+
+// wireHook runs a server's rewriters on the messages between the SDK client
+// and the backend. Requests pass the rewriters in order and responses in
+// reverse, so each rewriter sees a response the way it sent the request it
+// answers.
+type wireHook struct {
+	rewriters []wireRewriter
+	capture   *initCapture
+}
+
+// wireHookFor returns the hook for a server's connection. Requests get
+// string IDs first, so that the later rewriters note the IDs as sent, and the
+// raw initialize result is captured before anything rewrites it.
+func (m *Manager) wireHookFor(name string, cfg config.ServerConfig) *wireHook {
+	h := &wireHook{capture: &initCapture{}}
+	if cfg.IDFormat == "string" {
+		h.rewriters = append(h.rewriters, stringIDs{})
+	}
+	if patch := initializePatchFor(cfg); patch != nil {
+		h.rewriters = append(h.rewriters, &patchRewriter{patch: patch})
+	}
+	h.rewriters = append(h.rewriters, &versionPolicy{
+		name:    name,
+		logger:  m.logger,
+		lenient: cfg.VersionPolicy == "lenient",
+	}, h.capture)
+	return h
+}
+
+// active reports whether messages may still need rewriting
+func (h *wireHook) active() bool {
+	for _, r := range h.rewriters {
+		if !r.done() {
+			return true
+		}
+	}
+	return false
+}
+
+// outgoing returns msg as it is sent to the backend
+func (h *wireHook) outgoing(msg jsonrpc.Message) (jsonrpc.Message, bool) {
+	req, ok := msg.(*jsonrpc.Request)
+	if !ok {
+		return msg, false
+	}
+	changed := false
+	for _, r := range h.rewriters {
+		var c bool
+		req, c = r.outgoing(req)
+		changed = changed || c
+	}
+	return req, changed
+}
+
+// incoming returns msg as the SDK expects to read it
+func (h *wireHook) incoming(msg jsonrpc.Message) (jsonrpc.Message, bool) {
+	resp, ok := msg.(*jsonrpc.Response)
+	if !ok {
+		return msg, false
+	}
+	changed := false
+	for _, r := range slices.Backward(h.rewriters) {
+		var c bool
+		resp, c = r.incoming(resp)
+		changed = changed || c
+	}
+	return resp, changed
+}
+
+// stringIDs sends request IDs as strings to backends that reject numbers.
+// The SDK numbers its requests, so the responses' IDs are turned back into
+// numbers before the SDK correlates them.
+type stringIDs struct{}
+
+func (stringIDs) outgoing(req *jsonrpc.Request) (*jsonrpc.Request, bool) {
+	if n, ok := req.ID.Raw().(int64); ok {
+		id, err := jsonrpc.MakeID(strconv.FormatInt(n, 10))
+		if err != nil {
+			return req, false
+		}
+		out := *req
+		out.ID = id
+		return &out, true
+	}
+	// Cancellations refer to the request by the ID the backend saw
+	if req.Method == "notifications/cancelled" {
+		var params map[string]any
+		if json.Unmarshal(req.Params, &params) != nil {
+			return req, false
+		}
+		n, ok := params["requestId"].(float64)
+		if !ok {
+			return req, false
+		}
+		params["requestId"] = strconv.FormatInt(int64(n), 10)
+		data, err := json.Marshal(params)
+		if err != nil {
+			return req, false
+		}
+		out := *req
+		out.Params = data
+		return &out, true
+	}
+	return req, false
+}
+
+func (stringIDs) incoming(resp *jsonrpc.Response) (*jsonrpc.Response, bool) {
+	s, ok := resp.ID.Raw().(string)
+	if !ok {
+		return resp, false
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return resp, false
+	}
+	id, err := jsonrpc.MakeID(float64(n))
+	if err != nil {
+		return resp, false
+	}
+	out := *resp
+	out.ID = id
+	return &out, true
+}
+
+func (stringIDs) done() bool { return false }
+
+// versionPolicy applies the server's versionPolicy to the initialize result:
+// an unsupported protocol version fails the initialize call when strict, or
+// is logged and replaced by the requested version when lenient
+type versionPolicy struct {
+	name    string
+	logger  *slog.Logger
+	lenient bool
+
+	mu        sync.Mutex
+	initID    jsonrpc.ID // of the initialize request as sent
+	requested string     // protocol version the initialize request asked for
+	initDone  bool
+}
+
+// outgoing notes the initialize request
+func (v *versionPolicy) outgoing(req *jsonrpc.Request) (*jsonrpc.Request, bool) {
+	if req.Method == "initialize" {
+		var params struct {
+			ProtocolVersion string `json:"protocolVersion"`
+		}
+		_ = json.Unmarshal(req.Params, &params)
+		v.mu.Lock()
+		v.initID, v.requested = req.ID, params.ProtocolVersion
+		v.mu.Unlock()
+	}
+	return req, false
+}
+
+// incoming checks the protocol version of the initialize result
+func (v *versionPolicy) incoming(resp *jsonrpc.Response) (*jsonrpc.Response, bool) {
+	v.mu.Lock()
+	if v.initDone || !v.initID.IsValid() || resp.ID != v.initID {
+		v.mu.Unlock()
+		return resp, false
+	}
+	v.initDone = true
+	requested := v.requested
+	v.mu.Unlock()
+
+	if resp.Error != nil {
+		return resp, false
+	}
+	var result map[string]any
+	if json.Unmarshal(resp.Result, &result) != nil {
+		return resp, false
+	}
+	version, _ := result["protocolVersion"].(string)
+	if slices.Contains(sdkProtocolVersions, version) {
+		return resp, false
+	}
+
+	out := *resp
+	if !v.lenient {
+		out.Result = nil
+		out.Error = fmt.Errorf("unsupported protocol version %q (requested %s, set versionPolicy lenient to proceed anyway)", version, requested)
+		return &out, true
+	}
+	v.logger.Warn("connect:protocol-version", "plugin", v.name, "version", version, "requested", requested)
+	result["protocolVersion"] = requested
+	data, err := json.Marshal(result)
+	if err != nil {
+		return resp, false
+	}
+	out.Result = data
+	return &out, true
+}
+
+func (v *versionPolicy) done() bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.initDone
+}
+
+// rewriteData applies rewrite to an encoded message, returning data
+// unchanged if it isn't a message or needs no change
+func rewriteData(data []byte, rewrite func(jsonrpc.Message) (jsonrpc.Message, bool)) []byte {
+	msg, err := jsonrpc.DecodeMessage(bytes.TrimSpace(data))
+	if err != nil {
+		return data
+	}
+	msg, changed := rewrite(msg)
+	if !changed {
+		return data
+	}
+	out, err := jsonrpc.EncodeMessage(msg)
+	if err != nil {
+		return data
+	}
+	return out
+}
+
+// withWireHook installs h on the given transport
+func withWireHook(transport mcp.Transport, h *wireHook) mcp.Transport {
+	switch t := transport.(type) {
+	case *mcp.StreamableClientTransport:
+		t.HTTPClient = wireHTTPClient(t.HTTPClient, h)
+		return t
+	case *mcp.SSEClientTransport:
+		t.HTTPClient = wireHTTPClient(t.HTTPClient, h)
+		return t
+	default:
+		return &wireTransport{Transport: transport, hook: h}
+	}
+}
+
+// wireTransport rewrites the messages written to and read from a connection
+type wireTransport struct {
+	mcp.Transport
+	hook *wireHook
+}
+
+func (t *wireTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	conn, err := t.Transport.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &wireConn{Connection: conn, hook: t.hook}, nil
+}
+
+type wireConn struct {
+	mcp.Connection
+	hook *wireHook
+}
+
+func (c *wireConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	msg, _ = c.hook.outgoing(msg)
+	return c.Connection.Write(ctx, msg)
+}
+
+func (c *wireConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	msg, err := c.Connection.Read(ctx)
+	if err != nil {
+		return msg, err
+	}
+	msg, _ = c.hook.incoming(msg)
+	return msg, nil
+}
+
+func wireHTTPClient(client *http.Client, h *wireHook) *http.Client {
+	if client == nil {
+		client = &http.Client{}
+	}
+	rewritten := *client
+	rewritten.Transport = &wireRoundTripper{base: client.Transport, hook: h}
+	return &rewritten
+}
+
+// wireRoundTripper rewrites messages posted over HTTP and those read back,
+// as a JSON body or as SSE events, on the POST itself or on the SSE stream.
+// Streamable HTTP connections rely on SDK-internal session hooks that a
+// connection wrapper would hide, so HTTP transports are hooked here instead.
+// Each body is decoded once for all rewriters, and not at all once none of
+// them is active.
+type wireRoundTripper struct {
+	base http.RoundTripper
+	hook *wireHook
+}
+
+func (rt *wireRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := rt.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	if req.Method == http.MethodPost && req.Body != nil && rt.hook.active() {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		body = rewriteData(body, rt.hook.outgoing)
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil || resp.Body == nil || !rt.hook.active() {
+		return resp, err
+	}
+	mediaType := strings.ToLower(resp.Header.Get("Content-Type"))
+	switch {
+	case strings.HasPrefix(mediaType, "text/event-stream"):
+		resp.Body = &wireBody{ReadCloser: resp.Body, hook: rt.hook, reader: bufio.NewReader(resp.Body), events: true}
+	case strings.HasPrefix(mediaType, "application/json"):
+		resp.Body = &wireBody{ReadCloser: resp.Body, hook: rt.hook, reader: bufio.NewReader(resp.Body)}
+		resp.ContentLength = -1
+		resp.Header.Del("Content-Length")
+	}
+	return resp, nil
+}
+
+// wireBody rewrites the messages of a response body as it is read, line by
+// line for SSE streams so events are passed on as soon as they arrive
+type wireBody struct {
+	io.ReadCloser
+	hook   *wireHook
+	reader *bufio.Reader
+	events bool
+
+	out bytes.Buffer // rewritten data not read yet
+	err error
+}
+
+func (b *wireBody) Read(p []byte) (int, error) {
+	for b.out.Len() == 0 && b.err == nil {
+		if b.events {
+			b.readEventLine()
+		} else {
+			b.readJSON()
+		}
+	}
+	if b.out.Len() > 0 {
+		return b.out.Read(p)
+	}
+	return 0, b.err
+}
+
+// readEventLine rewrites the next line of an SSE stream if it carries data
+func (b *wireBody) readEventLine() {
+	line, err := b.reader.ReadBytes('\n')
+	b.err = err
+	content := bytes.TrimRight(line, "\r\n")
+	data, ok := bytes.CutPrefix(content, []byte("data:"))
+	if !ok {
+		b.out.Write(line)
+		return
+	}
+	rewritten := rewriteData(data, b.hook.incoming)
+	if bytes.Equal(rewritten, data) {
+		b.out.Write(line)
+		return
+	}
+	b.out.WriteString("data: ")
+	b.out.Write(rewritten)
+	b.out.Write(line[len(content):])
+}
+
+// readJSON rewrites a plain JSON body as a whole
+func (b *wireBody) readJSON() {
+	data, err := io.ReadAll(b.reader)
+	b.err = err
+	if b.err == nil {
+		b.err = io.EOF
+	}
+	b.out.Write(rewriteData(data, b.hook.incoming))
+}
@@ -0,0 +1,277 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// idRecorder keeps the IDs of the requests sent to a backend
+type idRecorder struct {
+	mu  sync.Mutex
+	ids []any
+}
+
+func (r *idRecorder) record(msg jsonrpc.Message) {
+	if req, ok := msg.(*jsonrpc.Request); ok && req.ID.IsValid() {
+		r.mu.Lock()
+		r.ids = append(r.ids, req.ID.Raw())
+		r.mu.Unlock()
+	}
+}
+
+func (r *idRecorder) check(t *testing.T) {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.ids) == 0 {
+		t.Fatal("no requests recorded")
+	}
+	for _, id := range r.ids {
+		if _, ok := id.(string); !ok {
+			t.Errorf("request ID %v (%T) sent, want a string", id, id)
+		}
+	}
+}
+
+type recordTransport struct {
+	mcp.Transport
+	rec *idRecorder
+}
+
+func (t *recordTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	conn, err := t.Transport.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &recordConn{Connection: conn, rec: t.rec}, nil
+}
+
+type recordConn struct {
+	mcp.Connection
+	rec *idRecorder
+}
+
+func (c *recordConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	c.rec.record(msg)
+	return c.Connection.Write(ctx, msg)
+}
+
+type recordRoundTripper struct {
+	rec *idRecorder
+}
+
+func (rt *recordRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodPost && req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		if msg, err := jsonrpc.DecodeMessage(body); err == nil {
+			rt.rec.record(msg)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// callEcho connects over transport and checks calls are correlated
+func callEcho(t *testing.T, transport mcp.Transport) {
+	t.Helper()
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil)
+	session, err := client.Connect(ctx, transport, nil)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer session.Close()
+
+	for _, text := range []string{"one", "two", "three"} {
+		res, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "echo", Arguments: map[string]any{"text": text}})
+		if err != nil {
+			t.Fatalf("call %s: %v", text, err)
+		}
+		if got := res.Content[0].(*mcp.TextContent).Text; got != text {
+			t.Errorf("call %s returned %q", text, got)
+		}
+	}
+}
+
+func TestWireRewriteStringIDs(t *testing.T) {
+	m := NewManager(registry.New())
+	hook := m.wireHookFor("echo", config.ServerConfig{IDFormat: "string"})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := echoServer().Connect(context.Background(), serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer serverSession.Close()
+
+	rec := &idRecorder{}
+	callEcho(t, withWireHook(&recordTransport{Transport: clientTransport, rec: rec}, hook))
+	rec.check(t)
+}
+
+func TestWireRewriteStringIDsOverHTTP(t *testing.T) {
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return echoServer() }, nil)
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	rec := &idRecorder{}
+	m := NewManager(registry.New())
+	transport := withWireHook(&mcp.StreamableClientTransport{
+		Endpoint:   srv.URL,
+		HTTPClient: &http.Client{Transport: &recordRoundTripper{rec: rec}},
+	}, m.wireHookFor("echo", config.ServerConfig{IDFormat: "string"}))
+	callEcho(t, transport)
+	rec.check(t)
+}
+
+func TestWireRewriteCancelled(t *testing.T) {
+	note := &jsonrpc.Request{Method: "notifications/cancelled", Params: []byte(`{"requestId":7,"reason":"gone"}`)}
+	out, changed := stringIDs{}.outgoing(note)
+	if !changed {
+		t.Fatal("cancellation not rewritten")
+	}
+	if got := string(out.Params); got != `{"reason":"gone","requestId":"7"}` {
+		t.Errorf("params = %s", got)
+	}
+	if string(note.Params) != `{"requestId":7,"reason":"gone"}` {
+		t.Error("original message modified")
+	}
+}
+
+// oldBackend answers initialize with a protocol version the SDK doesn't
+// support, and anything else with an empty result
+func oldBackend(conn mcp.Connection) {
+	for {
+		msg, err := conn.Read(context.Background())
+		if err != nil {
+			return
+		}
+		req, ok := msg.(*jsonrpc.Request)
+		if !ok || !req.ID.IsValid() {
+			continue
+		}
+		result := []byte(`{}`)
+		switch req.Method {
+		case "initialize":
+			result = []byte(`{"protocolVersion":"1999-01-01","capabilities":{},"serverInfo":{"name":"old","version":"1"}}`)
+		case "tools/list":
+			result = []byte(`{"tools":[]}`)
+		}
+		if err := conn.Write(context.Background(), &jsonrpc.Response{ID: req.ID, Result: result}); err != nil {
+			return
+		}
+	}
+}
+
+func TestWireRewriteVersionPolicy(t *testing.T) {
+	for _, policy := range []string{"", "strict", "lenient"} {
+		t.Run("policy="+policy, func(t *testing.T) {
+			ctx := context.Background()
+			serverTransport, clientTransport := mcp.NewInMemoryTransports()
+			conn, err := serverTransport.Connect(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+			go oldBackend(conn)
+
+			m := NewManager(registry.New())
+			transport := withWireHook(clientTransport, m.wireHookFor("old", config.ServerConfig{VersionPolicy: policy, IDFormat: "string"}))
+			client := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil)
+			session, err := client.Connect(ctx, transport, nil)
+			if policy != "lenient" {
+				if err == nil {
+					session.Close()
+					t.Fatal("connected to a backend with an unsupported protocol version")
+				}
+				if !strings.Contains(err.Error(), `unsupported protocol version "1999-01-01"`) {
+					t.Errorf("error = %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("connect: %v", err)
+			}
+			defer session.Close()
+			if got := session.InitializeResult().ProtocolVersion; !slices.Contains(sdkProtocolVersions, got) {
+				t.Errorf("negotiated protocol version %q", got)
+			}
+			if _, err := session.ListTools(ctx, nil); err != nil {
+				t.Errorf("list tools: %v", err)
+			}
+		})
+	}
+}
+
+// TestWireHookOverHTTP runs every rewriter on one HTTP connection: the
+// backend sees string IDs and the patched initialize request, and the hub
+// keeps the initialize result as the backend sent it
+func TestWireHookOverHTTP(t *testing.T) {
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return echoServer() }, nil)
+	var mu sync.Mutex
+	var initParams json.RawMessage
+	rec := &idRecorder{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			body, _ := io.ReadAll(r.Body)
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			if msg, err := jsonrpc.DecodeMessage(body); err == nil {
+				rec.record(msg)
+				if req, ok := msg.(*jsonrpc.Request); ok && req.Method == "initialize" {
+					mu.Lock()
+					initParams = req.Params
+					mu.Unlock()
+				}
+			}
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(func() {
+		srv.CloseClientConnections()
+		srv.Close()
+	})
+
+	m := newTestManager()
+	startServer(t, m, "echo", config.ServerConfig{
+		Type:                     "http",
+		URL:                      srv.URL,
+		IDFormat:                 "string",
+		ExperimentalCapabilities: map[string]any{"batching": map[string]any{}},
+	})
+	t.Cleanup(func() { m.StopServer("echo") })
+	if _, err := m.Execute(context.Background(), "echo", "echo", json.RawMessage(`{"text":"hi"}`)); err != nil {
+		t.Fatalf("call: %v", err)
+	}
+	rec.check(t)
+
+	mu.Lock()
+	params := string(initParams)
+	mu.Unlock()
+	if !strings.Contains(params, `"experimental":{"batching":{}}`) {
+		t.Errorf("initialize params %s, want the experimental capability", params)
+	}
+	raw, err := m.InitializeResult("echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result map[string]any
+	if err := json.Unmarshal(raw, &result); err != nil || result["protocolVersion"] == nil {
+		t.Errorf("initialize result %s, err %v", raw, err)
+	}
+}
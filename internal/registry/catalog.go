@@ -0,0 +1,197 @@
+package registry
+
+import (
+	"sort"
+)
+
+// Resource represents a resource exposed by a plugin
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MIMEType    string `json:"mime_type,omitempty"`
+	PluginID    string `json:"plugin_id"`
+}
+
+// PromptArgument describes an argument a prompt accepts
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// Prompt represents a prompt exposed by a plugin
+type Prompt struct {
+	Name        string           `json:"name"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+	PluginID    string           `json:"plugin_id"`
+}
+
+// RegisterResources replaces the resources of a plugin
+func (r *Registry) RegisterResources(pluginID string, resources []Resource) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dropResourcesLocked(pluginID)
+	for _, res := range resources {
+		res.PluginID = pluginID
+		r.resources[pluginID+":"+res.URI] = res
+	}
+	r.broadcastResourcesLocked()
+}
+
+// UnregisterResources removes all resources associated with a plugin
+func (r *Registry) UnregisterResources(pluginID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dropResourcesLocked(pluginID)
+	r.broadcastResourcesLocked()
+}
+
+// ListResources returns all resources sorted by plugin and URI
+func (r *Registry) ListResources() []Resource {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.resourcesLocked()
+}
+
+// SubscribeResources returns a channel receiving the current resources and
+// every later change
+func (r *Registry) SubscribeResources() chan []Resource {
+	ch := make(chan []Resource, 1)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.resourceSubs[ch] = struct{}{}
+	ch <- r.resourcesLocked()
+	return ch
+}
+
+func (r *Registry) UnsubscribeResources(ch chan []Resource) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.resourceSubs, ch)
+	close(ch)
+}
+
+func (r *Registry) dropResourcesLocked(pluginID string) {
+	for key, res := range r.resources {
+		if res.PluginID == pluginID {
+			delete(r.resources, key)
+		}
+	}
+}
+
+func (r *Registry) resourcesLocked() []Resource {
+	out := make([]Resource, 0, len(r.resources))
+	for _, res := range r.resources {
+		out = append(out, res)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].PluginID != out[j].PluginID {
+			return out[i].PluginID < out[j].PluginID
+		}
+		return out[i].URI < out[j].URI
+	})
+	return out
+}
+
+func (r *Registry) broadcastResourcesLocked() {
+	if r.holding {
+		return
+	}
+	snapshot := r.resourcesLocked()
+	for ch := range r.resourceSubs {
+		sendLatest(ch, snapshot)
+	}
+}
+
+// RegisterPrompts replaces the prompts of a plugin
+func (r *Registry) RegisterPrompts(pluginID string, prompts []Prompt) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dropPromptsLocked(pluginID)
+	for _, p := range prompts {
+		p.PluginID = pluginID
+		r.prompts[pluginID+":"+p.Name] = p
+	}
+	r.broadcastPromptsLocked()
+}
+
+// UnregisterPrompts removes all prompts associated with a plugin
+func (r *Registry) UnregisterPrompts(pluginID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.dropPromptsLocked(pluginID)
+	r.broadcastPromptsLocked()
+}
+
+// ListPrompts returns all prompts sorted by plugin and name
+func (r *Registry) ListPrompts() []Prompt {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.promptsLocked()
+}
+
+// SubscribePrompts returns a channel receiving the current prompts and every
+// later change
+func (r *Registry) SubscribePrompts() chan []Prompt {
+	ch := make(chan []Prompt, 1)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.promptSubs[ch] = struct{}{}
+	ch <- r.promptsLocked()
+	return ch
+}
+
+func (r *Registry) UnsubscribePrompts(ch chan []Prompt) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.promptSubs, ch)
+	close(ch)
+}
+
+func (r *Registry) dropPromptsLocked(pluginID string) {
+	for key, p := range r.prompts {
+		if p.PluginID == pluginID {
+			delete(r.prompts, key)
+		}
+	}
+}
+
+func (r *Registry) promptsLocked() []Prompt {
+	out := make([]Prompt, 0, len(r.prompts))
+	for _, p := range r.prompts {
+		out = append(out, p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].PluginID != out[j].PluginID {
+			return out[i].PluginID < out[j].PluginID
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
+
+func (r *Registry) broadcastPromptsLocked() {
+	if r.holding {
+		return
+	}
+	snapshot := r.promptsLocked()
+	for ch := range r.promptSubs {
+		sendLatest(ch, snapshot)
+	}
+}
+
+// sendLatest delivers snapshot without blocking, replacing a snapshot the
+// subscriber hasn't read yet. Unlike tools there is no periodic reconcile,
+// so a dropped snapshot would never be recovered.
+func sendLatest[T any](ch chan []T, snapshot []T) {
+	select {
+	case <-ch:
+	default:
+	}
+	select {
+	case ch <- snapshot:
+	default:
+	}
+}
@@ -2,28 +2,61 @@ package registry
 
 import (
 	"encoding/json"
+	"sort"
 	"sync"
 )
 
 // Tool represents a tool exposed by a plugin
 type Tool struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description,omitempty"`
-	PluginID    string `json:"plugin_id"`
+	ID          string          `json:"id"`
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema,omitempty"`
+	PluginID    string          `json:"plugin_id"`
+	// Servers able to run the tool when PluginID is a group of replicas
+	Backends []string `json:"backends,omitempty"`
 }
 
-// Registry stores registered tools and allows subscriptions for changes
+// Registry stores registered tools, resources and prompts and allows
+// subscriptions for changes.
+// Tools are keyed by plugin and ID, so plugins may expose tools of the same
+// name.
 type Registry struct {
 	mu    sync.RWMutex
 	tools map[string]Tool
 	subs  map[chan []Tool]struct{}
+
+	// order records when each tool was first registered, so tools keep the
+	// order their backend listed them in
+	order map[string]uint64
+	next  uint64
+
+	// hidden plugins' tools can be looked up but aren't listed, e.g. group
+	// members whose tools are listed under the group
+	hidden map[string]bool
+
+	resources    map[string]Resource
+	resourceSubs map[chan []Resource]struct{}
+	prompts      map[string]Prompt
+	promptSubs   map[chan []Prompt]struct{}
+
+	// holding defers notifications while a batch of changes is applied,
+	// held is the tool list subscribers last saw
+	holding bool
+	held    []Tool
 }
 
 func New() *Registry {
 	return &Registry{
-		tools: make(map[string]Tool),
-		subs:  make(map[chan []Tool]struct{}),
+		tools:  make(map[string]Tool),
+		subs:   make(map[chan []Tool]struct{}),
+		order:  make(map[string]uint64),
+		hidden: make(map[string]bool),
+
+		resources:    make(map[string]Resource),
+		resourceSubs: make(map[chan []Resource]struct{}),
+		prompts:      make(map[string]Prompt),
+		promptSubs:   make(map[chan []Prompt]struct{}),
 	}
 }
 
@@ -32,21 +65,60 @@ func (r *Registry) RegisterTools(pluginID string, tools []Tool) {
 	defer r.mu.Unlock()
 	for _, t := range tools {
 		t.PluginID = pluginID
-		r.tools[t.ID] = t
+		key := toolKey(t)
+		r.tools[key] = t
+		if _, ok := r.order[key]; !ok {
+			r.order[key] = r.next
+			r.next++
+		}
 	}
 	r.broadcastLocked()
 }
 
+// Tool returns the tool a plugin registered under id
+func (r *Registry) Tool(pluginID, id string) (Tool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.tools[toolKey(Tool{PluginID: pluginID, ID: id})]
+	return t, ok
+}
+
+// List returns all tools in registration order, tools of one backend are in
+// the order it listed them
 func (r *Registry) List() []Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.sliceLocked()
+}
+
+// All returns the tools of List and those of hidden plugins
+func (r *Registry) All() []Tool {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	out := make([]Tool, 0, len(r.tools))
 	for _, t := range r.tools {
 		out = append(out, t)
 	}
+	r.sortLocked(out)
 	return out
 }
 
+// SetHidden sets whether a plugin's tools are left out of List and change
+// notifications
+func (r *Registry) SetHidden(pluginID string, hidden bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.hidden[pluginID] == hidden {
+		return
+	}
+	if hidden {
+		r.hidden[pluginID] = true
+	} else {
+		delete(r.hidden, pluginID)
+	}
+	r.broadcastLocked()
+}
+
 func (r *Registry) Subscribe() chan []Tool {
 	ch := make(chan []Tool, 1)
 	r.mu.Lock()
@@ -67,12 +139,24 @@ func (r *Registry) Unsubscribe(ch chan []Tool) {
 func (r *Registry) sliceLocked() []Tool {
 	out := make([]Tool, 0, len(r.tools))
 	for _, t := range r.tools {
-		out = append(out, t)
+		if !r.hidden[t.PluginID] {
+			out = append(out, t)
+		}
 	}
+	r.sortLocked(out)
 	return out
 }
 
+func (r *Registry) sortLocked(tools []Tool) {
+	sort.Slice(tools, func(i, j int) bool {
+		return r.order[toolKey(tools[i])] < r.order[toolKey(tools[j])]
+	})
+}
+
 func (r *Registry) broadcastLocked() {
+	if r.holding {
+		return
+	}
 	snapshot := r.sliceLocked()
 	for ch := range r.subs {
 		// best effort non-blocking
@@ -83,6 +167,42 @@ func (r *Registry) broadcastLocked() {
 	}
 }
 
+// Hold defers change notifications until Release, so subscribers see a
+// batch of changes as a single snapshot instead of each step of it
+func (r *Registry) Hold() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.holding {
+		r.holding = true
+		r.held = r.sliceLocked()
+	}
+}
+
+// Release ends a Hold and notifies subscribers of the current state
+func (r *Registry) Release() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.holding {
+		return
+	}
+	r.holding = false
+	r.held = nil
+	r.broadcastLocked()
+	r.broadcastResourcesLocked()
+	r.broadcastPromptsLocked()
+}
+
+// Published returns the tools subscribers were last notified of, which
+// differs from List while changes are held
+func (r *Registry) Published() []Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.holding {
+		return r.held
+	}
+	return r.sliceLocked()
+}
+
 // MarshalJSON returns JSON representation of tools
 func (r *Registry) MarshalJSON() ([]byte, error) {
 	return json.Marshal(r.List())
@@ -92,10 +212,15 @@ func (r *Registry) MarshalJSON() ([]byte, error) {
 func (r *Registry) UnregisterTools(pluginID string) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	for id, tool := range r.tools {
+	for key, tool := range r.tools {
 		if tool.PluginID == pluginID {
-			delete(r.tools, id)
+			delete(r.tools, key)
+			delete(r.order, key)
 		}
 	}
 	r.broadcastLocked()
 }
+
+func toolKey(t Tool) string {
+	return t.PluginID + ":" + t.ID
+}
@@ -0,0 +1,221 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/amir-the-h/mcp-hub/internal/plugin"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// addAPIRoutes registers the hub's JSON admin endpoints under /api/
+func addAPIRoutes(mux *http.ServeMux, reg *registry.Registry, pm *plugin.Manager, names *toolNames, logger *slog.Logger) {
+	mux.HandleFunc("GET /api/servers", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, pm.ServerStatuses(), logger)
+	})
+	mux.HandleFunc("GET /api/servers/{name}/initialize", func(w http.ResponseWriter, r *http.Request) {
+		raw, err := pm.InitializeResult(r.PathValue("name"))
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()}, logger)
+			return
+		}
+		writeJSON(w, http.StatusOK, raw, logger)
+	})
+	mux.HandleFunc("GET /api/servers/{name}/drain", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, pm.DrainStatus(r.PathValue("name")), logger)
+	})
+	mux.HandleFunc("POST /api/servers/{name}/drain", drainHandler(pm.Drain, logger))
+	mux.HandleFunc("DELETE /api/servers/{name}/drain", drainHandler(pm.Undrain, logger))
+	mux.HandleFunc("GET /api/drift", func(w http.ResponseWriter, r *http.Request) {
+		report, ok := pm.DriftReport()
+		if !ok {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "no drift check has run"}, logger)
+			return
+		}
+		writeJSON(w, http.StatusOK, report, logger)
+	})
+	mux.HandleFunc("GET /api/events", eventsHandler(pm, logger))
+	mux.HandleFunc("GET /api/tools", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, listTools(reg, names, httpIdentity(r)), logger)
+	})
+	mux.HandleFunc("POST /api/tools/{plugin}/{tool}", toolCallHandler(pm, logger))
+}
+
+// apiTool is a tool as listed by GET /api/tools
+type apiTool struct {
+	Name        string `json:"name"`   // as MCP clients see it
+	Server      string `json:"server"` // for POST /api/tools/{server}/{tool}
+	Tool        string `json:"tool"`
+	Description string `json:"description,omitempty"`
+	InputSchema any    `json:"inputSchema"`
+	// Members of a server group able to run the tool
+	Backends []string `json:"backends,omitempty"`
+}
+
+// listTools returns the registered tools the client may call with the input
+// schema MCP clients get, sorted by name
+func listTools(reg *registry.Registry, names *toolNames, id *plugin.Identity) []apiTool {
+	out := []apiTool{}
+	for _, t := range reg.List() {
+		if id != nil && !id.Allows(t.PluginID, t.Name) {
+			continue
+		}
+		out = append(out, apiTool{
+			Name:        names.name(t.PluginID, t.Name),
+			Server:      t.PluginID,
+			Tool:        t.Name,
+			Description: t.Description,
+			InputSchema: inputSchema(t),
+			Backends:    t.Backends,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// maxToolCallBody bounds the arguments accepted by the tool call endpoint
+const maxToolCallBody = 16 << 20
+
+// toolCallHandler calls a tool with the JSON arguments in the request body,
+// for scripts without an MCP client. It answers with the tool result, which
+// has isError set if the tool reported failure, or with an error and a
+// status telling why the call didn't produce a result.
+func toolCallHandler(pm *plugin.Manager, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pluginID, toolName := r.PathValue("plugin"), r.PathValue("tool")
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxToolCallBody))
+		if err != nil {
+			writeJSON(w, http.StatusRequestEntityTooLarge, map[string]string{"error": err.Error()}, logger)
+			return
+		}
+		args := json.RawMessage(bytes.TrimSpace(body))
+		if len(args) == 0 {
+			args = json.RawMessage("{}")
+		}
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(args, &obj); err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "arguments must be a JSON object"}, logger)
+			return
+		}
+
+		// Same context as an MCP call: the caller's trace and access policy
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		if id := httpIdentity(r); id != nil {
+			ctx = plugin.WithIdentity(ctx, id)
+		}
+
+		respBytes, err := pm.Execute(ctx, pluginID, toolName, args)
+		if err != nil {
+			data, ok := plugin.ToolErrorResult(err)
+			if !ok {
+				logger.Info("api:call-fail", "plugin", pluginID, "tool", toolName, "err", err)
+				if wait, ok := plugin.RetryAfter(err); ok {
+					w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				}
+				writeJSON(w, callErrorStatus(ctx, err), map[string]string{"error": err.Error()}, logger)
+				return
+			}
+			respBytes = data
+		}
+		writeJSON(w, http.StatusOK, decodeToolResult(respBytes), logger)
+	}
+}
+
+// callErrorStatus maps a failed tool call to an HTTP status
+func callErrorStatus(ctx context.Context, err error) int {
+	switch {
+	case errors.Is(err, plugin.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, plugin.ErrQueueFull), errors.Is(err, plugin.ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case ctx.Err() != nil:
+		// The client went away, nobody reads the status
+		return http.StatusRequestTimeout
+	}
+	switch plugin.FailureKind(err) {
+	case "unavailable":
+		return http.StatusServiceUnavailable
+	case "error":
+		return http.StatusBadGateway
+	}
+	if _, ok := plugin.BackendError(err); ok {
+		return http.StatusBadGateway
+	}
+	// Rejected by the hub, e.g. too large, invalid or not exposed
+	return http.StatusBadRequest
+}
+
+// eventsHandler streams server lifecycle events as Server-Sent Events, one
+// JSON event per data line, until the client goes away. Events a slow client
+// doesn't read in time are dropped.
+func eventsHandler(pm *plugin.Manager, logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		events := pm.SubscribeEvents()
+		defer pm.UnsubscribeEvents(events)
+
+		rc := http.NewResponseController(w)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		if err := rc.Flush(); err != nil {
+			logger.Warn("api:events-fail", "err", err)
+			return
+		}
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case ev, ok := <-events:
+				if !ok {
+					return
+				}
+				data, err := json.Marshal(ev)
+				if err != nil {
+					logger.Warn("api:events-fail", "err", err)
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+					return
+				}
+				if err := rc.Flush(); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// drainHandler answers a drain or undrain request with the resulting state
+func drainHandler(set func(name string) (plugin.DrainStatus, error), logger *slog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		status, err := set(r.PathValue("name"))
+		if err != nil {
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()}, logger)
+			return
+		}
+		writeJSON(w, http.StatusOK, status, logger)
+	}
+}
+
+// writeJSON writes v as the JSON response body
+func writeJSON(w http.ResponseWriter, code int, v any, logger *slog.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		logger.Warn("api:write-fail", "err", err)
+	}
+}
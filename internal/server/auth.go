@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/plugin"
+	"github.com/modelcontextprotocol/go-sdk/auth"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// identityExtraKey holds the *plugin.Identity in auth.TokenInfo.Extra
+const identityExtraKey = "mcp-hub/identity"
+
+// withClientAuth requires the bearer token of a configured client or one of
+// the API keys on every request and attaches the client's identity for the
+// tool handlers. API keys carry no identity, so they may call any tool.
+func withClientAuth(clients map[string]config.ClientConfig, apiKeys []string, h http.Handler) http.Handler {
+	if len(clients) == 0 && len(apiKeys) == 0 {
+		return h
+	}
+
+	identities := make(map[string]*plugin.Identity, len(clients)+len(apiKeys))
+	for name, client := range clients {
+		identities[client.Token] = &plugin.Identity{Name: name, Allow: client.Allow}
+	}
+	for _, key := range apiKeys {
+		identities[key] = nil
+	}
+
+	verify := func(ctx context.Context, token string, req *http.Request) (*auth.TokenInfo, error) {
+		// Compare against every token so timing doesn't reveal a match
+		var match *plugin.Identity
+		found := false
+		for t, id := range identities {
+			if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+				match, found = id, true
+			}
+		}
+		if !found {
+			return nil, auth.ErrInvalidToken
+		}
+		info := &auth.TokenInfo{
+			// Static tokens don't expire, the SDK requires an expiration
+			Expiration: time.Now().Add(time.Hour),
+		}
+		if match != nil {
+			info.Extra = map[string]any{identityExtraKey: match}
+		}
+		return info, nil
+	}
+	return auth.RequireBearerToken(verify, nil)(h)
+}
+
+// requestIdentity returns the authenticated client of a request, if any
+func requestIdentity(req mcp.Request) *plugin.Identity {
+	extra := req.GetExtra()
+	if extra == nil {
+		return nil
+	}
+	return tokenIdentity(extra.TokenInfo)
+}
+
+// httpIdentity returns the authenticated client of a plain HTTP request
+func httpIdentity(r *http.Request) *plugin.Identity {
+	return tokenIdentity(auth.TokenInfoFromContext(r.Context()))
+}
+
+func tokenIdentity(info *auth.TokenInfo) *plugin.Identity {
+	if info == nil {
+		return nil
+	}
+	id, _ := info.Extra[identityExtraKey].(*plugin.Identity)
+	return id
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// bearerTransport authenticates every request with token
+type bearerTransport string
+
+func (token bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+string(token))
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestClientAccessPolicy(t *testing.T) {
+	reg := registry.New()
+	pm := newTestManager(reg)
+	startBackend(t, pm, "github", "search")
+	startBackend(t, pm, "files", "read", "write")
+	hub := newHub(reg, pm, WithClients(map[string]config.ClientConfig{
+		"alice": {Token: "alice-token", Allow: []string{"github:*"}},
+		"bob":   {Token: "bob-token", Allow: []string{"files:read"}},
+	}))
+	sessions := map[string]*mcp.ClientSession{
+		"alice": connectWith(t, hub, "", &http.Client{Transport: bearerTransport("alice-token")}),
+		"bob":   connectWith(t, hub, "", &http.Client{Transport: bearerTransport("bob-token")}),
+	}
+	waitForTools(t, sessions["alice"], 3)
+
+	tests := []struct {
+		client, tool string
+		allowed      bool
+	}{
+		{"alice", "github:search", true},
+		{"alice", "files:read", false},
+		{"bob", "files:read", true},
+		{"bob", "files:write", false},
+		{"bob", "github:search", false},
+	}
+	for _, tt := range tests {
+		res, err := sessions[tt.client].CallTool(context.Background(), &mcp.CallToolParams{Name: tt.tool})
+		if tt.allowed {
+			if err != nil || res.IsError {
+				t.Errorf("%s calling %s: err %v, result %+v", tt.client, tt.tool, err, res)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), "forbidden") {
+			t.Errorf("%s calling %s: err = %v, want forbidden", tt.client, tt.tool, err)
+		}
+	}
+
+	// Unknown tokens are turned away before reaching the MCP endpoint
+	srv := httptest.NewServer(hub)
+	defer srv.Close()
+	req, _ := http.NewRequest(http.MethodPost, srv.URL, strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	resp, err := (&http.Client{Transport: bearerTransport("stolen")}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("unknown token answered %d, want 401", resp.StatusCode)
+	}
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+)
+
+func TestBasePath(t *testing.T) {
+	reg := registry.New()
+	reg.RegisterTools("github", []registry.Tool{{ID: "search", Name: "search"}})
+	hub := newTestHub(reg, WithBasePath("/mcp-hub/"))
+
+	// The MCP endpoint is served under the prefix
+	waitForTools(t, connect(t, hub, "/mcp-hub/"), 1)
+
+	srv := httptest.NewServer(hub)
+	defer srv.Close()
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/mcp-hub/healthz", http.StatusOK},
+		{"/mcp-hub/metrics", http.StatusOK},
+		{"/mcp-hub/api/tools", http.StatusOK},
+		{"/healthz", http.StatusNotFound},
+		{"/metrics", http.StatusNotFound},
+		{"/api/tools", http.StatusNotFound},
+		{"/", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		resp, err := http.Get(srv.URL + tt.path)
+		if err != nil {
+			t.Fatalf("GET %s: %v", tt.path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("GET %s = %d, want %d", tt.path, resp.StatusCode, tt.want)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/plugin"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// swappableBackend serves an MCP server over streamable HTTP that tests can
+// replace. Replacing it drops every session, like a restarted backend.
+type swappableBackend struct {
+	mu      sync.Mutex
+	handler http.Handler
+}
+
+func (b *swappableBackend) set(server *mcp.Server) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handler = mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil)
+}
+
+func (b *swappableBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	h := b.handler
+	b.mu.Unlock()
+	h.ServeHTTP(w, r)
+}
+
+func TestCapabilityGainedOnReconnect(t *testing.T) {
+	backend := &swappableBackend{}
+	backend.set(toolServer("auth", "whoami"))
+	srv := httptest.NewServer(backend)
+	t.Cleanup(func() {
+		srv.CloseClientConnections()
+		srv.Close()
+	})
+
+	reg := registry.New()
+	pm := newTestManager(reg)
+	events := pm.SubscribeEvents()
+	defer pm.UnsubscribeEvents(events)
+	startServer(t, pm, "auth", config.ServerConfig{Type: "http", URL: srv.URL, RestartBaseDelay: 1})
+	hub := newHub(reg, pm, WithCapabilities("backends"))
+
+	before := connect(t, hub, "")
+	if caps := before.InitializeResult().Capabilities; caps.Prompts != nil {
+		t.Fatalf("prompts advertised before any backend has them: %+v", caps.Prompts)
+	}
+
+	// After authenticating the backend comes back with prompts
+	gained := toolServer("auth", "whoami")
+	gained.AddPrompt(&mcp.Prompt{Name: "review"}, func(context.Context, *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return &mcp.GetPromptResult{Messages: []*mcp.PromptMessage{{Role: "user", Content: &mcp.TextContent{Text: "review"}}}}, nil
+	})
+	backend.set(gained)
+	srv.CloseClientConnections()
+
+	var ev plugin.Event
+	timeout := time.After(10 * time.Second)
+	for ev.Type != plugin.EventCapabilitiesChanged {
+		select {
+		case ev = <-events:
+		case <-timeout:
+			t.Fatal("no capabilities_changed event")
+		}
+	}
+	if ev.Server != "auth" || ev.Details["from"] != "logging,tools" || ev.Details["to"] != "logging,prompts,tools" {
+		t.Errorf("capabilities_changed event = %+v", ev)
+	}
+	if got := pm.Capabilities("auth"); !slices.Contains(got, "prompts") {
+		t.Errorf("capabilities after reconnect = %v", got)
+	}
+
+	// Clients connecting from now on see the prompt and the capability
+	var after *mcp.ClientSession
+	var names []string
+	for deadline := time.Now().Add(5 * time.Second); !slices.Contains(names, "auth:review") && time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		after = connect(t, hub, "")
+		if after.InitializeResult().Capabilities.Prompts == nil {
+			continue
+		}
+		res, err := after.ListPrompts(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = names[:0]
+		for _, p := range res.Prompts {
+			names = append(names, p.Name)
+		}
+	}
+	if !slices.Contains(names, "auth:review") {
+		t.Fatalf("prompts after reconnect = %v, want auth:review", names)
+	}
+	res, err := after.GetPrompt(context.Background(), &mcp.GetPromptParams{Name: "auth:review"})
+	if err != nil {
+		t.Fatalf("get auth:review: %v", err)
+	}
+	if got := res.Messages[0].Content.(*mcp.TextContent).Text; got != "review" {
+		t.Errorf("auth:review rendered %q", got)
+	}
+}
@@ -0,0 +1,152 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"reflect"
+
+	"github.com/amir-the-h/mcp-hub/internal/plugin"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// syncPrompts mirrors registry prompt snapshots onto the SDK server until ch
+// is closed. Prompts are named <plugin>:<prompt> like tools.
+func syncPrompts(s *mcp.Server, pm *plugin.Manager, ch chan []registry.Prompt) {
+	registered := make(map[string]registry.Prompt)
+	for snapshot := range ch {
+		desired := make(map[string]struct{}, len(snapshot))
+		for _, p := range snapshot {
+			namespaced := p.PluginID + ":" + p.Name
+			desired[namespaced] = struct{}{}
+			if prev, ok := registered[namespaced]; ok && reflect.DeepEqual(prev, p) {
+				continue
+			}
+
+			prompt := &mcp.Prompt{Name: namespaced, Description: p.Description}
+			for _, arg := range p.Arguments {
+				prompt.Arguments = append(prompt.Arguments, &mcp.PromptArgument{
+					Name:        arg.Name,
+					Description: arg.Description,
+					Required:    arg.Required,
+				})
+			}
+			s.AddPrompt(prompt, promptHandler(pm, p.PluginID, p.Name))
+			registered[namespaced] = p
+		}
+
+		var stale []string
+		for name := range registered {
+			if _, ok := desired[name]; !ok {
+				stale = append(stale, name)
+				delete(registered, name)
+			}
+		}
+		if len(stale) > 0 {
+			s.RemovePrompts(stale...)
+		}
+	}
+}
+
+// promptHandler routes prompts/get to the backend owning the prompt
+func promptHandler(pm *plugin.Manager, pluginID, name string) mcp.PromptHandler {
+	return func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		if id := requestIdentity(req); id != nil {
+			ctx = plugin.WithIdentity(ctx, id)
+		}
+		return pm.GetPrompt(ctx, pluginID, name, req.Params.Arguments)
+	}
+}
+
+// syncResources mirrors registry resource snapshots onto the SDK server until
+// ch is closed. Resources keep their backend URI so clients can resolve them,
+// their names are namespaced as <plugin>:<name>. A URI is owned by the first
+// backend exposing it, and hub:// URIs are reserved for the hub itself.
+func syncResources(s *mcp.Server, pm *plugin.Manager, ch chan []registry.Resource, logger *slog.Logger) {
+	registered := make(map[string]registry.Resource) // by URI
+	for snapshot := range ch {
+		desired := make(map[string]registry.Resource, len(snapshot))
+		for _, r := range snapshot {
+			if isHubURI(r.URI) {
+				logger.Warn("resource:skip", "plugin", r.PluginID, "uri", r.URI, "reason", "reserved scheme")
+				continue
+			}
+			if owner, ok := desired[r.URI]; ok {
+				if owner == r {
+					continue
+				}
+				logger.Warn("resource:skip", "plugin", r.PluginID, "uri", r.URI, "reason", "exposed by "+owner.PluginID)
+				continue
+			}
+			if prev, ok := registered[r.URI]; ok && prev.PluginID != r.PluginID && inSnapshot(snapshot, prev) {
+				// keep the current owner even if it sorts later
+				desired[r.URI] = prev
+				logger.Warn("resource:skip", "plugin", r.PluginID, "uri", r.URI, "reason", "exposed by "+prev.PluginID)
+				continue
+			}
+			desired[r.URI] = r
+		}
+
+		for uri, r := range desired {
+			if prev, ok := registered[uri]; ok && prev == r {
+				continue
+			}
+			if err := addResource(s, r, pm); err != nil {
+				logger.Warn("resource:add-fail", "plugin", r.PluginID, "uri", uri, "err", err)
+				continue
+			}
+			registered[uri] = r
+		}
+
+		var stale []string
+		for uri := range registered {
+			if _, ok := desired[uri]; !ok {
+				stale = append(stale, uri)
+				delete(registered, uri)
+			}
+		}
+		if len(stale) > 0 {
+			s.RemoveResources(stale...)
+		}
+	}
+}
+
+// inSnapshot reports whether r is still part of snapshot
+func inSnapshot(snapshot []registry.Resource, r registry.Resource) bool {
+	for _, s := range snapshot {
+		if s == r {
+			return true
+		}
+	}
+	return false
+}
+
+// addResource registers a backend resource on the SDK server, converting the
+// SDK's panic on an unparsable URI into an error
+func addResource(s *mcp.Server, r registry.Resource, pm *plugin.Manager) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%v", p)
+		}
+	}()
+	s.AddResource(&mcp.Resource{
+		URI:         r.URI,
+		Name:        r.PluginID + ":" + r.Name,
+		Description: r.Description,
+		MIMEType:    r.MIMEType,
+	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		if id := requestIdentity(req); id != nil {
+			ctx = plugin.WithIdentity(ctx, id)
+		}
+		return pm.ReadResource(ctx, r.PluginID, r.Name, req.Params.URI)
+	})
+	return nil
+}
+
+// isHubURI reports whether uri uses the scheme reserved for hub resources
+func isHubURI(uri string) bool {
+	u, err := url.Parse(uri)
+	return err == nil && u.Scheme == hubScheme
+}
@@ -0,0 +1,97 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// requestHeader carries the ID under which the hub tracks an incoming POST.
+// The hub sets it on every POST, so a client can't pick another's ID.
+const requestHeader = "X-MCP-Hub-Request"
+
+// errClientDisconnected is the cancellation cause of calls whose client went
+// away before they finished
+var errClientDisconnected = errors.New("client disconnected")
+
+// clientRequests ties tool calls to the HTTP request that carried them. The
+// SDK keeps running a handler after its client disconnected, so without this
+// an abandoned call would keep its backend busy until it finished.
+type clientRequests struct {
+	cancel bool
+
+	mu   sync.Mutex
+	next uint64
+	ctxs map[string]context.Context // by requestHeader value
+}
+
+// newClientRequests applies the clientDisconnect policy: "cancel" (default)
+// or "continue"
+func newClientRequests(policy string) *clientRequests {
+	return &clientRequests{cancel: policy != "continue", ctxs: make(map[string]context.Context)}
+}
+
+// track records the context of every POST while it is being served
+func (c *clientRequests) track(h http.Handler) http.Handler {
+	if !c.cancel {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		c.mu.Lock()
+		c.next++
+		id := strconv.FormatUint(c.next, 10)
+		c.ctxs[id] = r.Context()
+		c.mu.Unlock()
+		defer func() {
+			c.mu.Lock()
+			delete(c.ctxs, id)
+			c.mu.Unlock()
+		}()
+
+		r.Header.Set(requestHeader, id)
+		h.ServeHTTP(w, r)
+	})
+}
+
+// bind returns a context for the call in req that is cancelled once the
+// client's HTTP request ends. release must be called when the call returns.
+// Calls not carried by a tracked request are left alone.
+func (c *clientRequests) bind(ctx context.Context, req *mcp.CallToolRequest) (context.Context, func()) {
+	if !c.cancel || req.Extra == nil || req.Extra.Header == nil {
+		return ctx, func() {}
+	}
+	id := req.Extra.Header.Get(requestHeader)
+	if id == "" {
+		return ctx, func() {}
+	}
+
+	callCtx, cancel := context.WithCancelCause(ctx)
+	c.mu.Lock()
+	reqCtx, ok := c.ctxs[id]
+	c.mu.Unlock()
+	if !ok {
+		// The request ended before the call started
+		cancel(errClientDisconnected)
+		return callCtx, func() { cancel(nil) }
+	}
+
+	stop := context.AfterFunc(reqCtx, func() { cancel(errClientDisconnected) })
+	return callCtx, func() {
+		stop()
+		cancel(nil)
+	}
+}
+
+// abandoned reports whether ctx was cancelled because its client went away
+func abandoned(ctx context.Context) bool {
+	return errors.Is(context.Cause(ctx), errClientDisconnected)
+}
@@ -0,0 +1,83 @@
+package server
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// buildServer has a tool "build" that runs until release is closed or its
+// call is cancelled, reporting which of them ended it on done
+func buildServer(started chan<- struct{}, release <-chan struct{}, done chan<- string) *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "ci"}, nil)
+	server.AddTool(&mcp.Tool{Name: "build", InputSchema: map[string]any{"type": "object"}}, func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		started <- struct{}{}
+		select {
+		case <-ctx.Done():
+			done <- "cancelled"
+		case <-release:
+			done <- "finished"
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "built"}}}, nil
+	})
+	return server
+}
+
+func TestClientDisconnect(t *testing.T) {
+	tests := []struct {
+		policy string
+		want   string
+	}{
+		{"", "cancelled"},
+		{"continue", "finished"},
+	}
+	for _, tt := range tests {
+		t.Run("policy "+tt.policy, func(t *testing.T) {
+			started := make(chan struct{}, 1)
+			release := make(chan struct{})
+			done := make(chan string, 1)
+			reg := registry.New()
+			pm := newTestManager(reg)
+			startServer(t, pm, "ci", config.ServerConfig{Type: "http", URL: serveBackend(t, buildServer(started, release, done))})
+
+			srv := httptest.NewServer(newHub(reg, pm, WithClientDisconnect(tt.policy)))
+			t.Cleanup(srv.Close)
+			client := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil)
+			session, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{Endpoint: srv.URL}, nil)
+			if err != nil {
+				t.Fatalf("connect: %v", err)
+			}
+			defer session.Close()
+			waitForTools(t, session, 1)
+
+			go session.CallTool(context.Background(), &mcp.CallToolParams{Name: "ci:build"})
+			select {
+			case <-started:
+			case <-time.After(5 * time.Second):
+				t.Fatal("build never started")
+			}
+
+			// The client goes away mid-call
+			srv.CloseClientConnections()
+			if tt.want == "finished" {
+				time.Sleep(100 * time.Millisecond)
+				close(release)
+			} else {
+				defer close(release)
+			}
+			select {
+			case got := <-done:
+				if got != tt.want {
+					t.Errorf("backend call %s, want %s", got, tt.want)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("backend call still running")
+			}
+		})
+	}
+}
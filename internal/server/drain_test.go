@@ -0,0 +1,153 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/plugin"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// memberServer has a work tool answering "<name>:work" and, if release is
+// set, a slow tool answering once release is closed
+func memberServer(name string, release chan struct{}) *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: name}, nil)
+	schema := map[string]any{"type": "object"}
+	server.AddTool(&mcp.Tool{Name: "work", InputSchema: schema}, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: name + ":work"}}}, nil
+	})
+	if release != nil {
+		server.AddTool(&mcp.Tool{Name: "slow", InputSchema: schema}, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			<-release
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: name + ":slow"}}}, nil
+		})
+	}
+	return server
+}
+
+// drainRequest sends an admin drain request and returns the resulting state
+func drainRequest(t *testing.T, hub http.Handler, method, name string) plugin.DrainStatus {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	hub.ServeHTTP(rec, httptest.NewRequest(method, "/api/servers/"+name+"/drain", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("%s drain %s: %d %s", method, name, rec.Code, rec.Body)
+	}
+	var status plugin.DrainStatus
+	if err := json.Unmarshal(rec.Body.Bytes(), &status); err != nil {
+		t.Fatal(err)
+	}
+	return status
+}
+
+func TestDrainWithStandby(t *testing.T) {
+	reg := registry.New()
+	pm := newTestManager(reg)
+	release := make(chan struct{})
+	startServer(t, pm, "b", config.ServerConfig{Type: "http", URL: serveBackend(t, memberServer("b", nil))})
+	startServer(t, pm, "a", config.ServerConfig{Type: "http", URL: serveBackend(t, memberServer("a", release)), Standby: "b"})
+	hub := newHub(reg, pm)
+	session := connect(t, hub, "")
+	waitForTools(t, session, 3)
+
+	served := func() map[string]int {
+		counts := make(map[string]int)
+		for range 4 {
+			counts[callTool(t, session, "a:work")]++
+		}
+		return counts
+	}
+	if counts := served(); counts["a:work"] != 4 {
+		t.Fatalf("calls went to %v, want only a", counts)
+	}
+
+	// A call running on a when it is drained still finishes
+	slow := make(chan *mcp.CallToolResult, 1)
+	go func() {
+		res, _ := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "a:slow"})
+		slow <- res
+	}()
+	for deadline := time.Now().Add(5 * time.Second); pm.DrainStatus("a").InFlight == 0; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("slow call never reached a")
+		}
+	}
+	if st := drainRequest(t, hub, http.MethodPost, "a"); !st.Drained || st.InFlight != 1 {
+		t.Errorf("drain status = %+v, want drained with the slow call in flight", st)
+	}
+
+	if counts := served(); counts["b:work"] != 4 {
+		t.Errorf("calls went to %v while a is drained, want only the standby b", counts)
+	}
+	close(release)
+	if res := <-slow; res == nil || res.IsError || res.Content[0].(*mcp.TextContent).Text != "a:slow" {
+		t.Errorf("in-flight call answered %+v", res)
+	}
+
+	if st := drainRequest(t, hub, http.MethodDelete, "a"); st.Drained {
+		t.Errorf("undrain status = %+v", st)
+	}
+	if counts := served(); counts["a:work"] != 4 {
+		t.Errorf("calls went to %v after undraining, want a back in rotation", counts)
+	}
+}
+
+func TestDrainGroupMember(t *testing.T) {
+	reg := registry.New()
+	pm := newTestManager(reg)
+	release := make(chan struct{})
+	startServer(t, pm, "a", config.ServerConfig{Type: "http", URL: serveBackend(t, memberServer("a", release)), Group: "pool"})
+	startServer(t, pm, "b", config.ServerConfig{Type: "http", URL: serveBackend(t, memberServer("b", nil)), Group: "pool"})
+	hub := newHub(reg, pm)
+	session := connect(t, hub, "")
+	// Members are only reachable through their group
+	waitForTools(t, session, 2)
+
+	// Both members serve the group
+	served := func() map[string]int {
+		counts := make(map[string]int)
+		for range 4 {
+			counts[callTool(t, session, "pool:work")]++
+		}
+		return counts
+	}
+	if counts := served(); counts["a:work"] == 0 || counts["b:work"] == 0 {
+		t.Fatalf("calls went to %v, want both members", counts)
+	}
+
+	// A call running on a when it is drained still finishes
+	slow := make(chan *mcp.CallToolResult, 1)
+	go func() {
+		res, _ := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "pool:slow"})
+		slow <- res
+	}()
+	for deadline := time.Now().Add(5 * time.Second); pm.DrainStatus("a").InFlight == 0; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("slow call never reached a")
+		}
+	}
+	if st := drainRequest(t, hub, http.MethodPost, "a"); !st.Drained || st.InFlight != 1 {
+		t.Errorf("drain status = %+v, want drained with the slow call in flight", st)
+	}
+
+	if counts := served(); counts["b:work"] != 4 {
+		t.Errorf("calls went to %v while a is drained, want only b", counts)
+	}
+	close(release)
+	if res := <-slow; res == nil || res.IsError || res.Content[0].(*mcp.TextContent).Text != "a:slow" {
+		t.Errorf("in-flight call answered %+v", res)
+	}
+
+	if st := drainRequest(t, hub, http.MethodDelete, "a"); st.Drained {
+		t.Errorf("undrain status = %+v", st)
+	}
+	if counts := served(); counts["a:work"] == 0 {
+		t.Errorf("calls went to %v after undraining, want a back in rotation", counts)
+	}
+}
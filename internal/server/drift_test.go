@@ -0,0 +1,43 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/plugin"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+)
+
+func TestDriftAPI(t *testing.T) {
+	reg := registry.New()
+	pm := newTestManager(reg)
+	hub := newHub(reg, pm)
+
+	rec := httptest.NewRecorder()
+	hub.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/drift", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("before any check: status %d, want 404", rec.Code)
+	}
+
+	want := plugin.DriftReport{
+		CheckedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Drift:     []plugin.Drift{{Server: "flaky", Kind: "gave_up"}, {Server: "github", Kind: "missing"}},
+	}
+	pm.SetDriftReport(want)
+	rec = httptest.NewRecorder()
+	hub.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/drift", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var got plugin.DriftReport
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body, err)
+	}
+	if !got.CheckedAt.Equal(want.CheckedAt) || !slices.Equal(got.Drift, want.Drift) || got.Repaired {
+		t.Errorf("report = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/plugin"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+)
+
+func TestEventsAPI(t *testing.T) {
+	reg := registry.New()
+	pm := newTestManager(reg)
+	srv := httptest.NewServer(newHub(reg, pm))
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/api/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); resp.StatusCode != http.StatusOK || ct != "text/event-stream" {
+		t.Fatalf("status %d, content type %q", resp.StatusCode, ct)
+	}
+
+	// The stream is subscribed once the headers are sent
+	startBackend(t, pm, "github", "search")
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var ev plugin.Event
+		if err := json.Unmarshal([]byte(data), &ev); err != nil {
+			t.Fatalf("decode %s: %v", data, err)
+		}
+		if ev.Type == plugin.EventStarted {
+			if ev.Server != "github" {
+				t.Errorf("started event for %q, want github", ev.Server)
+			}
+			return
+		}
+	}
+	t.Fatalf("stream ended without a started event: %v", scanner.Err())
+}
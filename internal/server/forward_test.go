@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// quotaServer answers every tools/call with a JSON-RPC error of its own code
+// and data
+func quotaServer(t *testing.T) *mcp.Server {
+	msg, err := jsonrpc.DecodeMessage([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32042,"message":"quota exceeded","data":{"retryIn":30}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	quotaErr := msg.(*jsonrpc.Response).Error
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "quota"}, nil)
+	server.AddTool(&mcp.Tool{Name: "search", InputSchema: map[string]any{"type": "object"}}, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{}, nil
+	})
+	server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method == "tools/call" {
+				return nil, quotaErr
+			}
+			return next(ctx, method, req)
+		}
+	})
+	return server
+}
+
+func TestForwardErrors(t *testing.T) {
+	for _, forward := range []bool{false, true} {
+		reg := registry.New()
+		pm := newTestManager(reg)
+		startServer(t, pm, "quota", config.ServerConfig{Type: "http", URL: serveBackend(t, quotaServer(t)), ForwardErrors: forward})
+		session := connect(t, newHub(reg, pm), "")
+		waitForTools(t, session, 1)
+
+		_, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "quota:search"})
+		if err == nil {
+			t.Fatalf("forwardErrors %v: call succeeded", forward)
+		}
+		code, data := wireError(err)
+		if got := code == -32042 && string(data) == `{"retryIn":30}`; got != forward {
+			t.Errorf("forwardErrors %v: client got code %d and data %s", forward, code, data)
+		}
+	}
+}
+
+// wireError returns the code and data of the JSON-RPC error in err's chain,
+// which the SDK only exposes through its wire form
+func wireError(err error) (int64, json.RawMessage) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		var wire struct {
+			Code    int64           `json:"code"`
+			Message string          `json:"message"`
+			Data    json.RawMessage `json:"data"`
+		}
+		data, merr := json.Marshal(err)
+		if merr == nil && json.Unmarshal(data, &wire) == nil && wire.Message != "" {
+			return wire.Code, wire.Data
+		}
+	}
+	return 0, nil
+}
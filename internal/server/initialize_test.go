@@ -0,0 +1,88 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// vendorResult is the initialize result of vendorBackend, with fields the
+// protocol doesn't define
+const vendorResult = `{
+	"protocolVersion": %q,
+	"capabilities": {"tools": {}, "experimental": {"batching": {"max": 8}}},
+	"serverInfo": {"name": "vendor", "version": "2.0", "build": "a1b2c3"},
+	"vendorExtension": {"region": "eu-west-1"}
+}`
+
+// vendorBackend answers initialize with vendorResult and serves the rest
+// statelessly with a tool "lookup"
+func vendorBackend(t *testing.T) string {
+	t.Helper()
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return toolServer("vendor", "lookup") }, &mcp.StreamableHTTPOptions{Stateless: true})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params struct {
+				ProtocolVersion string `json:"protocolVersion"`
+			} `json:"params"`
+		}
+		if json.Unmarshal(body, &req) == nil && req.Method == "initialize" {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":`+vendorResult+`}`, req.ID, req.Params.ProtocolVersion)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(func() {
+		srv.CloseClientConnections()
+		srv.Close()
+	})
+	return srv.URL
+}
+
+func TestInitializeResultAPI(t *testing.T) {
+	reg := registry.New()
+	pm := newTestManager(reg)
+	startServer(t, pm, "vendor", config.ServerConfig{Type: "http", URL: vendorBackend(t)})
+	hub := newHub(reg, pm)
+
+	rec := httptest.NewRecorder()
+	hub.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/servers/vendor/initialize", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var got, want map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body, err)
+	}
+	version, _ := got["protocolVersion"].(string)
+	if version == "" {
+		t.Fatalf("no protocol version in %s", rec.Body)
+	}
+	if err := json.Unmarshal([]byte(fmt.Sprintf(vendorResult, version)), &want); err != nil {
+		t.Fatal(err)
+	}
+	// Unknown fields are kept as the backend sent them
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("initialize result = %s, want the backend's %v", rec.Body, want)
+	}
+
+	rec = httptest.NewRecorder()
+	hub.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/servers/missing/initialize", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown server: status %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/amir-the-h/mcp-hub/internal/plugin"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// usageCounter counts calls per namespaced tool for the "usage" selection
+type usageCounter struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func newUsageCounter() *usageCounter {
+	return &usageCounter{counts: make(map[string]int64)}
+}
+
+func (u *usageCounter) record(name string) {
+	u.mu.Lock()
+	u.counts[name]++
+	u.mu.Unlock()
+}
+
+func (u *usageCounter) get(name string) int64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.counts[name]
+}
+
+// toolCapMiddleware trims tools/list results to the configured maximum. Tools
+// left out stay registered and can still be called by name. Requests for
+// which exempt returns true see the full list.
+func toolCapMiddleware(pm *plugin.Manager, names *toolNames, usage *usageCounter, o *options, exempt func(mcp.Request) bool) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			res, err := next(ctx, method, req)
+			if err != nil || method != "tools/list" || o.maxTools <= 0 || exempt(req) {
+				return res, err
+			}
+			list, ok := res.(*mcp.ListToolsResult)
+			if !ok || len(list.Tools) <= o.maxTools {
+				return res, err
+			}
+
+			tools := append([]*mcp.Tool(nil), list.Tools...)
+			switch o.toolSelection {
+			case "priority":
+				owners := names.owners()
+				sort.SliceStable(tools, func(i, j int) bool {
+					return toolPriority(pm, owners[tools[i].Name]) > toolPriority(pm, owners[tools[j].Name])
+				})
+			case "usage":
+				sort.SliceStable(tools, func(i, j int) bool {
+					return usage.get(tools[i].Name) > usage.get(tools[j].Name)
+				})
+			}
+
+			capped := *list
+			capped.Tools = tools[:o.maxTools]
+			capped.NextCursor = ""
+			return &capped, nil
+		}
+	}
+}
+
+// toolPriority returns the configured priority of a server, 0 for tools
+// without one
+func toolPriority(pm *plugin.Manager, pluginID string) int {
+	if srv, ok := pm.GetServer(pluginID); ok {
+		return srv.Priority()
+	}
+	return 0
+}
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+)
+
+func TestMaxTools(t *testing.T) {
+	reg := registry.New()
+	pm := newTestManager(reg)
+	startBackend(t, pm, "files", "delete", "read", "write")
+	session := connect(t, newHub(reg, pm, WithMaxTools(2, "first")), "")
+
+	tools := waitForTools(t, session, 2)
+	for _, name := range []string{"files:delete", "files:read"} {
+		if _, ok := tools[name]; !ok {
+			t.Errorf("%s not listed, got %v", name, keys(tools))
+		}
+	}
+
+	// Tools past the cap are left out of the list only
+	if got := callTool(t, session, "files:write"); got != "files:write" {
+		t.Errorf("files:write answered %q", got)
+	}
+}
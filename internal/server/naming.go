@@ -0,0 +1,87 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/amir-the-h/mcp-hub/internal/plugin"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+)
+
+// defaultToolSeparator joins a server's prefix and a tool name
+const defaultToolSeparator = ":"
+
+// toolNames maps backend tools to the names clients see,
+// <prefix><separator><tool>, where the prefix is the server's toolPrefix or
+// its name. Config references such as fallbackTool always use <server>:<tool>.
+type toolNames struct {
+	pm        *plugin.Manager
+	reg       *registry.Registry
+	separator string
+}
+
+func newToolNames(pm *plugin.Manager, reg *registry.Registry, separator string) *toolNames {
+	if separator == "" {
+		separator = defaultToolSeparator
+	}
+	return &toolNames{pm: pm, reg: reg, separator: separator}
+}
+
+// name returns the exposed name of a server's tool
+func (n *toolNames) name(pluginID, toolName string) string {
+	return n.prefix(pluginID) + n.separator + toolName
+}
+
+// prefix returns the prefix of a server's tools
+func (n *toolNames) prefix(pluginID string) string {
+	if srv, ok := n.pm.GetServer(pluginID); ok {
+		if p := srv.Config().ToolPrefix; p != "" {
+			return p
+		}
+	}
+	return pluginID
+}
+
+// parse returns the server and tool behind an exposed name. Names are looked
+// up rather than split since the separator may occur in prefixes and tool
+// names.
+func (n *toolNames) parse(name string) (pluginID, toolName string, ok bool) {
+	for _, t := range n.reg.List() {
+		if n.name(t.PluginID, t.Name) == name {
+			return t.PluginID, t.Name, true
+		}
+	}
+	return "", "", false
+}
+
+// prefixed reports whether name is meant to carry a server prefix rather
+// than be a bare tool name: it contains the separator and no backend has a
+// tool of that name
+func (n *toolNames) prefixed(name string) bool {
+	if !strings.Contains(name, n.separator) {
+		return false
+	}
+	for _, t := range n.reg.List() {
+		if t.Name == name {
+			return false
+		}
+	}
+	return true
+}
+
+// owners maps the exposed name of every registered tool to its server
+func (n *toolNames) owners() map[string]string {
+	owners := make(map[string]string)
+	for _, t := range n.reg.List() {
+		owners[n.name(t.PluginID, t.Name)] = t.PluginID
+	}
+	return owners
+}
+
+// reference returns the exposed name of a <server>:<tool> config reference
+func (n *toolNames) reference(ref string) string {
+	pluginID, toolName, ok := splitNamespaced(ref)
+	if !ok {
+		return ref
+	}
+	return n.name(pluginID, toolName)
+}
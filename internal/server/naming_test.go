@@ -0,0 +1,54 @@
+package server
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+)
+
+func TestSameToolOnTwoServers(t *testing.T) {
+	reg := registry.New()
+	pm := newTestManager(reg)
+	startBackend(t, pm, "github", "search")
+	startBackend(t, pm, "gitlab", "search")
+	session := connect(t, newHub(reg, pm), "")
+
+	if got := len(reg.List()); got != 2 {
+		t.Errorf("registry holds %d tools, want both searches", got)
+	}
+	tools := waitForTools(t, session, 2)
+	for _, name := range []string{"github:search", "gitlab:search"} {
+		if tools[name] == nil {
+			t.Errorf("%s not exposed, got %v", name, keys(tools))
+			continue
+		}
+		if got := callTool(t, session, name); got != name {
+			t.Errorf("%s answered by %s", name, got)
+		}
+	}
+}
+
+func TestToolPrefixAndSeparator(t *testing.T) {
+	reg := registry.New()
+	pm := newTestManager(reg)
+	startServer(t, pm, "github", config.ServerConfig{Type: "http", URL: serveBackend(t, toolServer("github", "search")), ToolPrefix: "vcs"})
+	startServer(t, pm, "gitlab", config.ServerConfig{Type: "http", URL: serveBackend(t, toolServer("gitlab", "search")), ToolPrefix: "vcs"})
+	startBackend(t, pm, "files", "search")
+	session := connect(t, newHub(reg, pm, WithToolSeparator("__")), "")
+
+	// The servers sharing a prefix collide, the first registered keeps the
+	// name
+	tools := keys(waitForTools(t, session, 2))
+	slices.Sort(tools)
+	if want := []string{"files__search", "vcs__search"}; !slices.Equal(tools, want) {
+		t.Fatalf("tools = %v, want %v", tools, want)
+	}
+	if got := callTool(t, session, "vcs__search"); got != "github:search" {
+		t.Errorf("vcs__search answered by %s", got)
+	}
+	if got := callTool(t, session, "files__search"); got != "files:search" {
+		t.Errorf("files__search answered by %s", got)
+	}
+}
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+)
+
+// Option configures the hub HTTP server
+type Option func(*options)
+
+type options struct {
+	unknownTool  string
+	fallbackTool string
+	basePath     string
+
+	maxTools      int
+	toolSelection string
+	toolOrder     string
+
+	toolUpdates string
+
+	clients map[string]config.ClientConfig
+	apiKeys []string
+
+	reconcileInterval time.Duration
+
+	bareToolNames string
+	toolSeparator string
+	capabilities  string
+
+	sessionMaxLifetime time.Duration
+	sessionIdleTimeout time.Duration
+	maxSessions        int
+
+	readiness string
+
+	clientDisconnect string
+
+	logger *slog.Logger
+}
+
+// WithClientDisconnect sets what happens to a tool call whose client
+// disconnected: "cancel" (default) cancels the backend call, "continue" lets
+// it run to completion
+func WithClientDisconnect(policy string) Option {
+	return func(o *options) {
+		o.clientDisconnect = policy
+	}
+}
+
+// WithCapabilities sets which capabilities are advertised to clients:
+// "static" (default) always advertises prompts, "backends" only while a
+// running backend provides some
+func WithCapabilities(mode string) Option {
+	return func(o *options) {
+		o.capabilities = mode
+	}
+}
+
+// WithToolSeparator sets the string joining a server's prefix and its tool
+// names in exposed tool names, default ":"
+func WithToolSeparator(separator string) Option {
+	return func(o *options) {
+		o.toolSeparator = separator
+	}
+}
+
+// WithLogger sets the logger for session, sync and API events, default
+// logging.Default()
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// WithReadiness sets when /readyz reports ready: "any" (default) once one
+// server is connected, "all" once every server meant to run is connected
+func WithReadiness(mode string) Option {
+	return func(o *options) {
+		o.readiness = mode
+	}
+}
+
+// WithSessionLimits bounds client sessions: each is closed maxLifetime
+// after it started or once idle for idleTimeout, and new sessions are
+// refused while maxSessions are open. Zero values disable a limit.
+func WithSessionLimits(maxLifetime, idleTimeout time.Duration, maxSessions int) Option {
+	return func(o *options) {
+		o.sessionMaxLifetime = maxLifetime
+		o.sessionIdleTimeout = idleTimeout
+		o.maxSessions = maxSessions
+	}
+}
+
+// defaultReconcileInterval is how often the exposed tools are checked
+// against the registry unless configured otherwise
+const defaultReconcileInterval = time.Minute
+
+// WithUnknownToolPolicy sets how calls to tools that don't exist are answered:
+// "error" (default) returns the SDK's unknown tool error, "suggest" lists the
+// closest matching tool names, and "fallback" routes the call to fallbackTool
+// with the requested name and arguments
+func WithUnknownToolPolicy(policy, fallbackTool string) Option {
+	return func(o *options) {
+		o.unknownTool = policy
+		o.fallbackTool = fallbackTool
+	}
+}
+
+// WithBasePath mounts every route under prefix (e.g. "/mcp-hub") for
+// deployments behind a path-rewriting reverse proxy
+func WithBasePath(prefix string) Option {
+	return func(o *options) {
+		o.basePath = normalizeBasePath(prefix)
+	}
+}
+
+// normalizeBasePath returns prefix with a leading and no trailing slash,
+// or "" for the root
+func normalizeBasePath(prefix string) string {
+	prefix = strings.Trim(strings.TrimSpace(prefix), "/")
+	if prefix == "" {
+		return ""
+	}
+	return "/" + prefix
+}
+
+// WithMaxTools caps the number of tools returned by tools/list. selection
+// picks which tools are kept: "first" (default, by name), "priority" (by
+// server priority) or "usage" (most called first).
+func WithMaxTools(max int, selection string) Option {
+	return func(o *options) {
+		o.maxTools = max
+		o.toolSelection = selection
+	}
+}
+
+// WithToolOrder sets the order of tools in tools/list: "name" (default,
+// alphabetical), "listed" (servers by name, each server's tools in the
+// order it listed them) or "priority" (like "listed" with servers by
+// priority, higher first)
+func WithToolOrder(order string) Option {
+	return func(o *options) {
+		o.toolOrder = order
+	}
+}
+
+// WithToolUpdates sets how changed definitions of already exposed tools are
+// handled: "update" (default) re-registers them so clients see the current
+// description and schema, "ignore" keeps the first definition
+func WithToolUpdates(policy string) Option {
+	return func(o *options) {
+		o.toolUpdates = policy
+	}
+}
+
+// WithClients requires every request to carry the bearer token of one of
+// clients, and limits each client to the tools its allow patterns match
+func WithClients(clients map[string]config.ClientConfig) Option {
+	return func(o *options) {
+		o.clients = clients
+	}
+}
+
+// WithAPIKeys requires every request to carry one of keys as bearer token,
+// unless it carries a client's token. Keys may call any tool.
+func WithAPIKeys(keys []string) Option {
+	return func(o *options) {
+		o.apiKeys = keys
+	}
+}
+
+// WithReconcileInterval sets how often the tools exposed by the SDK server
+// are compared with the registry and any drift repaired (default one minute)
+func WithReconcileInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.reconcileInterval = d
+	}
+}
+
+// WithBareToolNames sets how calls naming a tool without its <plugin>:
+// namespace are resolved: "single" (default) routes them to the only running
+// server, "search" to the one server exposing a tool of that name, and
+// "strict" rejects them
+func WithBareToolNames(mode string) Option {
+	return func(o *options) {
+		o.bareToolNames = mode
+	}
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"sort"
+
+	"github.com/amir-the-h/mcp-hub/internal/plugin"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// toolOrderMiddleware reorders tools/list results so each server's tools
+// appear in the order the server listed them, instead of the SDK's
+// alphabetical order. It runs before the tool cap, so "first" keeps the
+// first tools in this order.
+func toolOrderMiddleware(reg *registry.Registry, pm *plugin.Manager, names *toolNames, o *options) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			res, err := next(ctx, method, req)
+			if err != nil || method != "tools/list" || (o.toolOrder != "listed" && o.toolOrder != "priority") {
+				return res, err
+			}
+			list, ok := res.(*mcp.ListToolsResult)
+			if !ok {
+				return res, err
+			}
+
+			// Position and server of every tool in registration order
+			position := make(map[string]int)
+			owners := make(map[string]string)
+			for i, t := range reg.List() {
+				name := names.name(t.PluginID, t.Name)
+				position[name] = i
+				owners[name] = t.PluginID
+			}
+
+			tools := append([]*mcp.Tool(nil), list.Tools...)
+			sort.SliceStable(tools, func(i, j int) bool {
+				pi, pj := owners[tools[i].Name], owners[tools[j].Name]
+				if pi != pj {
+					if o.toolOrder == "priority" {
+						if a, b := toolPriority(pm, pi), toolPriority(pm, pj); a != b {
+							return a > b
+						}
+					}
+					return pi < pj
+				}
+				// Tools the registry doesn't know of, e.g. the fallback
+				// tool, keep their place after the listed ones
+				a, aok := position[tools[i].Name]
+				b, bok := position[tools[j].Name]
+				if aok != bok {
+					return aok
+				}
+				return a < b
+			})
+
+			ordered := *list
+			ordered.Tools = tools
+			return &ordered, nil
+		}
+	}
+}
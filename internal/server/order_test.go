@@ -0,0 +1,38 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+)
+
+func TestToolOrder(t *testing.T) {
+	tests := []struct {
+		order string
+		want  []string
+	}{
+		{"", []string{"alpha:index", "alpha:search", "zeta:delete", "zeta:read", "zeta:write"}},
+		{"listed", []string{"alpha:search", "alpha:index", "zeta:write", "zeta:read", "zeta:delete"}},
+	}
+	for _, tt := range tests {
+		reg := registry.New()
+		reg.RegisterTools("zeta", []registry.Tool{{ID: "write", Name: "write"}, {ID: "read", Name: "read"}, {ID: "delete", Name: "delete"}})
+		reg.RegisterTools("alpha", []registry.Tool{{ID: "search", Name: "search"}, {ID: "index", Name: "index"}})
+		session := connect(t, newTestHub(reg, WithToolOrder(tt.order)), "")
+		waitForTools(t, session, len(tt.want))
+
+		res, err := session.ListTools(context.Background(), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, tool := range res.Tools {
+			got = append(got, tool.Name)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("order %q: tools = %v, want %v", tt.order, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/amir-the-h/mcp-hub/internal/plugin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// probesHandler serves the liveness and readiness probes and the Prometheus
+// metrics ahead of h, so scrapers and probes need no client token
+func probesHandler(pm *plugin.Manager, readiness string, h http.Handler, logger *slog.Logger) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		// An empty hub is alive, but say so since it may be a misconfiguration
+		n := enabledServers(pm.ServerStatuses())
+		body := map[string]any{"status": "ok", "servers": n}
+		if n == 0 {
+			body["reason"] = errNoServers.Error()
+		}
+		writeJSON(w, http.StatusOK, body, logger)
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		if err := checkReady(pm.ServerStatuses(), readiness); err != nil {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": "not ready", "reason": err.Error()}, logger)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"}, logger)
+	})
+	mux.Handle("GET /metrics", promhttp.HandlerFor(pm.Metrics(), promhttp.HandlerOpts{
+		ErrorLog: slog.NewLogLogger(logger.Handler(), slog.LevelError),
+	}))
+	mux.Handle("/", h)
+	return mux
+}
+
+// errNoServers is the readiness failure of a hub with no server meant to run
+var errNoServers = errors.New("no servers enabled")
+
+// enabledServers counts the servers meant to run, connected or not
+func enabledServers(servers []plugin.ServerStatus) int {
+	n := 0
+	for _, s := range servers {
+		if s.State != plugin.StateStopped {
+			n++
+		}
+	}
+	return n
+}
+
+// checkReady applies the readiness criteria: "any" (default) needs one
+// connected server, "all" needs every server meant to run connected too.
+// Stopped servers, e.g. removed, refused or given up, don't count, so a hub
+// without servers is never ready.
+func checkReady(servers []plugin.ServerStatus, readiness string) error {
+	if enabledServers(servers) == 0 {
+		return errNoServers
+	}
+	connected := 0
+	var pending []string
+	for _, s := range servers {
+		switch s.State {
+		case plugin.StateConnected, plugin.StateDegraded:
+			connected++
+		case plugin.StateStopped:
+		default:
+			pending = append(pending, s.Name)
+		}
+	}
+	if connected == 0 {
+		return fmt.Errorf("no server connected")
+	}
+	if readiness == "all" && len(pending) > 0 {
+		return fmt.Errorf("servers not connected: %s", strings.Join(pending, ", "))
+	}
+	return nil
+}
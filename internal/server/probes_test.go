@@ -0,0 +1,80 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/plugin"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+)
+
+func TestCheckReady(t *testing.T) {
+	statuses := func(states ...plugin.ServerState) []plugin.ServerStatus {
+		var out []plugin.ServerStatus
+		for i, st := range states {
+			out = append(out, plugin.ServerStatus{Name: string(rune('a' + i)), State: st})
+		}
+		return out
+	}
+
+	tests := []struct {
+		name      string
+		servers   []plugin.ServerStatus
+		readiness string
+		wantErr   bool
+	}{
+		{name: "no servers", wantErr: true},
+		{name: "only stopped", servers: statuses(plugin.StateStopped), wantErr: true},
+		{name: "connecting", servers: statuses(plugin.StateConnecting), wantErr: true},
+		{name: "one connected", servers: statuses(plugin.StateConnected, plugin.StateReconnecting)},
+		{name: "degraded counts", servers: statuses(plugin.StateDegraded)},
+		{name: "all pending", servers: statuses(plugin.StateConnected, plugin.StateReconnecting), readiness: "all", wantErr: true},
+		{name: "all connected", servers: statuses(plugin.StateConnected, plugin.StateStopped), readiness: "all"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkReady(tt.servers, tt.readiness)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+	if err := checkReady(statuses(plugin.StateStopped), ""); !errors.Is(err, errNoServers) {
+		t.Errorf("hub without servers: %v", err)
+	}
+}
+
+func TestProbesEmptyHub(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	pm := plugin.NewManager(registry.New(), plugin.WithLogger(logger))
+	h := probesHandler(pm, "", http.NotFoundHandler(), logger)
+
+	get := func(path string) (int, map[string]any) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var body map[string]any
+		if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+			t.Fatalf("%s: %v", path, err)
+		}
+		return rec.Code, body
+	}
+
+	// Alive, but saying it has nothing to serve
+	code, body := get("/healthz")
+	if code != http.StatusOK || body["status"] != "ok" {
+		t.Errorf("/healthz = %d %v", code, body)
+	}
+	if body["servers"] != 0.0 || body["reason"] != "no servers enabled" {
+		t.Errorf("/healthz body = %v", body)
+	}
+
+	code, body = get("/readyz")
+	if code != http.StatusServiceUnavailable || body["reason"] != "no servers enabled" {
+		t.Errorf("/readyz = %d %v", code, body)
+	}
+}
@@ -0,0 +1,63 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// reviewServer has a review prompt answering "<name> reviews <pr>"
+func reviewServer(name string) *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: name}, nil)
+	server.AddPrompt(&mcp.Prompt{Name: "review", Arguments: []*mcp.PromptArgument{{Name: "pr", Required: true}}}, func(ctx context.Context, req *mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		return &mcp.GetPromptResult{Messages: []*mcp.PromptMessage{{
+			Role:    "user",
+			Content: &mcp.TextContent{Text: name + " reviews " + req.Params.Arguments["pr"]},
+		}}}, nil
+	})
+	return server
+}
+
+func TestNamespacedPrompts(t *testing.T) {
+	reg := registry.New()
+	pm := newTestManager(reg)
+	for _, name := range []string{"github", "gitlab"} {
+		startServer(t, pm, name, config.ServerConfig{Type: "http", URL: serveBackend(t, reviewServer(name))})
+	}
+	session := connect(t, newHub(reg, pm), "")
+
+	ctx := context.Background()
+	var names []string
+	for deadline := time.Now().Add(5 * time.Second); len(names) < 2 && time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		res, err := session.ListPrompts(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = names[:0]
+		for _, p := range res.Prompts {
+			names = append(names, p.Name)
+			if len(p.Arguments) != 1 || p.Arguments[0].Name != "pr" || !p.Arguments[0].Required {
+				t.Errorf("%s arguments = %+v", p.Name, p.Arguments)
+			}
+		}
+	}
+	slices.Sort(names)
+	if want := []string{"github:review", "gitlab:review"}; !slices.Equal(names, want) {
+		t.Fatalf("prompts = %v, want %v", names, want)
+	}
+
+	for _, name := range []string{"github", "gitlab"} {
+		res, err := session.GetPrompt(ctx, &mcp.GetPromptParams{Name: name + ":review", Arguments: map[string]string{"pr": "42"}})
+		if err != nil {
+			t.Fatalf("get %s:review: %v", name, err)
+		}
+		if got := res.Messages[0].Content.(*mcp.TextContent).Text; got != name+" reviews 42" {
+			t.Errorf("%s:review rendered %q", name, got)
+		}
+	}
+}
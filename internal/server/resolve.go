@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/amir-the-h/mcp-hub/internal/plugin"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// bareNameMiddleware resolves tools/call requests naming a tool without its
+// server prefix according to the configured mode, rewriting the call to the
+// exposed name before the SDK looks the tool up
+func bareNameMiddleware(reg *registry.Registry, pm *plugin.Manager, names *toolNames, o *options) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" {
+				return next(ctx, method, req)
+			}
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok {
+				return next(ctx, method, req)
+			}
+			if names.prefixed(call.Params.Name) {
+				return next(ctx, method, req)
+			}
+
+			name, err := resolveBareName(reg, pm, names, o.bareToolNames, call.Params.Name)
+			if err != nil {
+				return nil, err
+			}
+			call.Params.Name = name
+			return next(ctx, method, req)
+		}
+	}
+}
+
+// resolveBareName maps a tool name without prefix to an exposed one:
+// "strict" never resolves, "single" (default) uses the only running server,
+// and "search" uses the one server exposing a tool of that name
+func resolveBareName(reg *registry.Registry, pm *plugin.Manager, names *toolNames, mode, name string) (string, error) {
+	switch mode {
+	case "strict":
+		return "", fmt.Errorf("tool name must be namespaced as <plugin>:<tool>")
+	case "search":
+		var owners []string
+		for _, t := range reg.List() {
+			if t.Name == name {
+				owners = append(owners, t.PluginID)
+			}
+		}
+		switch len(owners) {
+		case 0:
+			return "", fmt.Errorf("unknown tool %q", name)
+		case 1:
+			return names.name(owners[0], name), nil
+		}
+		sort.Strings(owners)
+		candidates := make([]string, len(owners))
+		for i, owner := range owners {
+			candidates[i] = names.name(owner, name)
+		}
+		return "", fmt.Errorf("tool %q is ambiguous, use one of: %s", name, strings.Join(candidates, ", "))
+	default:
+		servers := pm.ListServers()
+		if len(servers) != 1 {
+			return "", fmt.Errorf("tool name must be namespaced as <plugin>:<tool>")
+		}
+		return names.name(servers[0], name), nil
+	}
+}
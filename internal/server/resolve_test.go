@@ -0,0 +1,54 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestBareToolNames(t *testing.T) {
+	tests := []struct {
+		mode    string
+		servers map[string][]string
+		tool    string
+		want    string // result text, or a substring of the error
+		fails   bool
+	}{
+		{"strict", map[string][]string{"github": {"search"}}, "search", "must be namespaced", true},
+		{"single", map[string][]string{"github": {"search"}}, "search", "github:search", false},
+		{"single", map[string][]string{"github": {"search"}, "gitlab": {"search"}}, "search", "must be namespaced", true},
+		{"search", map[string][]string{"github": {"search", "create_issue"}, "gitlab": {"search"}}, "create_issue", "github:create_issue", false},
+		{"search", map[string][]string{"github": {"search"}, "gitlab": {"search"}}, "search", "ambiguous, use one of: github:search, gitlab:search", true},
+		{"search", map[string][]string{"github": {"search"}, "gitlab": {"search"}}, "merge", "unknown tool", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			reg := registry.New()
+			pm := newTestManager(reg)
+			var n int
+			for name, tools := range tt.servers {
+				startBackend(t, pm, name, tools...)
+				n += len(tools)
+			}
+			session := connect(t, newHub(reg, pm, WithBareToolNames(tt.mode)), "")
+			waitForTools(t, session, n)
+
+			res, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: tt.tool})
+			if tt.fails {
+				if err == nil || !strings.Contains(err.Error(), tt.want) {
+					t.Fatalf("call %s: err = %v, want %q", tt.tool, err, tt.want)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("call %s: %v", tt.tool, err)
+			}
+			if got := res.Content[0].(*mcp.TextContent).Text; got != tt.want {
+				t.Errorf("call %s answered by %q, want %q", tt.tool, got, tt.want)
+			}
+		})
+	}
+}
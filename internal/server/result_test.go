@@ -0,0 +1,124 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// voidServer has an empty tool answering without content and a null tool
+// answering with a null result. The SDK refuses to send a nil result, a nil
+// *CallToolResult goes out as null.
+func voidServer() *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "void"}, nil)
+	schema := map[string]any{"type": "object"}
+	server.AddTool(&mcp.Tool{Name: "empty", InputSchema: schema}, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{}, nil
+	})
+	server.AddTool(&mcp.Tool{Name: "null", InputSchema: schema}, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{}, nil
+	})
+	server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if call, ok := req.(*mcp.CallToolRequest); ok && call.Params.Name == "null" {
+				return (*mcp.CallToolResult)(nil), nil
+			}
+			return next(ctx, method, req)
+		}
+	})
+	return server
+}
+
+func TestVoidResults(t *testing.T) {
+	reg := registry.New()
+	pm := newTestManager(reg)
+	startServer(t, pm, "void", config.ServerConfig{Type: "http", URL: serveBackend(t, voidServer())})
+	session := connect(t, newHub(reg, pm), "")
+	waitForTools(t, session, 2)
+
+	for _, tool := range []string{"void:empty", "void:null"} {
+		res, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: tool})
+		if err != nil {
+			t.Errorf("%s: %v", tool, err)
+			continue
+		}
+		if res.IsError || len(res.Content) != 0 {
+			t.Errorf("%s: result = %+v, want a successful result without content", tool, res)
+		}
+	}
+}
+
+func TestDecodeToolResult(t *testing.T) {
+	for _, data := range []string{"", " ", "null", "{}", `{"content":null}`, `{"content":[]}`} {
+		res := decodeToolResult([]byte(data))
+		if res.IsError || res.Content == nil || len(res.Content) != 0 {
+			t.Errorf("decodeToolResult(%q) = %+v, want an empty content array", data, res)
+		}
+	}
+}
+
+// pixel is the start of a PNG, binary data that must survive base64
+var pixel = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00\x1f\x15\xc4\x89")
+
+// mediaServer has a tool "screenshot" answering with an image and an
+// embedded binary resource
+func mediaServer() *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "media"}, nil)
+	server.AddTool(&mcp.Tool{Name: "screenshot", InputSchema: map[string]any{"type": "object"}}, func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{
+			&mcp.ImageContent{Data: pixel, MIMEType: "image/png"},
+			&mcp.EmbeddedResource{Resource: &mcp.ResourceContents{URI: "file:///shot.png", MIMEType: "image/png", Blob: pixel}},
+		}}, nil
+	})
+	return server
+}
+
+func TestImageContent(t *testing.T) {
+	reg := registry.New()
+	pm := newTestManager(reg)
+	startServer(t, pm, "media", config.ServerConfig{Type: "http", URL: serveBackend(t, mediaServer())})
+	session := connect(t, newHub(reg, pm), "")
+	waitForTools(t, session, 1)
+
+	res, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "media:screenshot"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.IsError || len(res.Content) != 2 {
+		t.Fatalf("result = %+v, want an image and a resource", res)
+	}
+	image, ok := res.Content[0].(*mcp.ImageContent)
+	if !ok {
+		t.Fatalf("first block is %T, want an image", res.Content[0])
+	}
+	if image.MIMEType != "image/png" || !bytes.Equal(image.Data, pixel) {
+		t.Errorf("image = %s %x, want image/png %x", image.MIMEType, image.Data, pixel)
+	}
+	resource, ok := res.Content[1].(*mcp.EmbeddedResource)
+	if !ok {
+		t.Fatalf("second block is %T, want a resource", res.Content[1])
+	}
+	if r := resource.Resource; r.URI != "file:///shot.png" || r.MIMEType != "image/png" || !bytes.Equal(r.Blob, pixel) {
+		t.Errorf("resource = %+v", r)
+	}
+}
+
+func TestDecodeUnknownBlock(t *testing.T) {
+	// A block type the SDK doesn't know is passed on as text next to the
+	// blocks it does
+	data := `{"content":[{"type":"image","data":"iVBORw0KGgo=","mimeType":"image/png"},{"type":"video","uri":"file:///clip.mp4"}],"isError":false}`
+	res := decodeToolResult([]byte(data))
+	if len(res.Content) != 2 {
+		t.Fatalf("content = %+v, want two blocks", res.Content)
+	}
+	if image, ok := res.Content[0].(*mcp.ImageContent); !ok || image.MIMEType != "image/png" || string(image.Data) != "\x89PNG\r\n\x1a\n" {
+		t.Errorf("first block = %+v, want the image", res.Content[0])
+	}
+	if text, ok := res.Content[1].(*mcp.TextContent); !ok || text.Text != `{"type":"video","uri":"file:///clip.mp4"}` {
+		t.Errorf("second block = %+v, want the video block as text", res.Content[1])
+	}
+}
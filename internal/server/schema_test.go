@@ -0,0 +1,78 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// schemalessServer lists its tools "legacy" and "modern", sending no input
+// schema for legacy
+func schemalessServer() *mcp.Server {
+	server := toolServer("old", "legacy", "modern")
+	server.AddReceivingMiddleware(func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			res, err := next(ctx, method, req)
+			if list, ok := res.(*mcp.ListToolsResult); ok && err == nil {
+				for i, tool := range list.Tools {
+					if tool.Name == "legacy" {
+						stripped := *tool
+						stripped.InputSchema = nil
+						list.Tools[i] = &stripped
+					}
+				}
+			}
+			return res, err
+		}
+	})
+	return server
+}
+
+func TestMissingInputSchema(t *testing.T) {
+	// Substituted by default, the tool stays usable
+	reg := registry.New()
+	pm := newTestManager(reg)
+	startServer(t, pm, "old", config.ServerConfig{Type: "http", URL: serveBackend(t, schemalessServer())})
+	session := connect(t, newHub(reg, pm), "")
+	tools := waitForTools(t, session, 2)
+	schema, err := json.Marshal(tools["old:legacy"].InputSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(schema) != `{"type":"object"}` {
+		t.Errorf("old:legacy input schema = %s, want {\"type\":\"object\"}", schema)
+	}
+	if got := callTool(t, session, "old:legacy"); got != "old:legacy" {
+		t.Errorf("old:legacy answered %q", got)
+	}
+
+	tests := []struct {
+		policy     string
+		registered bool
+	}{
+		{"reject", false},
+		{"passthrough", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			reg := registry.New()
+			pm := newTestManager(reg)
+			cfg := config.ServerConfig{Type: "http", URL: serveBackend(t, schemalessServer()), MissingInputSchema: tt.policy}
+			startServer(t, pm, "old", cfg)
+			tool, ok := reg.Tool("old", "legacy")
+			if ok != tt.registered {
+				t.Fatalf("legacy registered = %v, want %v", ok, tt.registered)
+			}
+			if ok && tool.InputSchema != nil {
+				t.Errorf("legacy input schema = %v, want none", tool.InputSchema)
+			}
+			if _, ok := reg.Tool("old", "modern"); !ok {
+				t.Error("modern not registered")
+			}
+		})
+	}
+}
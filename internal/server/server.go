@@ -1,101 +1,343 @@
 package server
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
 
+	"github.com/amir-the-h/mcp-hub/internal/logging"
 	"github.com/amir-the-h/mcp-hub/internal/plugin"
 	"github.com/amir-the-h/mcp-hub/internal/registry"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 )
 
 // New creates an HTTP server that serves MCP Streamable HTTP using the SDK.
 // It builds a single SDK Server instance and keeps it synchronized with the
-// hub registry (tools aggregated and namespaced as <plugin>:<tool>).
-func New(reg *registry.Registry, pm *plugin.Manager) *http.Server {
+// hub registry (tools and prompts aggregated and namespaced as
+// <plugin>:<name>, resources under their backend URIs).
+func New(reg *registry.Registry, pm *plugin.Manager, opts ...Option) *http.Server {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.logger == nil {
+		o.logger = logging.Default()
+	}
+
 	impl := &mcp.Implementation{Name: "mcp-hub", Version: "0.1.0"}
-	sdkServer := mcp.NewServer(impl, &mcp.ServerOptions{HasTools: true})
+	var sync *toolSync
+	sdkServer := mcp.NewServer(impl, &mcp.ServerOptions{
+		HasTools: true,
+		// Otherwise the SDK advertises prompts while any are registered,
+		// which syncPrompts keeps in line with the running backends
+		HasPrompts:   o.capabilities != "backends",
+		HasResources: true,
+		InitializedHandler: func(ctx context.Context, req *mcp.InitializedRequest) {
+			expireSession(req.Session, o.sessionMaxLifetime, sync.isInspectorSession, o.logger)
+		},
+	})
+	usage := newUsageCounter()
+	requests := newClientRequests(o.clientDisconnect)
+	names := newToolNames(pm, reg, o.toolSeparator)
+	sync = newToolSync(sdkServer, callHandler(pm, usage, requests, o.logger), names, o.toolUpdates, o.logger)
+	sessions := newSessionCounter(sdkServer, sync.isInspectorSession)
+	sdkServer.AddReceivingMiddleware(
+		bareNameMiddleware(reg, pm, names, &o),
+		unknownToolMiddleware(reg, names, &o),
+		toolCapMiddleware(pm, names, usage, &o, sync.isInspector),
+		toolOrderMiddleware(reg, pm, names, &o),
+	)
 
-	// Track registered tools so we can remove stale ones
-	registered := make(map[string]bool)
+	// Hub status served locally rather than forwarded
+	addStatusResource(sdkServer, reg, pm, names, sessions)
 
-	// Synchronize registry snapshots to SDK server tools
+	// Synchronize registry snapshots to SDK server tools, reconciling
+	// periodically in case a snapshot was missed
+	interval := o.reconcileInterval
+	if interval <= 0 {
+		interval = defaultReconcileInterval
+	}
 	ch := reg.Subscribe()
 	go func() {
 		defer reg.Unsubscribe(ch)
-		for snapshot := range ch {
-			desired := make(map[string]struct{})
-			for _, t := range snapshot {
-				namespaced := t.PluginID + ":" + t.Name
-				desired[namespaced] = struct{}{}
-				if !registered[namespaced] {
-					// add tool with simple object input schema
-					tool := &mcp.Tool{
-						Name:        namespaced,
-						Description: t.Description,
-						InputSchema: map[string]any{"type": "object"},
-					}
-					// handler forwards to plugin.Manager
-					handler := func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-						// parse namespaced name
-						name := req.Params.Name
-						idx := strings.Index(name, ":")
-						var pluginID, toolName string
-						if idx >= 0 {
-							pluginID = name[:idx]
-							toolName = name[idx+1:]
-						} else {
-							// fallback: if only one server, use it
-							servers := pm.ListServers()
-							if len(servers) == 1 {
-								pluginID = servers[0]
-								toolName = name
-							} else {
-								return nil, fmt.Errorf("tool name must be namespaced as <plugin>:<tool>")
-							}
-						}
-
-						respBytes, err := pm.Execute(ctx, pluginID, toolName, req.Params.Arguments)
-						if err != nil {
-							return nil, err
-						}
-
-						var result mcp.CallToolResult
-						if err := json.Unmarshal(respBytes, &result); err != nil {
-							// Return raw text content if unmarshal fails
-							res := &mcp.CallToolResult{}
-							res.Content = []mcp.Content{&mcp.TextContent{Text: string(respBytes)}}
-							return res, nil
-						}
-						return &result, nil
-					}
-
-					sdkServer.AddTool(tool, handler)
-					registered[namespaced] = true
-				}
+		sync.run(reg, ch, interval)
+	}()
+
+	// Backend prompts and resources follow their own registry snapshots
+	prompts := reg.SubscribePrompts()
+	go func() {
+		defer reg.UnsubscribePrompts(prompts)
+		syncPrompts(sdkServer, pm, prompts)
+	}()
+	resources := reg.SubscribeResources()
+	go func() {
+		defer reg.UnsubscribeResources(resources)
+		syncResources(sdkServer, pm, resources, o.logger)
+	}()
+
+	// Create streamable HTTP handler using SDK helper
+	mux := http.NewServeMux()
+	mcpHandler := mcp.NewStreamableHTTPHandler(func(req *http.Request) *mcp.Server { return sdkServer }, &mcp.StreamableHTTPOptions{
+		SessionTimeout: o.sessionIdleTimeout,
+	})
+	mux.Handle("/", limitSessions(sessions, o.maxSessions, requests.track(mcpHandler), o.logger))
+	addAPIRoutes(mux, reg, pm, names, o.logger)
+
+	return &http.Server{Addr: ":8080", Handler: withBasePath(o.basePath, probesHandler(pm, o.readiness, withClientAuth(o.clients, o.apiKeys, mux), o.logger)), ReadTimeout: 15 * time.Second}
+}
+
+// callHandler returns the handler of a forwarded tool, it routes calls to
+// the owning server through plugin.Manager. Bare names were resolved by
+// bareNameMiddleware before the SDK routed the call here.
+func callHandler(pm *plugin.Manager, usage *usageCounter, requests *clientRequests, logger *slog.Logger) func(pluginID, toolName string) mcp.ToolHandler {
+	return func(pluginID, toolName string) mcp.ToolHandler {
+		return func(ctx context.Context, req *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			received := time.Now()
+
+			// Stop the backend call if the client disconnects mid-call
+			ctx, release := requests.bind(ctx, req)
+			defer release()
+
+			// Continue the client's trace, if it sent one
+			if req.Extra != nil && req.Extra.Header != nil {
+				ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(req.Extra.Header))
 			}
-			// remove tools that are no longer present
-			var toRemove []string
-			for name := range registered {
-				if _, ok := desired[name]; !ok {
-					toRemove = append(toRemove, name)
-				}
+
+			usage.record(req.Params.Name)
+
+			// Forward backend incremental output as progress notifications
+			// while the call runs, if the client asked for progress
+			if token := req.Params.GetProgressToken(); token != nil {
+				ctx = plugin.WithChunkFunc(ctx, func(c plugin.Chunk) {
+					_ = req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+						ProgressToken: token,
+						Progress:      c.Progress,
+						Total:         c.Total,
+						Message:       c.Message,
+					})
+				})
+			}
+
+			// Enforce the authenticated client's access policy in Execute
+			if id := requestIdentity(req); id != nil {
+				ctx = plugin.WithIdentity(ctx, id)
+			}
+
+			// Record the phase breakdown if the client asked for it
+			var timing *plugin.Timing
+			if wantsTiming(req) {
+				timing = &plugin.Timing{}
+				ctx = plugin.WithTiming(ctx, timing)
 			}
-			if len(toRemove) > 0 {
-				sdkServer.RemoveTools(toRemove...)
-				for _, n := range toRemove {
-					delete(registered, n)
+
+			respBytes, err := pm.Execute(ctx, pluginID, toolName, req.Params.Arguments)
+			if err != nil {
+				if abandoned(ctx) {
+					logger.Info("call:abandoned", "plugin", pluginID, "tool", toolName, "duration", time.Since(received))
 				}
+				// Pass the backend's own error on if its server is set to
+				if rpc, ok := plugin.BackendError(err); ok {
+					return nil, rpc
+				}
+				// Invalid arguments are a tool error, so a model can correct
+				// its call
+				if errors.Is(err, plugin.ErrInvalidArguments) {
+					return &mcp.CallToolResult{
+						Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+						IsError: true,
+					}, nil
+				}
+				// A tool reporting failure keeps its error content
+				data, ok := plugin.ToolErrorResult(err)
+				if !ok {
+					return nil, err
+				}
+				respBytes = data
 			}
+
+			result := decodeToolResult(respBytes)
+			if timing != nil {
+				attachTiming(result, timing, time.Since(received))
+			}
+			return result, nil
+		}
+	}
+}
+
+// decodeToolResult turns the bytes returned by Execute into a tool result.
+// An empty or null result, e.g. from a void tool or an output transform, is a
+// successful result without content.
+func decodeToolResult(data []byte) *mcp.CallToolResult {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return &mcp.CallToolResult{Content: []mcp.Content{}}
+	}
+
+	result := &mcp.CallToolResult{}
+	if err := json.Unmarshal(trimmed, result); err != nil {
+		return decodeContentBlocks(trimmed, data)
+	}
+	if result.Content == nil {
+		result.Content = []mcp.Content{}
+	}
+	return result
+}
+
+// decodeContentBlocks decodes a result the SDK rejected one content block at
+// a time, so a block of a type it doesn't know doesn't cost the client the
+// images and resources next to it. Such blocks are passed on as their JSON
+// text, and data that isn't a result at all as a single text block.
+func decodeContentBlocks(trimmed, data []byte) *mcp.CallToolResult {
+	raw := func() *mcp.CallToolResult {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: string(data)}}}
+	}
+
+	var fields map[string]json.RawMessage
+	var blocks []json.RawMessage
+	if json.Unmarshal(trimmed, &fields) != nil || json.Unmarshal(fields["content"], &blocks) != nil {
+		return raw()
+	}
+	delete(fields, "content")
+	rest, err := json.Marshal(fields)
+	if err != nil {
+		return raw()
+	}
+	result := &mcp.CallToolResult{}
+	if json.Unmarshal(rest, result) != nil {
+		return raw()
+	}
+
+	result.Content = make([]mcp.Content, 0, len(blocks))
+	for _, block := range blocks {
+		var one mcp.CallToolResult
+		wrapped, err := json.Marshal(map[string][]json.RawMessage{"content": {block}})
+		if err == nil && json.Unmarshal(wrapped, &one) == nil && len(one.Content) == 1 {
+			result.Content = append(result.Content, one.Content[0])
+		} else {
+			result.Content = append(result.Content, &mcp.TextContent{Text: string(block)})
+		}
+	}
+	return result
+}
+
+// timingHeader asks the hub to attach a timing breakdown to tool results
+const timingHeader = "X-MCP-Hub-Timing"
+
+// timingMetaKey is the _meta key holding the timing breakdown
+const timingMetaKey = "mcp-hub/timing"
+
+// wantsTiming reports whether the call carries the timing debug header
+func wantsTiming(req *mcp.CallToolRequest) bool {
+	if req.Extra == nil || req.Extra.Header == nil {
+		return false
+	}
+	switch strings.ToLower(req.Extra.Header.Get(timingHeader)) {
+	case "1", "true", "yes":
+		return true
+	}
+	return false
+}
+
+// attachTiming adds the call's phase breakdown, in milliseconds, to the
+// result's _meta. Overhead is everything the hub spent outside the backend
+// call itself.
+func attachTiming(result *mcp.CallToolResult, t *plugin.Timing, total time.Duration) {
+	ms := func(d time.Duration) float64 { return float64(d.Microseconds()) / 1000 }
+	if result.Meta == nil {
+		result.Meta = mcp.Meta{}
+	}
+	result.Meta[timingMetaKey] = map[string]float64{
+		"queueMs":    ms(t.Queue),
+		"callMs":     ms(t.Call),
+		"processMs":  ms(t.Process),
+		"overheadMs": ms(total - t.Call),
+		"totalMs":    ms(total),
+	}
+}
+
+// splitNamespaced splits a <plugin>:<tool> config reference
+func splitNamespaced(name string) (pluginID, toolName string, ok bool) {
+	idx := strings.Index(name, ":")
+	if idx < 0 {
+		return "", name, false
+	}
+	return name[:idx], name[idx+1:], true
+}
+
+// addTool registers a tool on the SDK server, converting the SDK's panic on
+// invalid tools (e.g. a bad input schema) into an error
+func addTool(s *mcp.Server, tool *mcp.Tool, h mcp.ToolHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
 		}
 	}()
+	s.AddTool(tool, h)
+	return nil
+}
 
-	// Create streamable HTTP handler using SDK helper
-	handler := mcp.NewStreamableHTTPHandler(func(req *http.Request) *mcp.Server { return sdkServer }, nil)
+// withBasePath serves h under prefix only, answering 404 everywhere else
+func withBasePath(prefix string, h http.Handler) http.Handler {
+	if prefix == "" {
+		return h
+	}
+	root := http.NewServeMux()
+	root.Handle(prefix+"/", http.StripPrefix(prefix, h))
+	root.HandleFunc(prefix, func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, prefix+"/", http.StatusPermanentRedirect)
+	})
+	return root
+}
+
+// unknownToolMiddleware applies the unknown tool policy to tools/call requests
+// naming a tool that isn't in the registry
+func unknownToolMiddleware(reg *registry.Registry, names *toolNames, o *options) mcp.Middleware {
+	return func(next mcp.MethodHandler) mcp.MethodHandler {
+		return func(ctx context.Context, method string, req mcp.Request) (mcp.Result, error) {
+			if method != "tools/call" || o.unknownTool == "" || o.unknownTool == "error" {
+				return next(ctx, method, req)
+			}
+			call, ok := req.(*mcp.CallToolRequest)
+			if !ok {
+				return next(ctx, method, req)
+			}
 
-	return &http.Server{Addr: ":8080", Handler: handler, ReadTimeout: 15 * time.Second}
+			name := call.Params.Name
+			var known []string
+			for _, t := range reg.List() {
+				namespaced := names.name(t.PluginID, t.Name)
+				if namespaced == name {
+					return next(ctx, method, req)
+				}
+				known = append(known, namespaced)
+			}
+
+			switch o.unknownTool {
+			case "suggest":
+				if suggestions := suggestTools(name, known, names.separator); len(suggestions) > 0 {
+					return nil, fmt.Errorf("unknown tool %q, did you mean: %s", name, strings.Join(suggestions, ", "))
+				}
+				return nil, fmt.Errorf("unknown tool %q", name)
+			case "fallback":
+				args, err := json.Marshal(map[string]any{
+					"tool":      name,
+					"arguments": call.Params.Arguments,
+				})
+				if err != nil {
+					return nil, fmt.Errorf("failed to build fallback arguments: %w", err)
+				}
+				call.Params.Name = names.reference(o.fallbackTool)
+				call.Params.Arguments = args
+			}
+			return next(ctx, method, req)
+		}
+	}
 }
@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/plugin"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// newTestHub returns the handler of a hub over the registry. Its manager has
+// no servers, so calls of registered tools fail in the manager.
+func newTestHub(reg *registry.Registry, opts ...Option) http.Handler {
+	return newHub(reg, newTestManager(reg), opts...)
+}
+
+// newHub returns the handler of a hub forwarding calls to pm
+func newHub(reg *registry.Registry, pm *plugin.Manager, opts ...Option) http.Handler {
+	return New(reg, pm, append([]Option{WithLogger(testLogger)}, opts...)...).Handler
+}
+
+func newTestManager(reg *registry.Registry) *plugin.Manager {
+	return plugin.NewManager(reg, plugin.WithLogger(testLogger))
+}
+
+// startBackend starts server name on pm, backed by toolServer
+func startBackend(t *testing.T, pm *plugin.Manager, name string, tools ...string) {
+	t.Helper()
+	startServer(t, pm, name, config.ServerConfig{Type: "http", URL: serveBackend(t, toolServer(name, tools...))})
+}
+
+// toolServer is an MCP server whose tools answer with "<name>:<tool>"
+func toolServer(name string, tools ...string) *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: name}, nil)
+	for _, tool := range tools {
+		mcp.AddTool(server, &mcp.Tool{Name: tool}, func(context.Context, *mcp.CallToolRequest, struct{}) (*mcp.CallToolResult, any, error) {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: name + ":" + tool}}}, nil, nil
+		})
+	}
+	return server
+}
+
+// serveBackend serves backend over streamable HTTP and returns its URL
+func serveBackend(t *testing.T, backend *mcp.Server) string {
+	t.Helper()
+	srv := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return backend }, nil))
+	t.Cleanup(func() {
+		srv.CloseClientConnections()
+		srv.Close()
+	})
+	return srv.URL
+}
+
+// startServer starts server name on pm and stops it when the test ends
+func startServer(t *testing.T, pm *plugin.Manager, name string, cfg config.ServerConfig) {
+	t.Helper()
+	if err := pm.StartServer(context.Background(), name, cfg); err != nil {
+		t.Fatalf("start %s: %v", name, err)
+	}
+	t.Cleanup(func() { pm.StopServer(name) })
+}
+
+// callTool calls a tool through the hub, retrying while the sync goroutine
+// hasn't exposed it yet, and returns the text of the result
+func callTool(t *testing.T, session *mcp.ClientSession, name string) string {
+	t.Helper()
+	var err error
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		var res *mcp.CallToolResult
+		res, err = session.CallTool(context.Background(), &mcp.CallToolParams{Name: name})
+		if err != nil {
+			continue
+		}
+		if res.IsError || len(res.Content) == 0 {
+			t.Fatalf("call %s: error result %v", name, res.Content)
+		}
+		text, _ := res.Content[0].(*mcp.TextContent)
+		if text == nil {
+			t.Fatalf("call %s: content %T", name, res.Content[0])
+		}
+		return text.Text
+	}
+	t.Fatalf("call %s: %v", name, err)
+	return ""
+}
+
+// connect serves handler over httptest and connects an MCP client to the
+// endpoint under path
+func connect(t *testing.T, handler http.Handler, path string) *mcp.ClientSession {
+	t.Helper()
+	return connectWith(t, handler, path, nil)
+}
+
+// connectWith connects like connect, sending requests with httpClient
+func connectWith(t *testing.T, handler http.Handler, path string, httpClient *http.Client) *mcp.ClientSession {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil)
+	session, err := client.Connect(context.Background(), &mcp.StreamableClientTransport{Endpoint: srv.URL + path, HTTPClient: httpClient}, nil)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { session.Close() })
+	return session
+}
+
+// waitForTools lists the session's tools until there are n, the sync
+// goroutine exposes registry changes shortly after they happen
+func waitForTools(t *testing.T, session *mcp.ClientSession, n int) map[string]*mcp.Tool {
+	t.Helper()
+	var tools map[string]*mcp.Tool
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		res, err := session.ListTools(context.Background(), nil)
+		if err != nil {
+			t.Fatalf("list tools: %v", err)
+		}
+		tools = make(map[string]*mcp.Tool)
+		for _, tool := range res.Tools {
+			tools[tool.Name] = tool
+		}
+		if len(tools) == n {
+			return tools
+		}
+	}
+	t.Fatalf("got tools %v, want %d", keys(tools), n)
+	return nil
+}
+
+func keys[V any](m map[string]V) []string {
+	out := make([]string, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
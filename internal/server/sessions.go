@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// sessionCounter counts the open client sessions of the SDK server, leaving
+// out the hub's own reconciliation session
+type sessionCounter struct {
+	server   *mcp.Server
+	internal func(*mcp.ServerSession) bool
+}
+
+func newSessionCounter(s *mcp.Server, internal func(*mcp.ServerSession) bool) *sessionCounter {
+	c := &sessionCounter{server: s, internal: internal}
+	meter := otel.Meter("github.com/amir-the-h/mcp-hub/internal/server")
+	_, _ = meter.Int64ObservableGauge("mcp_hub.sessions",
+		metric.WithDescription("Open client sessions"),
+		metric.WithInt64Callback(func(ctx context.Context, o metric.Int64Observer) error {
+			o.Observe(int64(c.count()))
+			return nil
+		}))
+	return c
+}
+
+func (c *sessionCounter) count() int {
+	n := 0
+	for ss := range c.server.Sessions() {
+		if !c.internal(ss) {
+			n++
+		}
+	}
+	return n
+}
+
+// expireSession closes a client session maxLifetime after it was
+// initialized, the client then starts a new one. Sessions for which
+// internal returns true are left open.
+func expireSession(ss *mcp.ServerSession, maxLifetime time.Duration, internal func(*mcp.ServerSession) bool, logger *slog.Logger) {
+	if maxLifetime <= 0 {
+		return
+	}
+	time.AfterFunc(maxLifetime, func() {
+		if internal(ss) {
+			return
+		}
+		logger.Info("session:expire", "id", ss.ID(), "lifetime", maxLifetime)
+		_ = ss.Close()
+	})
+}
+
+// limitSessions refuses requests starting a new session while max sessions
+// are open. Requests of existing sessions carry their session ID and pass.
+func limitSessions(sessions *sessionCounter, max int, h http.Handler, logger *slog.Logger) http.Handler {
+	if max <= 0 {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.Header.Get("Mcp-Session-Id") == "" && sessions.count() >= max {
+			logger.Warn("session:refuse", "open", sessions.count(), "max", max)
+			w.Header().Set("Retry-After", "5")
+			http.Error(w, "too many sessions", http.StatusServiceUnavailable)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
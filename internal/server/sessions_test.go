@@ -0,0 +1,70 @@
+package server
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestIdleSessionClosed(t *testing.T) {
+	hub := newTestHub(registry.New(), WithSessionLimits(0, 100*time.Millisecond, 0))
+	idle := connect(t, hub, "")
+	busy := connect(t, hub, "")
+	ctx := context.Background()
+
+	// A session in use stays open past the idle timeout
+	for range 6 {
+		if _, err := busy.ListTools(ctx, nil); err != nil {
+			t.Fatalf("busy session: %v", err)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	if _, err := idle.ListTools(ctx, nil); err == nil {
+		t.Error("idle session still answered")
+	}
+}
+
+func TestSessionLifetime(t *testing.T) {
+	session := connect(t, newTestHub(registry.New(), WithSessionLimits(100*time.Millisecond, 0, 0)), "")
+	ctx := context.Background()
+	if _, err := session.ListTools(ctx, nil); err != nil {
+		t.Fatalf("list tools: %v", err)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if _, err := session.ListTools(ctx, nil); err == nil {
+		t.Error("session answered past its lifetime")
+	}
+}
+
+func TestMaxSessions(t *testing.T) {
+	srv := httptest.NewServer(newTestHub(registry.New(), WithSessionLimits(0, 0, 1)))
+	t.Cleanup(srv.Close)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test"}, nil)
+	connect := func() (*mcp.ClientSession, error) {
+		return client.Connect(context.Background(), &mcp.StreamableClientTransport{Endpoint: srv.URL}, nil)
+	}
+
+	first, err := connect()
+	if err != nil {
+		t.Fatalf("first session: %v", err)
+	}
+	if second, err := connect(); err == nil {
+		second.Close()
+		t.Fatal("second session opened past the limit")
+	}
+
+	// Closing the first makes room
+	first.Close()
+	var second *mcp.ClientSession
+	for deadline := time.Now().Add(5 * time.Second); second == nil && time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		second, _ = connect()
+	}
+	if second == nil {
+		t.Fatal("no session after the first closed")
+	}
+	second.Close()
+}
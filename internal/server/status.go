@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/amir-the-h/mcp-hub/internal/plugin"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// hubScheme is the URI scheme of resources served by the hub itself. Proxied
+// backend resources must never use it.
+const hubScheme = "hub"
+
+// statusURI is the resource describing the hub's backends and tools
+const statusURI = hubScheme + "://status"
+
+// hubStatus is the document served at statusURI
+type hubStatus struct {
+	Servers  []serverStatus      `json:"servers"`
+	Invalid  map[string]string   `json:"invalid,omitempty"`
+	Drift    *plugin.DriftReport `json:"drift,omitempty"`
+	Sessions int                 `json:"sessions"`
+}
+
+type serverStatus struct {
+	Name      string            `json:"name"`
+	State     string            `json:"state"`
+	Transport string            `json:"transport,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Tools     []string          `json:"tools"`
+}
+
+// addStatusResource registers the read-only hub status resource
+func addStatusResource(s *mcp.Server, reg *registry.Registry, pm *plugin.Manager, names *toolNames, sessions *sessionCounter) {
+	s.AddResource(&mcp.Resource{
+		URI:         statusURI,
+		Name:        "hub-status",
+		Description: "Backends aggregated by this hub with their connection state and tools",
+		MIMEType:    "application/json",
+	}, func(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+		status := buildStatus(reg, pm, names)
+		status.Sessions = sessions.count()
+		data, err := json.MarshalIndent(status, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode hub status: %w", err)
+		}
+		return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{{
+			URI:      statusURI,
+			MIMEType: "application/json",
+			Text:     string(data),
+		}}}, nil
+	})
+}
+
+// buildStatus snapshots every known server, running or not
+func buildStatus(reg *registry.Registry, pm *plugin.Manager, names *toolNames) hubStatus {
+	tools := make(map[string][]string)
+	for _, t := range reg.List() {
+		tools[t.PluginID] = append(tools[t.PluginID], names.name(t.PluginID, t.Name))
+	}
+
+	status := hubStatus{Invalid: pm.InvalidServers()}
+	if report, ok := pm.DriftReport(); ok {
+		status.Drift = &report
+	}
+	for _, st := range pm.ServerStatuses() {
+		srv := serverStatus{Name: st.Name, State: string(st.State), Transport: st.Transport, Tools: tools[st.Name]}
+		if srv.Tools == nil {
+			srv.Tools = []string{}
+		}
+		sort.Strings(srv.Tools)
+		if s, ok := pm.GetServer(st.Name); ok {
+			srv.Labels = s.Labels()
+		}
+		status.Servers = append(status.Servers, srv)
+	}
+	return status
+}
@@ -0,0 +1,69 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestStatusResource(t *testing.T) {
+	reg := registry.New()
+	pm := newTestManager(reg)
+	startBackend(t, pm, "files", "read", "write")
+	// A backend claiming the hub's scheme doesn't replace the status
+	reg.RegisterResources("files", []registry.Resource{
+		{URI: statusURI, Name: "fake-status", PluginID: "files"},
+		{URI: "file:///readme", Name: "readme", PluginID: "files"},
+	})
+	session := connect(t, newHub(reg, pm), "")
+
+	ctx := context.Background()
+	var uris []string
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		res, err := session.ListResources(ctx, nil)
+		if err != nil {
+			t.Fatalf("list resources: %v", err)
+		}
+		uris = uris[:0]
+		for _, r := range res.Resources {
+			uris = append(uris, r.URI)
+		}
+		if len(uris) == 2 {
+			break
+		}
+	}
+	slices.Sort(uris)
+	if !slices.Equal(uris, []string{"file:///readme", statusURI}) {
+		t.Errorf("resources = %v", uris)
+	}
+
+	res, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: statusURI})
+	if err != nil {
+		t.Fatalf("read status: %v", err)
+	}
+	if len(res.Contents) != 1 || res.Contents[0].MIMEType != "application/json" {
+		t.Fatalf("contents = %+v", res.Contents)
+	}
+	var status hubStatus
+	if err := json.Unmarshal([]byte(res.Contents[0].Text), &status); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+	if len(status.Servers) != 1 {
+		t.Fatalf("servers = %+v", status.Servers)
+	}
+	files := status.Servers[0]
+	if files.Name != "files" || files.State != "connected" || files.Transport != "http" {
+		t.Errorf("server status = %+v", files)
+	}
+	if !slices.Equal(files.Tools, []string{"files:read", "files:write"}) {
+		t.Errorf("tools = %v", files.Tools)
+	}
+	if status.Sessions < 1 {
+		t.Errorf("sessions = %d", status.Sessions)
+	}
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"sort"
+	"strings"
+)
+
+// maxSuggestions bounds the number of "did you mean" candidates returned
+const maxSuggestions = 3
+
+// suggestTools returns the candidate names closest to name by edit distance.
+// Both the full namespaced name and its bare tool part are compared so that
+// a missing or wrong plugin prefix still finds the intended tool.
+func suggestTools(name string, candidates []string, separator string) []string {
+	type scored struct {
+		name string
+		dist int
+	}
+
+	bare := name
+	if idx := strings.Index(name, separator); idx >= 0 {
+		bare = name[idx+len(separator):]
+	}
+
+	var matches []scored
+	for _, c := range candidates {
+		d := levenshtein(strings.ToLower(name), strings.ToLower(c))
+		if idx := strings.Index(c, separator); idx >= 0 {
+			if bd := levenshtein(strings.ToLower(bare), strings.ToLower(c[idx+len(separator):])); bd < d {
+				d = bd
+			}
+		}
+		// Only suggest names within a third of the length of the request
+		limit := len(bare) / 3
+		if limit < 2 {
+			limit = 2
+		}
+		if d <= limit {
+			matches = append(matches, scored{name: c, dist: d})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].dist != matches[j].dist {
+			return matches[i].dist < matches[j].dist
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	out := make([]string, 0, maxSuggestions)
+	for i := 0; i < len(matches) && i < maxSuggestions; i++ {
+		out = append(out, matches[i].name)
+	}
+	return out
+}
+
+// levenshtein computes the edit distance between a and b
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestSuggestTools(t *testing.T) {
+	candidates := []string{"github:search", "github:create_issue", "files:read", "files:write"}
+	tests := []struct {
+		name string
+		want []string
+	}{
+		{"github:serch", []string{"github:search"}},
+		{"search", []string{"github:search"}},
+		{"gitlab:search", []string{"github:search"}},
+		{"files:raed", []string{"files:read"}},
+		{"weather:forecast", []string{}},
+	}
+	for _, tt := range tests {
+		if got := suggestTools(tt.name, candidates, ":"); !slices.Equal(got, tt.want) {
+			t.Errorf("suggestTools(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUnknownToolSuggestions(t *testing.T) {
+	reg := registry.New()
+	reg.RegisterTools("github", []registry.Tool{{ID: "search", Name: "search"}, {ID: "create_issue", Name: "create_issue"}})
+	session := connect(t, newTestHub(reg, WithUnknownToolPolicy("suggest", "")), "")
+	waitForTools(t, session, 2)
+
+	_, err := session.CallTool(context.Background(), &mcp.CallToolParams{Name: "github:serch"})
+	if err == nil {
+		t.Fatal("call of an unknown tool succeeded")
+	}
+	if !strings.Contains(err.Error(), "did you mean: github:search") {
+		t.Errorf("error = %v, want a suggestion of github:search", err)
+	}
+}
@@ -0,0 +1,244 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// toolSync mirrors registry snapshots onto the SDK server's tool set
+type toolSync struct {
+	sdk     *mcp.Server
+	handler func(pluginID, toolName string) mcp.ToolHandler
+	names   *toolNames
+	updates string
+
+	// mu serializes snapshot application and reconciliation
+	mu sync.Mutex
+
+	// inspector lists the tools the SDK server actually exposes
+	inspector        *mcp.ClientSession
+	inspectorSession atomic.Pointer[mcp.ServerSession]
+
+	// registered holds the last definition pushed to the SDK per namespaced
+	// tool, so stale and changed tools can be detected
+	registered map[string]registry.Tool
+	logger     *slog.Logger
+
+	// add registers a tool on the SDK server, replaced in tests to make it
+	// fail
+	add func(*mcp.Server, *mcp.Tool, mcp.ToolHandler) error
+}
+
+func newToolSync(sdk *mcp.Server, handler func(pluginID, toolName string) mcp.ToolHandler, names *toolNames, updates string, logger *slog.Logger) *toolSync {
+	return &toolSync{
+		sdk:        sdk,
+		handler:    handler,
+		names:      names,
+		updates:    updates,
+		registered: make(map[string]registry.Tool),
+		logger:     logger,
+		add:        addTool,
+	}
+}
+
+// run applies registry snapshots until ch is closed and reconciles against
+// the registry every interval as a safety net
+func (s *toolSync) run(reg *registry.Registry, ch chan []registry.Tool, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case snapshot, ok := <-ch:
+			if !ok {
+				return
+			}
+			s.safely(func() { s.apply(snapshot) })
+		case <-ticker.C:
+			s.safely(func() { s.reconcile(reg.Published()) })
+		}
+	}
+}
+
+// safely runs fn, logging instead of dying on a panic so one bad snapshot
+// doesn't stop all later syncs
+func (s *toolSync) safely(fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("sync:panic", "err", r)
+		}
+	}()
+	fn()
+}
+
+// apply brings the SDK server in line with a registry snapshot
+func (s *toolSync) apply(snapshot []registry.Tool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.applyLocked(snapshot)
+}
+
+func (s *toolSync) applyLocked(snapshot []registry.Tool) {
+	desired := make(map[string]registry.Tool)
+	for _, t := range snapshot {
+		namespaced := s.names.name(t.PluginID, t.Name)
+		if owner, taken := desired[namespaced]; taken {
+			s.logger.Warn("sync:name-collision", "tool", namespaced, "plugin", t.PluginID, "exposedBy", owner.PluginID)
+			continue
+		}
+		desired[namespaced] = t
+
+		prev, ok := s.registered[namespaced]
+		if ok && prev.PluginID == t.PluginID && prev.Name == t.Name && (!toolChanged(prev, t) || s.updates == "ignore") {
+			continue
+		}
+		if ok {
+			s.logger.Info("sync:update", "tool", namespaced)
+		}
+
+		// add tool with the backend's input schema, falling back to a
+		// simple object schema. AddTool replaces an existing tool of the
+		// same name
+		tool := &mcp.Tool{
+			Name:        namespaced,
+			Description: t.Description,
+			InputSchema: inputSchema(t),
+		}
+
+		// Only record tools the SDK accepted, failed ones are retried
+		// on the next snapshot
+		if err := s.add(s.sdk, tool, s.handler(t.PluginID, t.Name)); err != nil {
+			s.logger.Warn("sync:add-fail", "tool", namespaced, "err", err)
+			continue
+		}
+		s.registered[namespaced] = t
+	}
+
+	// remove tools that are no longer present
+	var toRemove []string
+	for name := range s.registered {
+		if _, ok := desired[name]; !ok {
+			toRemove = append(toRemove, name)
+		}
+	}
+	if len(toRemove) > 0 {
+		s.sdk.RemoveTools(toRemove...)
+		for _, n := range toRemove {
+			delete(s.registered, n)
+		}
+	}
+}
+
+// toolChanged reports whether the exposed definition of a tool changed
+func toolChanged(a, b registry.Tool) bool {
+	return a.Description != b.Description || !bytes.Equal(a.InputSchema, b.InputSchema)
+}
+
+// inputSchema returns the schema to expose for t, the SDK requires an object
+// schema so anything else is replaced by an empty object schema
+func inputSchema(t registry.Tool) any {
+	var schema map[string]any
+	if err := json.Unmarshal(t.InputSchema, &schema); err != nil || schema["type"] != "object" {
+		return map[string]any{"type": "object"}
+	}
+	return schema
+}
+
+// reconcile compares the tools the SDK server actually exposes with the
+// registry and repairs any drift
+func (s *toolSync) reconcile(snapshot []registry.Tool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), reconcileTimeout)
+	defer cancel()
+	actual, err := s.exposedTools(ctx)
+	if err != nil {
+		s.logger.Warn("sync:reconcile-fail", "err", err)
+		return
+	}
+
+	desired := make(map[string]bool, len(snapshot))
+	for _, t := range snapshot {
+		desired[s.names.name(t.PluginID, t.Name)] = true
+	}
+
+	// Forget tools missing from the SDK so applyLocked adds them again
+	missing := 0
+	for name := range desired {
+		if !actual[name] {
+			delete(s.registered, name)
+			missing++
+		}
+	}
+
+	var stale []string
+	for name := range actual {
+		if !desired[name] {
+			stale = append(stale, name)
+		}
+	}
+	if len(stale) > 0 {
+		s.sdk.RemoveTools(stale...)
+		for _, name := range stale {
+			delete(s.registered, name)
+		}
+	}
+
+	if missing > 0 || len(stale) > 0 {
+		s.logger.Warn("sync:drift", "missing", missing, "stale", len(stale))
+	}
+	s.applyLocked(snapshot)
+}
+
+// reconcileTimeout bounds listing the SDK server's tools
+const reconcileTimeout = 10 * time.Second
+
+// exposedTools lists the SDK server's tools through an in-memory client
+// session, connected on first use
+func (s *toolSync) exposedTools(ctx context.Context) (map[string]bool, error) {
+	if s.inspector == nil {
+		clientT, serverT := mcp.NewInMemoryTransports()
+		ss, err := s.sdk.Connect(ctx, serverT, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect inspector: %w", err)
+		}
+		impl := &mcp.Implementation{Name: "mcp-hub-reconcile", Version: "0.1.0"}
+		cs, err := mcp.NewClient(impl, nil).Connect(ctx, clientT, nil)
+		if err != nil {
+			ss.Close()
+			return nil, fmt.Errorf("failed to connect inspector: %w", err)
+		}
+		s.inspector = cs
+		s.inspectorSession.Store(ss)
+	}
+
+	tools := make(map[string]bool)
+	for tool, err := range s.inspector.Tools(ctx, nil) {
+		if err != nil {
+			return nil, err
+		}
+		tools[tool.Name] = true
+	}
+	return tools, nil
+}
+
+// isInspector reports whether req comes from the reconciliation session
+func (s *toolSync) isInspector(req mcp.Request) bool {
+	ss := s.inspectorSession.Load()
+	return ss != nil && req.GetSession() == ss
+}
+
+// isInspectorSession reports whether ss is the reconciliation session
+func (s *toolSync) isInspectorSession(ss *mcp.ServerSession) bool {
+	return ss != nil && s.inspectorSession.Load() == ss
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/plugin"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestForwardedToolKeepsInputSchema(t *testing.T) {
+	reg := registry.New()
+	reg.RegisterTools("github", []registry.Tool{
+		{
+			ID:          "search",
+			Name:        "search",
+			InputSchema: []byte(`{"type":"object","properties":{"query":{"type":"string"},"limit":{"type":"integer"}},"required":["query"]}`),
+		},
+		{ID: "bare", Name: "bare", InputSchema: []byte(`{"type":"string"}`)},
+	})
+	session := connect(t, newTestHub(reg), "")
+	tools := waitForTools(t, session, 2)
+
+	search, ok := tools["github:search"]
+	if !ok {
+		t.Fatalf("github:search not exposed, got %v", tools)
+	}
+	schema, ok := search.InputSchema.(map[string]any)
+	if !ok {
+		t.Fatalf("input schema %T", search.InputSchema)
+	}
+	required, _ := schema["required"].([]any)
+	if !slices.Equal(required, []any{"query"}) {
+		t.Errorf("required = %v, want [query]", schema["required"])
+	}
+	props, _ := schema["properties"].(map[string]any)
+	if _, ok := props["limit"]; !ok {
+		t.Errorf("properties = %v", schema["properties"])
+	}
+
+	// Schemas the SDK can't expose fall back to an empty object
+	if bare, ok := tools["github:bare"]; !ok {
+		t.Error("github:bare not exposed")
+	} else if schema, _ := bare.InputSchema.(map[string]any); schema["type"] != "object" || len(schema) != 1 {
+		t.Errorf("fallback schema = %v", bare.InputSchema)
+	}
+}
+
+// newTestSync returns a tool sync onto a fresh SDK server, its tools
+// answer with an empty result
+func newTestSync() *toolSync {
+	reg := registry.New()
+	pm := plugin.NewManager(reg, plugin.WithLogger(testLogger))
+	sdk := mcp.NewServer(&mcp.Implementation{Name: "test"}, nil)
+	handler := func(string, string) mcp.ToolHandler {
+		return func(context.Context, *mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+			return &mcp.CallToolResult{}, nil
+		}
+	}
+	return newToolSync(sdk, handler, newToolNames(pm, reg, ""), "", testLogger)
+}
+
+func TestSyncRetriesFailedAddTool(t *testing.T) {
+	sync := newTestSync()
+	sync.add = func(s *mcp.Server, tool *mcp.Tool, h mcp.ToolHandler) error {
+		if tool.Name == "github:broken" {
+			return errors.New("rejected")
+		}
+		return addTool(s, tool, h)
+	}
+
+	snapshot := []registry.Tool{
+		{PluginID: "github", ID: "search", Name: "search"},
+		{PluginID: "github", ID: "broken", Name: "broken"},
+	}
+	sync.apply(snapshot)
+	if _, ok := sync.registered["github:broken"]; ok {
+		t.Fatal("rejected tool recorded as registered")
+	}
+	if _, ok := sync.registered["github:search"]; !ok {
+		t.Fatal("github:search not registered")
+	}
+
+	// The next snapshot adds the tool once the SDK accepts it
+	sync.add = addTool
+	sync.apply(snapshot)
+	if _, ok := sync.registered["github:broken"]; !ok {
+		t.Fatal("rejected tool not retried")
+	}
+
+	// Invalid tools make the SDK panic, addTool turns that into an error
+	if err := addTool(sync.sdk, &mcp.Tool{Name: "no-schema"}, sync.handler("", "")); err == nil {
+		t.Error("addTool accepted a tool without an input schema")
+	}
+}
+
+func TestSyncUpdatesChangedSchema(t *testing.T) {
+	reg := registry.New()
+	reg.RegisterTools("github", []registry.Tool{
+		{ID: "search", Name: "search", InputSchema: []byte(`{"type":"object","properties":{"query":{"type":"string"}}}`)},
+	})
+	session := connect(t, newTestHub(reg), "")
+	waitForTools(t, session, 1)
+
+	// A reconnect lists the tool again with another schema
+	reg.RegisterTools("github", []registry.Tool{
+		{ID: "search", Name: "search", Description: "Search issues", InputSchema: []byte(`{"type":"object","properties":{"q":{"type":"string"}}}`)},
+	})
+	for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(20 * time.Millisecond) {
+		tool := waitForTools(t, session, 1)["github:search"]
+		props, _ := tool.InputSchema.(map[string]any)["properties"].(map[string]any)
+		if _, ok := props["q"]; ok && tool.Description == "Search issues" {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("exposed tool not updated: %q %v", tool.Description, tool.InputSchema)
+		}
+	}
+}
+
+func TestReconcileRepairsDrift(t *testing.T) {
+	sync := newTestSync()
+	snapshot := []registry.Tool{
+		{PluginID: "github", ID: "search", Name: "search"},
+		{PluginID: "files", ID: "read", Name: "read"},
+	}
+	sync.apply(snapshot)
+
+	// Drift the SDK server away from the registry behind the sync's back: a
+	// tool goes missing and a stale one shows up
+	sync.sdk.RemoveTools("github:search")
+	addTool(sync.sdk, &mcp.Tool{Name: "old:tool", InputSchema: map[string]any{"type": "object"}}, sync.handler("old", "tool"))
+
+	sync.reconcile(snapshot)
+	exposed, err := sync.exposedTools(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := keys(exposed)
+	slices.Sort(got)
+	if !slices.Equal(got, []string{"files:read", "github:search"}) {
+		t.Errorf("exposed tools after reconciling = %v", got)
+	}
+}
@@ -0,0 +1,56 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// timingTransport sets the timing debug header on every request
+type timingTransport struct{}
+
+func (timingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set(timingHeader, "1")
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func TestTimingMeta(t *testing.T) {
+	reg := registry.New()
+	pm := newTestManager(reg)
+	startBackend(t, pm, "files", "read")
+	hub := newHub(reg, pm)
+	plain := connect(t, hub, "")
+	waitForTools(t, plain, 1)
+	callTool(t, plain, "files:read")
+
+	debug := connectWith(t, hub, "", &http.Client{Transport: timingTransport{}})
+
+	tests := []struct {
+		name    string
+		session *mcp.ClientSession
+		want    bool
+	}{
+		{"without header", plain, false},
+		{"with header", debug, true},
+	}
+	for _, tt := range tests {
+		res, err := tt.session.CallTool(context.Background(), &mcp.CallToolParams{Name: "files:read"})
+		if err != nil {
+			t.Fatalf("%s: call: %v", tt.name, err)
+		}
+		timing, ok := res.Meta[timingMetaKey].(map[string]any)
+		if ok != tt.want {
+			t.Errorf("%s: timing meta %v, want present %v", tt.name, res.Meta, tt.want)
+			continue
+		}
+		for _, key := range []string{"queueMs", "callMs", "processMs", "overheadMs", "totalMs"} {
+			if _, found := timing[key]; tt.want && !found {
+				t.Errorf("%s: timing meta without %s: %v", tt.name, key, timing)
+			}
+		}
+	}
+}
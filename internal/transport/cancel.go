@@ -0,0 +1,31 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/mcp"
+)
+
+// cancelNotifyTimeout bounds sending notifications/cancelled after the
+// caller gave up on a request
+const cancelNotifyTimeout = 5 * time.Second
+
+// notifyCancelled tells the server to abandon the request encoded in
+// reqBytes, so it stops work nobody waits for any more. It is best effort and
+// a no-op for messages without an ID, which get no response anyway.
+func notifyCancelled(send func(context.Context, interface{}) error, reqBytes []byte, reason error) {
+	var req mcp.JSONRPCRequest
+	if err := json.Unmarshal(reqBytes, &req); err != nil || req.ID == nil {
+		return
+	}
+	params, err := json.Marshal(map[string]interface{}{"requestId": req.ID, "reason": reason.Error()})
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cancelNotifyTimeout)
+	defer cancel()
+	_ = send(ctx, mcp.JSONRPCNotification{JSONRPC: "2.0", Method: "notifications/cancelled", Params: params})
+}
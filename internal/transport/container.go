@@ -0,0 +1,43 @@
+package transport
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// invalidContainerChars are the characters container runtimes reject in
+// container names
+var invalidContainerChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// ContainerName returns a name for a new container of the named server, e.g.
+// mcp-hub-github-3f9a1c2e. The random suffix keeps it unique across
+// restarts, the prefix lets operators find the hub's containers in ps output.
+func ContainerName(server string) string {
+	server = strings.Trim(invalidContainerChars.ReplaceAllString(server, "-"), "-._")
+	if server == "" {
+		server = "server"
+	}
+	suffix := make([]byte, 4)
+	_, _ = rand.Read(suffix)
+	return "mcp-hub-" + server + "-" + hex.EncodeToString(suffix)
+}
+
+// CIDFile returns the path passed to --cidfile for a container. The runtime
+// refuses to start if the file exists, so it is only created by the runtime.
+func CIDFile(container string) string {
+	return filepath.Join(os.TempDir(), container+".cid")
+}
+
+// ReadContainerID returns the ID the runtime wrote to a --cidfile, or "" if
+// the container hasn't been created yet
+func ReadContainerID(cidfile string) string {
+	data, err := os.ReadFile(cidfile)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
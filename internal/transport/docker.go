@@ -6,7 +6,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
+	"os"
 	"os/exec"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -16,22 +19,71 @@ import (
 
 // DockerTransport implements stdio-based MCP transport via Docker containers
 type DockerTransport struct {
-	image        string
-	args         []string
-	env          map[string]string
-	volumes      map[string]string // host:container path mappings
-	network      string
-	removeOnExit bool
-	timeout      time.Duration
-
-	cmd         *exec.Cmd
-	containerID string
-	stdin       io.WriteCloser
-	stdout      io.ReadCloser
-	reader      *bufio.Reader
-	mu          sync.Mutex
-	requestID   int
-	connected   bool
+	image          string
+	args           []string
+	env            map[string]string
+	volumes        map[string]string // host:container path mappings
+	network        string
+	runtime        string // container CLI, docker unless set
+	memory         string
+	cpus           float64
+	pidsLimit      int
+	removeOnExit   bool
+	timeout        time.Duration
+	envPassthrough []string // host environment variables passed to the container
+
+	handshake
+	notifications
+
+	cmd           *exec.Cmd
+	containerName string // --name of the running container
+	cidfile       string // where the runtime writes the container ID
+	containerID   string // read from cidfile once the container exists
+	stdin         io.WriteCloser
+	stdout        io.ReadCloser
+	reader        *bufio.Reader
+	mu            sync.Mutex
+	requestID     int
+	connected     bool
+}
+
+// SetEnvPassthrough passes the host environment variables matching patterns
+// into the container, which otherwise only sees its configured env. It must
+// be called before Start.
+func (t *DockerTransport) SetEnvPassthrough(patterns []string) {
+	t.envPassthrough = patterns
+}
+
+// SetRuntime selects the container CLI, "docker" (default), "podman" or
+// "nerdctl", which take the same run arguments. It must be called before
+// Start.
+func (t *DockerTransport) SetRuntime(runtime string) {
+	if runtime != "" {
+		t.runtime = runtime
+	}
+}
+
+// SetLimits caps the container's memory (a docker quantity such as "512m"),
+// CPUs and number of processes. Zero values leave a resource unlimited. It
+// must be called before Start.
+func (t *DockerTransport) SetLimits(memory string, cpus float64, pidsLimit int) {
+	t.memory, t.cpus, t.pidsLimit = memory, cpus, pidsLimit
+}
+
+// LimitArgs returns the run arguments applying resource limits, skipping
+// unset ones
+func LimitArgs(memory string, cpus float64, pidsLimit int) []string {
+	var args []string
+	if memory != "" {
+		args = append(args, "--memory", memory)
+	}
+	if cpus > 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(cpus, 'f', -1, 64))
+	}
+	if pidsLimit > 0 {
+		args = append(args, "--pids-limit", strconv.Itoa(pidsLimit))
+	}
+	return args
 }
 
 // NewDockerTransport creates a new Docker-based transport
@@ -45,6 +97,7 @@ func NewDockerTransport(image string, args []string, env, volumes map[string]str
 		env:          env,
 		volumes:      volumes,
 		network:      network,
+		runtime:      "docker",
 		removeOnExit: true,
 		timeout:      timeout,
 	}
@@ -59,15 +112,29 @@ func (t *DockerTransport) Start(ctx context.Context) error {
 		return fmt.Errorf("transport already started")
 	}
 
-	// Build docker run command
-	dockerArgs := []string{"run", "-i"}
+	// Fail clearly rather than with exec's "executable file not found"
+	runtime, err := exec.LookPath(t.runtime)
+	if err != nil {
+		return fmt.Errorf("%s not found in PATH", t.runtime)
+	}
+
+	// Build docker run command, named after the image so the container can
+	// be found and stopped even if the CLI process is gone
+	t.containerName = ContainerName(imageName(t.image))
+	t.cidfile = CIDFile(t.containerName)
+	t.containerID = ""
+	dockerArgs := []string{"run", "-i", "--name", t.containerName, "--cidfile", t.cidfile}
 
 	// Add --rm for automatic cleanup
 	if t.removeOnExit {
 		dockerArgs = append(dockerArgs, "--rm")
 	}
 
-	// Add environment variables
+	// Add environment variables, inherited ones by name so docker copies the
+	// host value, configured values win
+	for _, name := range HostEnvNames(t.envPassthrough) {
+		dockerArgs = append(dockerArgs, "-e", name)
+	}
 	for k, v := range t.env {
 		dockerArgs = append(dockerArgs, "-e", fmt.Sprintf("%s=%s", k, v))
 	}
@@ -82,13 +149,16 @@ func (t *DockerTransport) Start(ctx context.Context) error {
 		dockerArgs = append(dockerArgs, "--network", t.network)
 	}
 
+	// Add resource limits
+	dockerArgs = append(dockerArgs, LimitArgs(t.memory, t.cpus, t.pidsLimit)...)
+
 	// Add image
 	dockerArgs = append(dockerArgs, t.image)
 
 	// Add command args
 	dockerArgs = append(dockerArgs, t.args...)
 
-	t.cmd = exec.CommandContext(ctx, "docker", dockerArgs...)
+	t.cmd = exec.CommandContext(ctx, runtime, dockerArgs...)
 
 	// Set up pipes
 	stdin, err := t.cmd.StdinPipe()
@@ -122,17 +192,18 @@ func (t *DockerTransport) Start(ctx context.Context) error {
 	go func() {
 		scanner := bufio.NewScanner(stderr)
 		for scanner.Scan() {
-			// Log stderr output (can be improved with proper logging)
-			fmt.Printf("[docker:%s] %s\n", t.image, scanner.Text())
+			t.log().Info("docker:stderr", "image", t.image, "line", scanner.Text())
 		}
 	}()
 
 	// Monitor process in background
+	cidfile := t.cidfile
 	go func() {
 		_ = t.cmd.Wait()
 		t.mu.Lock()
 		t.connected = false
 		t.mu.Unlock()
+		os.Remove(cidfile)
 	}()
 
 	return nil
@@ -166,7 +237,7 @@ func (t *DockerTransport) SendRequest(ctx context.Context, req interface{}) (jso
 	errorChan := make(chan error, 1)
 
 	go func() {
-		line, err := t.reader.ReadBytes('\n')
+		line, err := readResponse(t.reader, &t.notifications)
 		if err != nil {
 			errorChan <- fmt.Errorf("failed to read response: %w", err)
 			return
@@ -188,6 +259,7 @@ func (t *DockerTransport) SendRequest(ctx context.Context, req interface{}) (jso
 	case <-time.After(timeout):
 		return nil, fmt.Errorf("request timeout after %v", timeout)
 	case <-ctx.Done():
+		notifyCancelled(t.SendNotification, reqBytes, ctx.Err())
 		return nil, ctx.Err()
 	}
 }
@@ -215,6 +287,8 @@ func (t *DockerTransport) SendNotification(ctx context.Context, notification int
 
 // Close terminates the Docker container
 func (t *DockerTransport) Close() error {
+	t.closeNotifications()
+
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -241,17 +315,41 @@ func (t *DockerTransport) Close() error {
 		if t.cmd.Process != nil {
 			_ = t.cmd.Process.Kill()
 		}
-		// Also try docker stop if we have container ID
-		if t.containerID != "" {
-			exec.Command("docker", "stop", t.containerID).Run()
+		// The CLI's exit doesn't stop the container, stop it by ID, or by
+		// name if the ID was never written
+		container := t.loadContainerID()
+		if container == "" {
+			container = t.containerName
 		}
+		exec.Command(t.runtime, "stop", container).Run()
 	case <-done:
 		// Container terminated gracefully
 	}
+	os.Remove(t.cidfile)
 
 	return nil
 }
 
+// loadContainerID reads the container ID once the runtime has written it.
+// The caller must hold t.mu.
+func (t *DockerTransport) loadContainerID() string {
+	if t.containerID == "" && t.cidfile != "" {
+		t.containerID = ReadContainerID(t.cidfile)
+	}
+	return t.containerID
+}
+
+// imageName returns the repository name of an image reference without its
+// registry, tag or digest, e.g. "server" for ghcr.io/org/server:1.2
+func imageName(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, "/"); i >= 0 {
+		image = image[i+1:]
+	}
+	image, _, _ = strings.Cut(image, ":")
+	return image
+}
+
 // IsConnected returns connection status
 func (t *DockerTransport) IsConnected() bool {
 	t.mu.Lock()
@@ -271,16 +369,9 @@ func (t *DockerTransport) NextRequestID() int {
 func (t *DockerTransport) Initialize(ctx context.Context) (*mcp.InitializeResult, error) {
 	reqID := t.NextRequestID()
 
-	initParams := mcp.InitializeParams{
-		ProtocolVersion: "2024-11-05",
-		Capabilities:    mcp.ClientCapabilities{},
-		ClientInfo: mcp.ClientInfo{
-			Name:    "mcp-hub",
-			Version: "0.1.0",
-		},
-	}
+	initParams := t.initializeParams()
 
-	req, err := mcp.NewRequest(reqID, "initialize", initParams)
+	req, err := mcp.NewRequest(t.formatID(reqID), "initialize", initParams)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create initialize request: %w", err)
 	}
@@ -303,6 +394,11 @@ func (t *DockerTransport) Initialize(ctx context.Context) (*mcp.InitializeResult
 	if err := json.Unmarshal(resp.Result, &result); err != nil {
 		return nil, fmt.Errorf("failed to parse initialize result: %w", err)
 	}
+	t.setRawInitializeResult(resp.Result)
+
+	if err := t.checkProtocolVersion(result.ProtocolVersion); err != nil {
+		return nil, err
+	}
 
 	// Send initialized notification
 	notif, err := mcp.NewNotification("notifications/initialized", nil)
@@ -327,8 +423,11 @@ func (t *DockerTransport) GetContainerInfo() map[string]string {
 		"connected": fmt.Sprintf("%v", t.connected),
 	}
 
-	if t.containerID != "" {
-		info["container_id"] = t.containerID
+	if t.containerName != "" {
+		info["container_name"] = t.containerName
+	}
+	if id := t.loadContainerID(); id != "" {
+		info["container_id"] = id
 	}
 
 	if len(t.args) > 0 {
@@ -337,3 +436,9 @@ func (t *DockerTransport) GetContainerInfo() map[string]string {
 
 	return info
 }
+
+// SetLogger sets the logger for transport events, logging.Default() if unset
+func (t *DockerTransport) SetLogger(logger *slog.Logger) {
+	t.handshake.SetLogger(logger)
+	t.notifications.logger = logger
+}
@@ -0,0 +1,43 @@
+package transport
+
+import (
+	"os"
+	"path"
+	"strings"
+)
+
+// Environ returns the environment of a backend process: the whole host
+// environment if inherit is set, otherwise the host's PATH and the host
+// variables matching one of the passthrough patterns, and env, whose values
+// win over inherited ones
+func Environ(inherit bool, passthrough []string, env map[string]string) []string {
+	var result []string
+	if inherit {
+		result = os.Environ()
+	} else {
+		for _, name := range HostEnvNames(append([]string{"PATH"}, passthrough...)) {
+			result = append(result, name+"="+os.Getenv(name))
+		}
+	}
+	for k, v := range env {
+		result = append(result, k+"="+v)
+	}
+	return result
+}
+
+// HostEnvNames returns the names of the host environment variables matching
+// one of patterns. Docker takes the values of names passed as "-e NAME" from
+// its own environment, which keeps them out of process listings.
+func HostEnvNames(patterns []string) []string {
+	var result []string
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, name); ok {
+				result = append(result, name)
+				break
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,57 @@
+package transport
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestEnviron(t *testing.T) {
+	t.Setenv("MCP_HUB_TEST_SECRET", "s3cret")
+	t.Setenv("MCP_HUB_TEST_AWS_REGION", "eu-west-1")
+	t.Setenv("PATH", "/usr/bin")
+	env := map[string]string{"TOKEN": "configured", "MCP_HUB_TEST_SECRET": "override"}
+
+	tests := []struct {
+		name        string
+		inherit     bool
+		passthrough []string
+		want        []string
+		notWant     []string
+	}{
+		{
+			name:    "inherit",
+			inherit: true,
+			want:    []string{"PATH=/usr/bin", "MCP_HUB_TEST_AWS_REGION=eu-west-1", "TOKEN=configured"},
+		},
+		{
+			name:    "isolated",
+			want:    []string{"PATH=/usr/bin", "TOKEN=configured", "MCP_HUB_TEST_SECRET=override"},
+			notWant: []string{"MCP_HUB_TEST_AWS_REGION=eu-west-1", "MCP_HUB_TEST_SECRET=s3cret"},
+		},
+		{
+			name:        "passthrough",
+			passthrough: []string{"MCP_HUB_TEST_AWS_*"},
+			want:        []string{"PATH=/usr/bin", "MCP_HUB_TEST_AWS_REGION=eu-west-1", "TOKEN=configured"},
+			notWant:     []string{"MCP_HUB_TEST_SECRET=s3cret"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Environ(tt.inherit, tt.passthrough, env)
+			for _, kv := range tt.want {
+				if !slices.Contains(got, kv) {
+					t.Errorf("%s missing from %v", kv, got)
+				}
+			}
+			for _, kv := range tt.notWant {
+				if slices.Contains(got, kv) {
+					t.Errorf("%s passed to the backend", kv)
+				}
+			}
+			// Configured values come last so they win over inherited ones
+			if i, j := slices.Index(got, "MCP_HUB_TEST_SECRET=override"), slices.Index(got, "MCP_HUB_TEST_SECRET=s3cret"); j > i {
+				t.Errorf("inherited value after the configured one in %v", got)
+			}
+		})
+	}
+}
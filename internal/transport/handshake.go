@@ -0,0 +1,166 @@
+package transport
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/amir-the-h/mcp-hub/internal/logging"
+	"github.com/amir-the-h/mcp-hub/internal/mcp"
+)
+
+// handshake holds the protocol settings shared by the custom transports
+type handshake struct {
+	experimental  map[string]interface{}
+	extra         map[string]interface{}
+	idFormat      string
+	versionPolicy string
+	logger        *slog.Logger
+
+	rawMu  sync.Mutex
+	rawMsg json.RawMessage // latest initialize result as received
+}
+
+// protocolVersion is the version requested during Initialize
+const protocolVersion = "2024-11-05"
+
+// supportedProtocolVersions are the versions a server may answer with that
+// the custom transports can talk
+var supportedProtocolVersions = []string{protocolVersion, "2025-03-26", "2025-06-18"}
+
+// SetExperimentalCapabilities sets the experimental capabilities advertised
+// during Initialize. It must be called before Initialize.
+func (h *handshake) SetExperimentalCapabilities(caps map[string]interface{}) {
+	h.experimental = caps
+}
+
+// SetInitializeExtra sets fields merged into the initialize params, for
+// backends expecting non-standard handshake data. Objects are merged
+// recursively, anything else replaces the standard value. It must be called
+// before Initialize.
+func (h *handshake) SetInitializeExtra(extra map[string]interface{}) {
+	h.extra = extra
+}
+
+// initializeParams builds the params for the initialize request
+func (h *handshake) initializeParams() interface{} {
+	params := h.standardParams()
+	if len(h.extra) == 0 {
+		return params
+	}
+
+	b, err := json.Marshal(params)
+	if err != nil {
+		return params
+	}
+	merged := make(map[string]interface{})
+	if err := json.Unmarshal(b, &merged); err != nil {
+		return params
+	}
+	mergeObject(merged, h.extra)
+	return merged
+}
+
+// mergeObject merges src into dst, recursing into objects present in both
+func mergeObject(dst, src map[string]interface{}) {
+	for k, v := range src {
+		if sub, ok := v.(map[string]interface{}); ok {
+			if existing, ok := dst[k].(map[string]interface{}); ok {
+				mergeObject(existing, sub)
+				continue
+			}
+		}
+		dst[k] = v
+	}
+}
+
+func (h *handshake) standardParams() mcp.InitializeParams {
+	return mcp.InitializeParams{
+		ProtocolVersion: protocolVersion,
+		Capabilities: mcp.ClientCapabilities{
+			Experimental: h.experimental,
+		},
+		ClientInfo: mcp.ClientInfo{
+			Name:    "mcp-hub",
+			Version: "0.1.0",
+		},
+	}
+}
+
+// SetIDFormat selects how request IDs are encoded on the wire: "int"
+// (default) or "string", for backends that reject the other form
+func (h *handshake) SetIDFormat(format string) {
+	h.idFormat = format
+}
+
+// formatID encodes a sequential request number in the configured ID format
+func (h *handshake) formatID(n int) interface{} {
+	if h.idFormat == "string" {
+		return strconv.Itoa(n)
+	}
+	return n
+}
+
+// SetVersionPolicy selects what happens when the server answers Initialize
+// with an unsupported protocol version: "strict" (default) fails Initialize,
+// "lenient" logs a warning and proceeds
+func (h *handshake) SetVersionPolicy(policy string) {
+	h.versionPolicy = policy
+}
+
+// SetLogger sets the logger for transport events, logging.Default() if unset
+func (h *handshake) SetLogger(logger *slog.Logger) {
+	h.logger = logger
+}
+
+// RawInitializeResult returns the latest initialize result exactly as the
+// server sent it, including fields InitializeResult doesn't know
+func (h *handshake) RawInitializeResult() json.RawMessage {
+	h.rawMu.Lock()
+	defer h.rawMu.Unlock()
+	return h.rawMsg
+}
+
+func (h *handshake) setRawInitializeResult(raw json.RawMessage) {
+	h.rawMu.Lock()
+	h.rawMsg = bytes.Clone(raw)
+	h.rawMu.Unlock()
+}
+
+// log returns the transport's logger
+func (h *handshake) log() *slog.Logger {
+	if h.logger == nil {
+		return logging.Default()
+	}
+	return h.logger
+}
+
+// checkProtocolVersion applies the version policy to the server's answer
+func (h *handshake) checkProtocolVersion(version string) error {
+	if slices.Contains(supportedProtocolVersions, version) {
+		return nil
+	}
+	if h.versionPolicy != "lenient" {
+		return fmt.Errorf("unsupported protocol version %q (requested %s)", version, protocolVersion)
+	}
+	h.log().Warn("handshake:unsupported-version", "version", version, "requested", protocolVersion)
+	return nil
+}
+
+// idKey returns a correlation key for a JSON-RPC ID that is stable across
+// marshaling, so an int sent as 1 matches the float64 1 decoded from the
+// response and string IDs never collide with numeric ones
+func idKey(id interface{}) (string, bool) {
+	if id == nil {
+		return "", false
+	}
+	b, err := json.Marshal(id)
+	if err != nil {
+		return "", false
+	}
+	return string(b), true
+}
@@ -0,0 +1,40 @@
+package transport
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestInitializeExtra(t *testing.T) {
+	var h handshake
+	h.SetExperimentalCapabilities(map[string]interface{}{"streaming": true})
+	h.SetInitializeExtra(map[string]interface{}{
+		"clientInfo":   map[string]interface{}{"vendor": "acme"},
+		"acme/session": "abc",
+	})
+
+	data, err := json.Marshal(h.initializeParams())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var params struct {
+		ProtocolVersion string `json:"protocolVersion"`
+		Session         string `json:"acme/session"`
+		Capabilities    struct {
+			Experimental map[string]any `json:"experimental"`
+		} `json:"capabilities"`
+		ClientInfo struct {
+			Name   string `json:"name"`
+			Vendor string `json:"vendor"`
+		} `json:"clientInfo"`
+	}
+	if err := json.Unmarshal(data, &params); err != nil {
+		t.Fatal(err)
+	}
+	if params.Session != "abc" || params.ClientInfo.Vendor != "acme" {
+		t.Errorf("extra fields missing from %s", data)
+	}
+	if params.ProtocolVersion != protocolVersion || params.ClientInfo.Name != "mcp-hub" || params.Capabilities.Experimental["streaming"] != true {
+		t.Errorf("standard fields lost in %s", data)
+	}
+}
@@ -0,0 +1,98 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/logging"
+)
+
+// HeaderProvider returns headers resolved at request time, e.g. rotating
+// credentials. They override configured headers of the same name.
+type HeaderProvider func(ctx context.Context) (map[string]string, error)
+
+// HeaderSource resolves one header value from an environment variable or the
+// output of a command
+type HeaderSource struct {
+	Env     string
+	Command []string
+	Prefix  string        // prepended to the value, e.g. "Bearer "
+	TTL     time.Duration // how long a command's output is reused
+}
+
+// NewHeaderProvider returns a provider resolving every header from its source
+// on each request, commands at most once per TTL
+func NewHeaderProvider(sources map[string]HeaderSource) HeaderProvider {
+	type cached struct {
+		value   string
+		expires time.Time
+	}
+	var mu sync.Mutex
+	cache := make(map[string]cached)
+
+	resolve := func(ctx context.Context, name string, src HeaderSource) (string, error) {
+		if len(src.Command) == 0 {
+			return os.Getenv(src.Env), nil
+		}
+
+		mu.Lock()
+		c, ok := cache[name]
+		mu.Unlock()
+		if ok && time.Now().Before(c.expires) {
+			return c.value, nil
+		}
+
+		var stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, src.Command[0], src.Command[1:]...)
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			// The command may echo the credential it failed to produce
+			return "", fmt.Errorf("command for header %s failed: %w: %s", name, err, logging.RedactText(strings.TrimSpace(stderr.String()), nil))
+		}
+		value := strings.TrimSpace(string(out))
+		if src.TTL > 0 {
+			mu.Lock()
+			cache[name] = cached{value: value, expires: time.Now().Add(src.TTL)}
+			mu.Unlock()
+		}
+		return value, nil
+	}
+
+	return func(ctx context.Context) (map[string]string, error) {
+		headers := make(map[string]string, len(sources))
+		for name, src := range sources {
+			value, err := resolve(ctx, name, src)
+			if err != nil {
+				return nil, err
+			}
+			headers[name] = src.Prefix + value
+		}
+		return headers, nil
+	}
+}
+
+// setHeaders sets the configured headers on req, then those of provider
+func setHeaders(req *http.Request, headers map[string]string, provider HeaderProvider) error {
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if provider == nil {
+		return nil
+	}
+	resolved, err := provider(req.Context())
+	if err != nil {
+		return logging.RedactError(fmt.Errorf("failed to resolve headers: %w", err), req.Header)
+	}
+	for k, v := range resolved {
+		req.Header.Set(k, v)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestSetHeadersRedactsErrors(t *testing.T) {
+	provider := NewHeaderProvider(map[string]HeaderSource{
+		"Authorization": {Command: []string{"sh", "-c", "echo 'refresh failed for token=abc123' >&2; exit 1"}},
+	})
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "http://example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = setHeaders(req, map[string]string{"X-Api-Key": "static-key"}, provider)
+	if err == nil {
+		t.Fatal("failing header command didn't fail the request")
+	}
+	msg := err.Error()
+	if strings.Contains(msg, "abc123") {
+		t.Errorf("error leaks the command's stderr: %s", msg)
+	}
+	if !strings.Contains(msg, "token=***") {
+		t.Errorf("error = %s, want the redacted stderr", msg)
+	}
+	if req.Header.Get("X-Api-Key") != "static-key" {
+		t.Error("configured header not set")
+	}
+}
+
+func TestSetHeadersProviderOverrides(t *testing.T) {
+	t.Setenv("MCP_HUB_TEST_TOKEN", "rotated")
+	provider := NewHeaderProvider(map[string]HeaderSource{
+		"Authorization": {Env: "MCP_HUB_TEST_TOKEN", Prefix: "Bearer "},
+	})
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, "http://example.com", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := setHeaders(req, map[string]string{"Authorization": "Bearer old"}, provider); err != nil {
+		t.Fatal(err)
+	}
+	if got := req.Header.Get("Authorization"); got != "Bearer rotated" {
+		t.Errorf("Authorization = %q", got)
+	}
+}
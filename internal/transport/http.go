@@ -19,10 +19,19 @@ type HTTPTransport struct {
 	headers map[string]string
 	timeout time.Duration
 
-	client    *http.Client
-	mu        sync.Mutex
-	requestID int
-	connected bool
+	handshake
+
+	client         *http.Client
+	headerProvider HeaderProvider
+	mu             sync.Mutex
+	requestID      int
+	connected      bool
+}
+
+// SetHeaderProvider sets headers resolved on every request, e.g. a rotating
+// Authorization token. It must be called before Start.
+func (t *HTTPTransport) SetHeaderProvider(p HeaderProvider) {
+	t.headerProvider = p
 }
 
 // NewHTTPTransport creates a new HTTP transport
@@ -35,7 +44,8 @@ func NewHTTPTransport(url string, headers map[string]string, timeout time.Durati
 		headers: headers,
 		timeout: timeout,
 		client: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: NewRateLimitTransport(nil, false),
 		},
 	}
 }
@@ -80,8 +90,8 @@ func (t *HTTPTransport) SendRequest(ctx context.Context, req interface{}) (json.
 
 	// Set headers
 	httpReq.Header.Set("Content-Type", "application/json")
-	for k, v := range t.headers {
-		httpReq.Header.Set(k, v)
+	if err := setHeaders(httpReq, t.headers, t.headerProvider); err != nil {
+		return nil, err
 	}
 
 	// Send request
@@ -128,8 +138,8 @@ func (t *HTTPTransport) sendRequestWithMethod(ctx context.Context, method string
 
 	// Set headers
 	httpReq.Header.Set("Content-Type", "application/json")
-	for k, v := range t.headers {
-		httpReq.Header.Set(k, v)
+	if err := setHeaders(httpReq, t.headers, t.headerProvider); err != nil {
+		return nil, err
 	}
 
 	// Send request
@@ -190,16 +200,9 @@ func (t *HTTPTransport) NextRequestID() int {
 func (t *HTTPTransport) Initialize(ctx context.Context) (*mcp.InitializeResult, error) {
 	reqID := t.NextRequestID()
 
-	initParams := mcp.InitializeParams{
-		ProtocolVersion: "2024-11-05",
-		Capabilities:    mcp.ClientCapabilities{},
-		ClientInfo: mcp.ClientInfo{
-			Name:    "mcp-hub",
-			Version: "0.1.0",
-		},
-	}
+	initParams := t.initializeParams()
 
-	req, err := mcp.NewRequest(reqID, "initialize", initParams)
+	req, err := mcp.NewRequest(t.formatID(reqID), "initialize", initParams)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create initialize request: %w", err)
 	}
@@ -232,6 +235,11 @@ func (t *HTTPTransport) Initialize(ctx context.Context) (*mcp.InitializeResult,
 	if err := json.Unmarshal(resp.Result, &result); err != nil {
 		return nil, fmt.Errorf("failed to parse initialize result: %w", err)
 	}
+	t.setRawInitializeResult(resp.Result)
+
+	if err := t.checkProtocolVersion(result.ProtocolVersion); err != nil {
+		return nil, err
+	}
 
 	// Send initialized notification
 	notif, err := mcp.NewNotification("notifications/initialized", nil)
@@ -241,7 +249,7 @@ func (t *HTTPTransport) Initialize(ctx context.Context) (*mcp.InitializeResult,
 
 	if err := t.SendNotification(ctx, notif); err != nil {
 		// Log but don't fail - some servers may not require this
-		fmt.Printf("warning: failed to send initialized notification: %v\n", err)
+		t.log().Warn("http:initialized-fail", "url", t.url, "err", err)
 	}
 
 	return &result, nil
@@ -0,0 +1,176 @@
+package transport
+
+import (
+	"bufio"
+	"encoding/json"
+	"log/slog"
+	"sync"
+
+	"github.com/amir-the-h/mcp-hub/internal/logging"
+)
+
+// Notification is a JSON-RPC notification received from the server
+type Notification struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// NotificationHandler receives server notifications, one at a time
+type NotificationHandler func(Notification)
+
+// defaultNotificationBuffer is how many notifications wait for the handler
+// unless configured otherwise
+const defaultNotificationBuffer = 256
+
+// notifications buffers server notifications and hands them to the handler
+// on a dedicated goroutine, so a flood of progress or log notifications
+// never holds up routing responses to waiting requests
+type notifications struct {
+	mu      sync.Mutex
+	handler NotificationHandler
+	size    int
+	policy  string
+	queue   []Notification
+	dropped int
+	wake    chan struct{}
+	stop    chan struct{}
+	logger  *slog.Logger
+}
+
+// SetNotificationHandler delivers server notifications to h. Up to
+// bufferSize notifications (default 256) are queued while h is busy. When
+// the queue is full, policy "drop" (default) discards the new notification
+// and "coalesce" replaces a queued notification of the same kind (method
+// and progress token) with it, dropping it only if there is none.
+func (n *notifications) SetNotificationHandler(h NotificationHandler, bufferSize int, policy string) {
+	if bufferSize <= 0 {
+		bufferSize = defaultNotificationBuffer
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.handler = h
+	n.size = bufferSize
+	n.policy = policy
+	if n.wake == nil {
+		n.wake = make(chan struct{}, 1)
+		n.stop = make(chan struct{})
+		go n.dispatch(n.wake, n.stop)
+	}
+}
+
+// push queues a notification without blocking the reader
+func (n *notifications) push(note Notification) {
+	n.mu.Lock()
+	if n.handler == nil {
+		n.mu.Unlock()
+		return
+	}
+	if len(n.queue) < n.size {
+		n.queue = append(n.queue, note)
+	} else if i := n.coalesceIndex(note); i >= 0 {
+		n.queue[i] = note
+	} else {
+		n.dropped++
+		if n.dropped == 1 || n.dropped%1000 == 0 {
+			logger := n.logger
+			if logger == nil {
+				logger = logging.Default()
+			}
+			logger.Warn("notify:drop", "method", note.Method, "dropped", n.dropped, "buffer", n.size)
+		}
+	}
+	wake := n.wake
+	n.mu.Unlock()
+
+	select {
+	case wake <- struct{}{}:
+	default:
+	}
+}
+
+// coalesceIndex returns the queued notification note may replace, -1 if none
+func (n *notifications) coalesceIndex(note Notification) int {
+	if n.policy != "coalesce" {
+		return -1
+	}
+	key := coalesceKey(note)
+	for i := len(n.queue) - 1; i >= 0; i-- {
+		if coalesceKey(n.queue[i]) == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// coalesceKey identifies notifications superseded by a later one, progress
+// is tracked per token
+func coalesceKey(note Notification) string {
+	var params struct {
+		ProgressToken json.RawMessage `json:"progressToken"`
+	}
+	_ = json.Unmarshal(note.Params, &params)
+	return note.Method + "\x00" + string(params.ProgressToken)
+}
+
+// dispatch runs the handler for queued notifications in order
+func (n *notifications) dispatch(wake, stop chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		case <-wake:
+		}
+		for {
+			n.mu.Lock()
+			if len(n.queue) == 0 {
+				n.mu.Unlock()
+				break
+			}
+			note, h := n.queue[0], n.handler
+			n.queue = n.queue[1:]
+			n.mu.Unlock()
+			h(note)
+		}
+	}
+}
+
+// closeNotifications stops the dispatcher, queued notifications are dropped
+func (n *notifications) closeNotifications() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.stop != nil {
+		close(n.stop)
+		n.stop, n.wake = nil, nil
+		n.queue = nil
+	}
+}
+
+// parseNotification reports whether a message read from the server is a
+// notification, a message with a method and without an ID
+func parseNotification(data []byte) (Notification, bool) {
+	var msg struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(data, &msg); err != nil || msg.Method == "" || (len(msg.ID) > 0 && string(msg.ID) != "null") {
+		return Notification{}, false
+	}
+	return Notification{Method: msg.Method, Params: msg.Params}, true
+}
+
+// readResponse reads lines until one that isn't a notification, handing the
+// notifications read on the way to n
+func readResponse(r *bufio.Reader, n *notifications) ([]byte, error) {
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil {
+			return nil, err
+		}
+		if note, ok := parseNotification(line); ok {
+			n.push(note)
+			continue
+		}
+		return line, nil
+	}
+}
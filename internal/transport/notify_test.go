@@ -0,0 +1,78 @@
+package transport
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+// floodBackend streams n progress notifications ahead of every tools/list
+// response
+func floodBackend(t *testing.T, n int) *SSETransport {
+	t.Helper()
+	srv := newSSEBackend(t, func(w http.ResponseWriter, resp []byte, stream chan<- string) {
+		w.WriteHeader(http.StatusAccepted)
+		var msg struct {
+			Result map[string]any `json:"result"`
+		}
+		json.Unmarshal(resp, &msg)
+		if _, ok := msg.Result["protocolVersion"]; !ok {
+			for i := 1; i <= n; i++ {
+				stream <- fmt.Sprintf(`{"jsonrpc":"2.0","method":"notifications/progress","params":{"progressToken":"t","progress":%d}}`, i)
+			}
+		}
+		stream <- string(resp)
+	})
+	return startSSE(t, srv)
+}
+
+func TestNotificationFlood(t *testing.T) {
+	const flood = 1000
+	for _, policy := range []string{"drop", "coalesce"} {
+		tr := floodBackend(t, flood)
+
+		// The handler is stuck until the response arrived
+		var mu sync.Mutex
+		var progress []float64
+		release := make(chan struct{})
+		tr.SetNotificationHandler(func(note Notification) {
+			<-release
+			var params struct {
+				Progress float64 `json:"progress"`
+			}
+			json.Unmarshal(note.Params, &params)
+			mu.Lock()
+			progress = append(progress, params.Progress)
+			mu.Unlock()
+		}, 16, policy)
+
+		start := time.Now()
+		listTools(t, tr)
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("%s: response took %v behind the notifications", policy, elapsed)
+		}
+		close(release)
+
+		// Wait for the dispatcher to drain the buffer
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			tr.notifications.mu.Lock()
+			queued := len(tr.notifications.queue)
+			tr.notifications.mu.Unlock()
+			if queued == 0 {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		if len(progress) == 0 || len(progress) > 17 {
+			t.Errorf("%s: handler got %d notifications, want at most the buffer and the one in progress", policy, len(progress))
+		} else if last := progress[len(progress)-1]; (last == flood) != (policy == "coalesce") {
+			t.Errorf("%s: last progress delivered = %v", policy, last)
+		}
+		mu.Unlock()
+	}
+}
@@ -0,0 +1,186 @@
+package transport
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/logging"
+)
+
+const (
+	defaultRateLimitRetries = 3
+	defaultRateLimitMaxWait = time.Minute
+)
+
+// RateLimitTransport is an http.RoundTripper honoring backend rate-limit
+// hints. A 429 answer is retried after its Retry-After delay, and with
+// Throttle set requests are held back while the backend reports its quota
+// as exhausted (X-RateLimit-Remaining: 0 until X-RateLimit-Reset).
+type RateLimitTransport struct {
+	Base http.RoundTripper
+	// Throttle pauses requests while the quota is exhausted
+	Throttle bool
+	// MaxRetries bounds 429 retries per request (default 3)
+	MaxRetries int
+	// MaxWait is the longest delay honored, longer ones return the 429 to
+	// the caller (default one minute)
+	MaxWait time.Duration
+	// Logger receives retry events (default logging.Default())
+	Logger *slog.Logger
+
+	mu           sync.Mutex
+	blockedUntil time.Time
+}
+
+// NewRateLimitTransport wraps base, or http.DefaultTransport if nil
+func NewRateLimitTransport(base http.RoundTripper, throttle bool) *RateLimitTransport {
+	return &RateLimitTransport{Base: base, Throttle: throttle}
+}
+
+func (t *RateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	maxRetries := t.MaxRetries
+	if maxRetries <= 0 {
+		maxRetries = defaultRateLimitRetries
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := t.wait(req); err != nil {
+			return nil, err
+		}
+
+		resp, err := base.RoundTrip(req)
+		if err != nil {
+			return nil, err
+		}
+		if t.Throttle {
+			t.observeQuota(resp.Header)
+		}
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= maxRetries {
+			return resp, nil
+		}
+
+		// Only requests whose body can be replayed are retried
+		retry, ok := rewind(req)
+		if !ok {
+			return resp, nil
+		}
+		delay, ok := retryAfter(resp.Header, time.Now())
+		if !ok {
+			delay = time.Second << attempt
+		}
+		if delay > t.maxWait() {
+			return resp, nil
+		}
+
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		t.log().Info("ratelimit:retry", "host", req.URL.Host, "attempt", attempt+1, "delay", delay)
+		t.block(time.Now().Add(delay))
+		req = retry
+	}
+}
+
+// log returns the transport's logger
+func (t *RateLimitTransport) log() *slog.Logger {
+	if t.Logger == nil {
+		return logging.Default()
+	}
+	return t.Logger
+}
+
+func (t *RateLimitTransport) maxWait() time.Duration {
+	if t.MaxWait > 0 {
+		return t.MaxWait
+	}
+	return defaultRateLimitMaxWait
+}
+
+// wait blocks until the backend accepts requests again or req is cancelled
+func (t *RateLimitTransport) wait(req *http.Request) error {
+	t.mu.Lock()
+	delay := time.Until(t.blockedUntil)
+	t.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-req.Context().Done():
+		return req.Context().Err()
+	}
+}
+
+// block holds back requests until the given time
+func (t *RateLimitTransport) block(until time.Time) {
+	t.mu.Lock()
+	if until.After(t.blockedUntil) {
+		t.blockedUntil = until
+	}
+	t.mu.Unlock()
+}
+
+// observeQuota blocks further requests while the reported quota is exhausted
+func (t *RateLimitTransport) observeQuota(h http.Header) {
+	if h.Get("X-RateLimit-Remaining") != "0" {
+		return
+	}
+	reset, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil || reset <= 0 {
+		return
+	}
+
+	// Reset is either a Unix timestamp or a delay in seconds
+	now := time.Now()
+	until := now.Add(time.Duration(reset) * time.Second)
+	if reset > 1_000_000_000 {
+		until = time.Unix(reset, 0)
+	}
+	if until.Sub(now) > t.maxWait() {
+		until = now.Add(t.maxWait())
+	}
+	t.block(until)
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date
+func retryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if at, err := http.ParseTime(v); err == nil {
+		return max(at.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// rewind returns a copy of req with a fresh body for retrying
+func rewind(req *http.Request) (*http.Request, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	retry := req.Clone(req.Context())
+	retry.Body = body
+	return retry, true
+}
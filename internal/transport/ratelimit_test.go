@@ -0,0 +1,142 @@
+package transport
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// rateLimitedBackend answers the first request with 429 and Retry-After and
+// records when each request arrived
+type rateLimitedBackend struct {
+	mu       sync.Mutex
+	arrivals []time.Time
+	bodies   []string
+}
+
+func (b *rateLimitedBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	b.mu.Lock()
+	b.arrivals = append(b.arrivals, time.Now())
+	b.bodies = append(b.bodies, string(body))
+	first := len(b.arrivals) == 1
+	b.mu.Unlock()
+
+	if first {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func TestRateLimitRetryAfter(t *testing.T) {
+	backend := &rateLimitedBackend{}
+	srv := httptest.NewServer(backend)
+	defer srv.Close()
+
+	rt := NewRateLimitTransport(nil, false)
+	rt.Logger = testLogger
+	client := &http.Client{Transport: rt}
+
+	resp, err := client.Post(srv.URL, "application/json", strings.NewReader(`{"id":1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want the retried request's 200", resp.StatusCode)
+	}
+
+	backend.mu.Lock()
+	defer backend.mu.Unlock()
+	if len(backend.arrivals) != 2 {
+		t.Fatalf("backend got %d requests, want 2", len(backend.arrivals))
+	}
+	if waited := backend.arrivals[1].Sub(backend.arrivals[0]); waited < 900*time.Millisecond {
+		t.Errorf("retried after %v, want the 1s Retry-After", waited)
+	}
+	if backend.bodies[1] != `{"id":1}` {
+		t.Errorf("retried body = %q", backend.bodies[1])
+	}
+}
+
+func TestRateLimitMaxWait(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	rt := NewRateLimitTransport(nil, false)
+	rt.Logger = testLogger
+	resp, err := (&http.Client{Transport: rt}).Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || hits != 1 {
+		t.Errorf("status = %d after %d requests, want the 429 returned without waiting", resp.StatusCode, hits)
+	}
+}
+
+func TestRateLimitThrottle(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		arrivals []time.Time
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		arrivals = append(arrivals, time.Now())
+		mu.Unlock()
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "1")
+	}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: NewRateLimitTransport(nil, true)}
+	for range 2 {
+		resp, err := client.Get(srv.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if waited := arrivals[1].Sub(arrivals[0]); waited < 900*time.Millisecond {
+		t.Errorf("second request sent after %v, want it held until the quota resets", waited)
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"", 0, false},
+		{"5", 5 * time.Second, true},
+		{now.Add(time.Minute).Format(http.TimeFormat), time.Minute, true},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0, true},
+		{"soon", 0, false},
+	}
+	for _, tt := range tests {
+		h := http.Header{}
+		if tt.value != "" {
+			h.Set("Retry-After", tt.value)
+		}
+		got, ok := retryAfter(h, now)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("retryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.ok)
+		}
+	}
+}
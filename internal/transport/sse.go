@@ -5,8 +5,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strings"
 	"sync"
@@ -21,15 +23,26 @@ type SSETransport struct {
 	headers map[string]string
 	timeout time.Duration
 
-	client     *http.Client
-	sseConn    *http.Response
-	mu         sync.Mutex
-	requestID  int
-	connected  bool
-	responses  map[int]chan json.RawMessage
-	responseMu sync.Mutex
-	ctx        context.Context
-	cancel     context.CancelFunc
+	handshake
+	notifications
+
+	client         *http.Client
+	headerProvider HeaderProvider
+	sseConn        *http.Response
+	maxEventSize   int
+	mu             sync.Mutex
+	requestID      int
+	connected      bool
+	responses      map[string]chan json.RawMessage
+	responseMu     sync.Mutex
+	ctx            context.Context
+	cancel         context.CancelFunc
+
+	// Reconnecting after the stream dropped, see SetReconnect
+	reconnectAttempts int
+	reconnectDelay    time.Duration
+	initialized       bool          // replay the handshake after reconnecting
+	reconnected       chan struct{} // closed once a reconnect ends, nil otherwise
 }
 
 // NewSSETransport creates a new SSE transport
@@ -41,11 +54,48 @@ func NewSSETransport(baseURL string, headers map[string]string, timeout time.Dur
 	baseURL = strings.TrimSuffix(baseURL, "/sse")
 
 	return &SSETransport{
-		baseURL:   baseURL,
-		headers:   headers,
-		timeout:   timeout,
-		client:    &http.Client{Timeout: timeout},
-		responses: make(map[int]chan json.RawMessage),
+		baseURL:      baseURL,
+		headers:      headers,
+		timeout:      timeout,
+		client:       &http.Client{Timeout: timeout},
+		responses:    make(map[string]chan json.RawMessage),
+		maxEventSize: defaultMaxEventSize,
+
+		reconnectAttempts: defaultReconnectAttempts,
+		reconnectDelay:    defaultReconnectDelay,
+	}
+}
+
+const (
+	defaultReconnectAttempts = 5
+	defaultReconnectDelay    = time.Second
+	maxReconnectDelay        = 30 * time.Second
+)
+
+// SetReconnect sets how often the transport tries to reopen a dropped SSE
+// stream before giving up (0 disables reconnecting) and the delay before the
+// first attempt, doubling up to 30s between attempts
+func (t *SSETransport) SetReconnect(attempts int, delay time.Duration) {
+	t.reconnectAttempts = max(attempts, 0)
+	if delay > 0 {
+		t.reconnectDelay = delay
+	}
+}
+
+// SetHeaderProvider sets headers resolved on every request, e.g. a rotating
+// Authorization token. It must be called before Start.
+func (t *SSETransport) SetHeaderProvider(p HeaderProvider) {
+	t.headerProvider = p
+}
+
+// defaultMaxEventSize is the largest SSE line accepted by default
+const defaultMaxEventSize = 16 << 20
+
+// SetMaxEventSize sets the largest SSE line accepted from the server. Longer
+// events are dropped without closing the connection.
+func (t *SSETransport) SetMaxEventSize(n int) {
+	if n > 0 {
+		t.maxEventSize = n
 	}
 }
 
@@ -60,61 +110,99 @@ func (t *SSETransport) Start(ctx context.Context) error {
 
 	t.ctx, t.cancel = context.WithCancel(ctx)
 
-	// Establish SSE connection
+	resp, err := t.openStream()
+	if err != nil {
+		return err
+	}
+
+	t.sseConn = resp
+	t.connected = true
+	t.requestID = 0
+
+	// Start reading SSE events
+	go t.readSSEEvents(resp.Body)
+
+	return nil
+}
+
+// openStream opens the SSE stream
+func (t *SSETransport) openStream() (*http.Response, error) {
 	sseURL := t.baseURL + "/sse"
 	req, err := http.NewRequestWithContext(t.ctx, "GET", sseURL, nil)
 	if err != nil {
-		return fmt.Errorf("failed to create SSE request: %w", err)
+		return nil, fmt.Errorf("failed to create SSE request: %w", err)
 	}
 
 	req.Header.Set("Accept", "text/event-stream")
 	req.Header.Set("Cache-Control", "no-cache")
 	req.Header.Set("Connection", "keep-alive")
-	for k, v := range t.headers {
-		req.Header.Set(k, v)
+	if err := setHeaders(req, t.headers, t.headerProvider); err != nil {
+		return nil, err
 	}
 
-	resp, err := t.client.Do(req)
+	// The stream stays open, only the client's timeout must not apply
+	client := *t.client
+	client.Timeout = 0
+	resp, err := client.Do(req)
 	if err != nil {
-		return fmt.Errorf("failed to connect to SSE: %w", err)
+		return nil, fmt.Errorf("failed to connect to SSE: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return fmt.Errorf("SSE connection failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("SSE connection failed with status %d: %s", resp.StatusCode, string(body))
 	}
-
-	t.sseConn = resp
-	t.connected = true
-	t.requestID = 0
-
-	// Start reading SSE events
-	go t.readSSEEvents()
-
-	return nil
+	return resp, nil
 }
 
-// readSSEEvents reads and processes SSE events
-func (t *SSETransport) readSSEEvents() {
+// readSSEEvents reads and processes SSE events until the stream ends, then
+// starts reconnecting unless the transport was closed
+func (t *SSETransport) readSSEEvents(body io.Reader) {
 	defer func() {
 		t.mu.Lock()
 		t.connected = false
+		// A stream opened by a reconnect is retried by that reconnect
+		reconnect := t.ctx.Err() == nil && t.reconnectAttempts > 0 && t.reconnected == nil
+		if reconnect {
+			t.reconnected = make(chan struct{})
+		}
 		t.mu.Unlock()
+		if reconnect {
+			go t.reconnect()
+		}
 	}()
 
-	scanner := bufio.NewScanner(t.sseConn.Body)
+	reader := bufio.NewReader(body)
 	var eventData strings.Builder
-
-	for scanner.Scan() {
-		line := scanner.Text()
+	dropping := false
+
+	for {
+		line, err := readSSELine(reader, t.maxEventSize)
+		if errors.Is(err, errLineTooLong) {
+			// Skip the rest of this event but keep the stream open
+			t.log().Warn("sse:event-too-large", "url", t.baseURL, "maxEventSize", t.maxEventSize)
+			eventData.Reset()
+			dropping = true
+			continue
+		}
+		if err != nil {
+			if err != io.EOF && t.ctx.Err() == nil {
+				t.log().Error("sse:read-fail", "url", t.baseURL, "err", err)
+			}
+			return
+		}
 
 		// Empty line indicates end of event
 		if line == "" {
-			if eventData.Len() > 0 {
+			if eventData.Len() > 0 && !dropping {
 				t.handleSSEMessage(eventData.String())
-				eventData.Reset()
 			}
+			eventData.Reset()
+			dropping = false
+			continue
+		}
+		if dropping {
 			continue
 		}
 
@@ -125,26 +213,60 @@ func (t *SSETransport) readSSEEvents() {
 		}
 		// Ignore other SSE fields (event:, id:, retry:)
 	}
+}
 
-	if err := scanner.Err(); err != nil && t.ctx.Err() == nil {
-		fmt.Printf("SSE read error: %v\n", err)
+var errLineTooLong = errors.New("SSE line too long")
+
+// readSSELine reads one line without its line ending. A line longer than max
+// is consumed entirely and reported as errLineTooLong.
+func readSSELine(r *bufio.Reader, max int) (string, error) {
+	var line []byte
+	tooLong := false
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if !tooLong {
+			if len(line)+len(chunk) > max+2 {
+				tooLong = true
+				line = nil
+			} else {
+				line = append(line, chunk...)
+			}
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			return "", err
+		}
+		if tooLong {
+			return "", errLineTooLong
+		}
+		return strings.TrimRight(string(line), "\r\n"), nil
 	}
 }
 
 // handleSSEMessage processes a received SSE message
 func (t *SSETransport) handleSSEMessage(data string) {
+	// Notifications are queued for their own dispatcher, the reader moves
+	// straight on to the next event
+	if note, ok := parseNotification([]byte(data)); ok {
+		t.push(note)
+		return
+	}
+
 	var msg mcp.JSONRPCResponse
 	if err := json.Unmarshal([]byte(data), &msg); err != nil {
-		fmt.Printf("failed to parse SSE message: %v\n", err)
+		t.log().Warn("sse:bad-message", "url", t.baseURL, "err", err)
 		return
 	}
 
-	// Route response to waiting request
-	if msg.ID != nil {
+	// Route the full response envelope to the waiting request, like the
+	// other transports return it
+	if key, ok := idKey(msg.ID); ok {
 		t.responseMu.Lock()
-		if ch, ok := t.responses[int(msg.ID.(float64))]; ok {
+		if ch, ok := t.responses[key]; ok {
 			select {
-			case ch <- msg.Result:
+			case ch <- json.RawMessage(data):
 			default:
 			}
 		}
@@ -152,8 +274,110 @@ func (t *SSETransport) handleSSEMessage(data string) {
 	}
 }
 
-// SendRequest sends a JSON-RPC request via POST to /messages
+// reconnect reopens the dropped stream and replays the initialize handshake,
+// backing off between attempts. Requests wait for it in awaitConnected.
+func (t *SSETransport) reconnect() {
+	delay := t.reconnectDelay
+	for attempt := 1; attempt <= t.reconnectAttempts; attempt++ {
+		select {
+		case <-t.ctx.Done():
+			t.endReconnect(false)
+			return
+		case <-time.After(delay):
+		}
+
+		err := t.reopen()
+		if err == nil {
+			t.log().Info("sse:reconnect-ok", "url", t.baseURL, "attempt", attempt)
+			t.endReconnect(true)
+			return
+		}
+		delay = min(delay*2, maxReconnectDelay)
+		t.log().Warn("sse:reconnect-fail", "url", t.baseURL, "attempt", attempt, "err", err)
+	}
+	t.log().Error("sse:reconnect-give-up", "url", t.baseURL, "attempts", t.reconnectAttempts)
+	t.endReconnect(false)
+}
+
+// reopen opens a new stream and, if the transport had been initialized,
+// initializes the new session
+func (t *SSETransport) reopen() error {
+	resp, err := t.openStream()
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	t.sseConn = resp
+	t.connected = true
+	initialized := t.initialized
+	t.mu.Unlock()
+	go t.readSSEEvents(resp.Body)
+
+	if !initialized {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(t.ctx, t.timeout)
+	defer cancel()
+	if _, err := t.initialize(ctx); err != nil {
+		// The next attempt opens a new stream
+		resp.Body.Close()
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+	return nil
+}
+
+// endReconnect releases the requests waiting for a reconnect
+func (t *SSETransport) endReconnect(ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !ok {
+		t.connected = false
+	}
+	if t.reconnected != nil {
+		close(t.reconnected)
+		t.reconnected = nil
+	}
+}
+
+// awaitConnected waits for a reconnect in progress, up to the transport
+// timeout, and reports whether the transport is connected
+func (t *SSETransport) awaitConnected(ctx context.Context) error {
+	t.mu.Lock()
+	connected, reconnected := t.connected, t.reconnected
+	t.mu.Unlock()
+	if reconnected == nil {
+		if !connected {
+			return fmt.Errorf("transport not connected")
+		}
+		return nil
+	}
+
+	select {
+	case <-reconnected:
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(t.timeout):
+		return fmt.Errorf("transport reconnecting, not connected after %v", t.timeout)
+	}
+	if !t.IsConnected() {
+		return fmt.Errorf("transport not connected")
+	}
+	return nil
+}
+
+// SendRequest sends a JSON-RPC request via POST to /messages, waiting for a
+// reconnect in progress
 func (t *SSETransport) SendRequest(ctx context.Context, req interface{}) (json.RawMessage, error) {
+	if err := t.awaitConnected(ctx); err != nil {
+		return nil, err
+	}
+	return t.send(ctx, req)
+}
+
+// send posts a request without waiting for a reconnect, which is how the
+// reconnect replays the handshake
+func (t *SSETransport) send(ctx context.Context, req interface{}) (json.RawMessage, error) {
 	t.mu.Lock()
 	if !t.connected {
 		t.mu.Unlock()
@@ -172,18 +396,21 @@ func (t *SSETransport) SendRequest(ctx context.Context, req interface{}) (json.R
 		return nil, fmt.Errorf("failed to parse request: %w", err)
 	}
 
-	// Create response channel
+	// Create response channel, notifications have no ID and get no response
+	key, expectResponse := idKey(reqMsg.ID)
 	respCh := make(chan json.RawMessage, 1)
-	t.responseMu.Lock()
-	t.responses[int(reqMsg.ID.(float64))] = respCh
-	t.responseMu.Unlock()
-
-	defer func() {
+	if expectResponse {
 		t.responseMu.Lock()
-		delete(t.responses, int(reqMsg.ID.(float64)))
+		t.responses[key] = respCh
 		t.responseMu.Unlock()
-		close(respCh)
-	}()
+
+		defer func() {
+			t.responseMu.Lock()
+			delete(t.responses, key)
+			t.responseMu.Unlock()
+			close(respCh)
+		}()
+	}
 
 	// Send request to /messages endpoint
 	messagesURL := t.baseURL + "/messages"
@@ -193,8 +420,8 @@ func (t *SSETransport) SendRequest(ctx context.Context, req interface{}) (json.R
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
-	for k, v := range t.headers {
-		httpReq.Header.Set(k, v)
+	if err := setHeaders(httpReq, t.headers, t.headerProvider); err != nil {
+		return nil, err
 	}
 
 	resp, err := t.client.Do(httpReq)
@@ -203,23 +430,58 @@ func (t *SSETransport) SendRequest(ctx context.Context, req interface{}) (json.R
 	}
 	defer resp.Body.Close()
 
-	// For SSE, the POST to /messages might return 202 Accepted
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+	// For SSE, the POST to /messages might return 202 Accepted or 204 No Content
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted, http.StatusNoContent:
+	default:
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("HTTP error %d: %s", resp.StatusCode, string(body))
 	}
 
+	if !expectResponse {
+		return nil, nil
+	}
+
+	// Some servers answer inline in the POST body instead of over the stream
+	if resp.StatusCode == http.StatusOK {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		if inline, ok := inlineResponse(body, key); ok {
+			return inline, nil
+		}
+	}
+
 	// Wait for response via SSE
 	select {
 	case result := <-respCh:
 		return result, nil
 	case <-ctx.Done():
+		notifyCancelled(t.SendNotification, reqBytes, ctx.Err())
 		return nil, ctx.Err()
 	case <-time.After(t.timeout):
 		return nil, fmt.Errorf("request timeout")
 	}
 }
 
+// inlineResponse returns body if it is the JSON-RPC response for the request
+// identified by key
+func inlineResponse(body []byte, key string) (json.RawMessage, bool) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, false
+	}
+	var msg mcp.JSONRPCResponse
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, false
+	}
+	if k, ok := idKey(msg.ID); !ok || k != key {
+		return nil, false
+	}
+	return json.RawMessage(body), true
+}
+
 // SendNotification sends a JSON-RPC notification
 func (t *SSETransport) SendNotification(ctx context.Context, notification interface{}) error {
 	_, err := t.SendRequest(ctx, notification)
@@ -228,6 +490,8 @@ func (t *SSETransport) SendNotification(ctx context.Context, notification interf
 
 // Close closes the SSE transport
 func (t *SSETransport) Close() error {
+	t.closeNotifications()
+
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -240,6 +504,10 @@ func (t *SSETransport) Close() error {
 	}
 
 	t.connected = false
+	if t.reconnected != nil {
+		close(t.reconnected)
+		t.reconnected = nil
+	}
 	t.client.CloseIdleConnections()
 	return nil
 }
@@ -261,23 +529,30 @@ func (t *SSETransport) NextRequestID() int {
 
 // Initialize performs MCP initialization handshake
 func (t *SSETransport) Initialize(ctx context.Context) (*mcp.InitializeResult, error) {
+	if err := t.awaitConnected(ctx); err != nil {
+		return nil, err
+	}
+	result, err := t.initialize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	t.initialized = true
+	t.mu.Unlock()
+	return result, nil
+}
+
+func (t *SSETransport) initialize(ctx context.Context) (*mcp.InitializeResult, error) {
 	reqID := t.NextRequestID()
 
-	initParams := mcp.InitializeParams{
-		ProtocolVersion: "2024-11-05",
-		Capabilities:    mcp.ClientCapabilities{},
-		ClientInfo: mcp.ClientInfo{
-			Name:    "mcp-hub",
-			Version: "0.1.0",
-		},
-	}
+	initParams := t.initializeParams()
 
-	req, err := mcp.NewRequest(reqID, "initialize", initParams)
+	req, err := mcp.NewRequest(t.formatID(reqID), "initialize", initParams)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create initialize request: %w", err)
 	}
 
-	respBytes, err := t.SendRequest(ctx, req)
+	respBytes, err := t.send(ctx, req)
 	if err != nil {
 		return nil, fmt.Errorf("initialize request failed: %w", err)
 	}
@@ -295,6 +570,11 @@ func (t *SSETransport) Initialize(ctx context.Context) (*mcp.InitializeResult, e
 	if err := json.Unmarshal(resp.Result, &result); err != nil {
 		return nil, fmt.Errorf("failed to parse initialize result: %w", err)
 	}
+	t.setRawInitializeResult(resp.Result)
+
+	if err := t.checkProtocolVersion(result.ProtocolVersion); err != nil {
+		return nil, err
+	}
 
 	// Send initialized notification
 	notif, err := mcp.NewNotification("notifications/initialized", nil)
@@ -302,10 +582,16 @@ func (t *SSETransport) Initialize(ctx context.Context) (*mcp.InitializeResult, e
 		return nil, fmt.Errorf("failed to create initialized notification: %w", err)
 	}
 
-	if err := t.SendNotification(ctx, notif); err != nil {
+	if _, err := t.send(ctx, notif); err != nil {
 		// Log but don't fail - some servers may not require this
-		fmt.Printf("warning: failed to send initialized notification: %v\n", err)
+		t.log().Warn("sse:initialized-fail", "url", t.baseURL, "err", err)
 	}
 
 	return &result, nil
 }
+
+// SetLogger sets the logger for transport events, logging.Default() if unset
+func (t *SSETransport) SetLogger(logger *slog.Logger) {
+	t.handshake.SetLogger(logger)
+	t.notifications.logger = logger
+}
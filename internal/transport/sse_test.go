@@ -0,0 +1,170 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// sseBackend is a legacy SSE MCP server answering every request with an
+// empty result, or the requested protocol version for initialize
+type sseBackend struct {
+	// reply answers a posted request, given the response. It writes the
+	// POST's response and may send the JSON-RPC response over the stream.
+	reply  func(w http.ResponseWriter, resp []byte, stream chan<- string)
+	stream chan string
+}
+
+func newSSEBackend(t *testing.T, reply func(w http.ResponseWriter, resp []byte, stream chan<- string)) *httptest.Server {
+	t.Helper()
+	b := &sseBackend{reply: reply, stream: make(chan string, 16)}
+	srv := httptest.NewServer(b)
+	t.Cleanup(func() {
+		srv.CloseClientConnections()
+		srv.Close()
+	})
+	return srv
+}
+
+func (b *sseBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/sse":
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		for {
+			select {
+			case data := <-b.stream:
+				fmt.Fprintf(w, "data: %s\n\n", data)
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	case r.Method == http.MethodPost && r.URL.Path == "/messages":
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params struct {
+				ProtocolVersion string `json:"protocolVersion"`
+			} `json:"params"`
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if req.ID == nil {
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		result := map[string]any{}
+		if req.Method == "initialize" {
+			result = map[string]any{
+				"protocolVersion": req.Params.ProtocolVersion,
+				"capabilities":    map[string]any{},
+				"serverInfo":      map[string]any{"name": "sse-test", "version": "1.0"},
+			}
+		}
+		resp, _ := json.Marshal(map[string]any{"jsonrpc": "2.0", "id": req.ID, "result": result})
+		b.reply(w, resp, b.stream)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// startSSE connects and initializes a transport to srv
+func startSSE(t *testing.T, srv *httptest.Server) *SSETransport {
+	t.Helper()
+	tr := NewSSETransport(srv.URL+"/sse", nil, 2*time.Second)
+	tr.SetLogger(testLogger)
+	if err := tr.Start(context.Background()); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	t.Cleanup(func() { tr.Close() })
+	if _, err := tr.Initialize(context.Background()); err != nil {
+		t.Fatalf("initialize: %v", err)
+	}
+	return tr
+}
+
+// listTools sends a request with ID 42 and checks the response is its own
+func listTools(t *testing.T, tr *SSETransport) {
+	t.Helper()
+	raw, err := tr.SendRequest(context.Background(), map[string]any{"jsonrpc": "2.0", "id": 42, "method": "tools/list"})
+	if err != nil {
+		t.Fatalf("tools/list: %v", err)
+	}
+	var resp struct {
+		ID int `json:"id"`
+	}
+	if err := json.Unmarshal(raw, &resp); err != nil || resp.ID != 42 {
+		t.Fatalf("response %s, want id 42", raw)
+	}
+}
+
+func TestSSENoContent(t *testing.T) {
+	srv := newSSEBackend(t, func(w http.ResponseWriter, resp []byte, stream chan<- string) {
+		w.WriteHeader(http.StatusNoContent)
+		stream <- string(resp)
+	})
+	listTools(t, startSSE(t, srv))
+}
+
+func TestSSEInlineResponse(t *testing.T) {
+	// Nothing is sent over the stream, waiting on it would time out
+	srv := newSSEBackend(t, func(w http.ResponseWriter, resp []byte, stream chan<- string) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(resp)
+	})
+	listTools(t, startSSE(t, srv))
+}
+
+func TestSSELargeEvents(t *testing.T) {
+	// Every response over the stream comes after an unrelated 1MB event,
+	// and tools/list answers carry 100KB of padding
+	srv := newSSEBackend(t, func(w http.ResponseWriter, resp []byte, stream chan<- string) {
+		w.WriteHeader(http.StatusAccepted)
+		stream <- `{"jsonrpc":"2.0","method":"notifications/message","params":{"data":"` + strings.Repeat("x", 1<<20) + `"}}`
+		var msg struct {
+			JSONRPC string          `json:"jsonrpc"`
+			ID      json.RawMessage `json:"id"`
+			Result  map[string]any  `json:"result"`
+		}
+		json.Unmarshal(resp, &msg)
+		if _, ok := msg.Result["protocolVersion"]; !ok {
+			msg.Result["padding"] = strings.Repeat("y", 100<<10)
+			resp, _ = json.Marshal(msg)
+		}
+		stream <- string(resp)
+	})
+
+	tr := NewSSETransport(srv.URL+"/sse", nil, 2*time.Second)
+	tr.SetLogger(testLogger)
+	tr.SetMaxEventSize(512 << 10)
+	if err := tr.Start(context.Background()); err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	defer tr.Close()
+	if _, err := tr.Initialize(context.Background()); err != nil {
+		t.Fatalf("initialize: %v", err)
+	}
+
+	// Lines past 64KB are read whole, lines past the limit are dropped
+	// without losing the connection
+	for range 2 {
+		listTools(t, tr)
+	}
+	if !tr.IsConnected() {
+		t.Error("oversized event closed the connection")
+	}
+}
@@ -6,12 +6,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"os"
 	"os/exec"
 	"sync"
 	"time"
 
+	"github.com/amir-the-h/mcp-hub/internal/logging"
 	"github.com/amir-the-h/mcp-hub/internal/mcp"
 )
 
@@ -21,6 +22,12 @@ type StdioTransport struct {
 	args    []string
 	env     map[string]string
 	timeout time.Duration
+	// without inheritEnv only PATH and envPassthrough are passed on
+	inheritEnv     bool
+	envPassthrough []string
+
+	handshake
+	notifications
 
 	cmd       *exec.Cmd
 	stdin     io.WriteCloser
@@ -37,13 +44,29 @@ func NewStdioTransport(command string, args []string, env map[string]string, tim
 		timeout = 30 * time.Second
 	}
 	return &StdioTransport{
-		command: command,
-		args:    args,
-		env:     env,
-		timeout: timeout,
+		command:    command,
+		args:       args,
+		env:        env,
+		timeout:    timeout,
+		inheritEnv: true,
 	}
 }
 
+// SetInheritEnv selects whether the subprocess gets the whole host
+// environment besides its configured env (default true). Without it, it
+// only gets PATH and the variables set with SetEnvPassthrough. It must be
+// called before Start.
+func (t *StdioTransport) SetInheritEnv(inherit bool) {
+	t.inheritEnv = inherit
+}
+
+// SetEnvPassthrough passes the host environment variables matching patterns
+// to a subprocess that doesn't inherit the environment. It must be called
+// before Start.
+func (t *StdioTransport) SetEnvPassthrough(patterns []string) {
+	t.envPassthrough = patterns
+}
+
 // Start launches the subprocess and initializes stdio communication
 func (t *StdioTransport) Start(ctx context.Context) error {
 	t.mu.Lock()
@@ -56,10 +79,7 @@ func (t *StdioTransport) Start(ctx context.Context) error {
 	t.cmd = exec.CommandContext(ctx, t.command, t.args...)
 
 	// Set up environment
-	t.cmd.Env = os.Environ()
-	for k, v := range t.env {
-		t.cmd.Env = append(t.cmd.Env, fmt.Sprintf("%s=%s", k, v))
-	}
+	t.cmd.Env = Environ(t.inheritEnv, t.envPassthrough, t.env)
 
 	// Set up pipes
 	stdin, err := t.cmd.StdinPipe()
@@ -86,7 +106,7 @@ func (t *StdioTransport) Start(ctx context.Context) error {
 	t.connected = true
 	t.requestID = 0
 
-	log.Printf("stdio:started command=%s args=%v", t.command, t.args)
+	t.log().Info("stdio:started", "command", t.command, "args", t.args)
 
 	// Monitor process in background
 	go func() {
@@ -94,7 +114,7 @@ func (t *StdioTransport) Start(ctx context.Context) error {
 		t.mu.Lock()
 		t.connected = false
 		t.mu.Unlock()
-		log.Printf("stdio:process exited command=%s", t.command)
+		t.log().Info("stdio:exited", "command", t.command)
 	}()
 
 	return nil
@@ -115,12 +135,12 @@ func (t *StdioTransport) SendRequest(ctx context.Context, req interface{}) (json
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	// Log request (trimmed)
-	reqSnippet := string(reqBytes)
+	// Log request (redacted, then trimmed)
+	reqSnippet := string(logging.Redact(reqBytes))
 	if len(reqSnippet) > 200 {
 		reqSnippet = reqSnippet[:200] + "..."
 	}
-	log.Printf("stdio:send request len=%d snippet=%s", len(reqBytes), reqSnippet)
+	t.log().Debug("stdio:send", "len", len(reqBytes), "snippet", reqSnippet)
 
 	// Send request with newline delimiter
 	t.mu.Lock()
@@ -136,7 +156,7 @@ func (t *StdioTransport) SendRequest(ctx context.Context, req interface{}) (json
 
 	start := time.Now()
 	go func() {
-		line, err := t.reader.ReadBytes('\n')
+		line, err := readResponse(t.reader, &t.notifications)
 		if err != nil {
 			errorChan <- fmt.Errorf("failed to read response: %w", err)
 			return
@@ -153,13 +173,14 @@ func (t *StdioTransport) SendRequest(ctx context.Context, req interface{}) (json
 	select {
 	case resp := <-responseChan:
 		dur := time.Since(start)
-		log.Printf("stdio:recv response len=%d duration=%s", len(resp), dur)
+		t.log().Debug("stdio:recv", "len", len(resp), "duration", dur)
 		return resp, nil
 	case err := <-errorChan:
 		return nil, err
 	case <-time.After(timeout):
 		return nil, fmt.Errorf("request timeout after %v", timeout)
 	case <-ctx.Done():
+		notifyCancelled(t.SendNotification, reqBytes, ctx.Err())
 		return nil, ctx.Err()
 	}
 }
@@ -187,6 +208,8 @@ func (t *StdioTransport) SendNotification(ctx context.Context, notification inte
 
 // Close terminates the transport
 func (t *StdioTransport) Close() error {
+	t.closeNotifications()
+
 	t.mu.Lock()
 	defer t.mu.Unlock()
 
@@ -239,16 +262,9 @@ func (t *StdioTransport) NextRequestID() int {
 func (t *StdioTransport) Initialize(ctx context.Context) (*mcp.InitializeResult, error) {
 	reqID := t.NextRequestID()
 
-	initParams := mcp.InitializeParams{
-		ProtocolVersion: "2024-11-05",
-		Capabilities:    mcp.ClientCapabilities{},
-		ClientInfo: mcp.ClientInfo{
-			Name:    "mcp-hub",
-			Version: "0.1.0",
-		},
-	}
+	initParams := t.initializeParams()
 
-	req, err := mcp.NewRequest(reqID, "initialize", initParams)
+	req, err := mcp.NewRequest(t.formatID(reqID), "initialize", initParams)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create initialize request: %w", err)
 	}
@@ -271,6 +287,11 @@ func (t *StdioTransport) Initialize(ctx context.Context) (*mcp.InitializeResult,
 	if err := json.Unmarshal(resp.Result, &result); err != nil {
 		return nil, fmt.Errorf("failed to parse initialize result: %w", err)
 	}
+	t.setRawInitializeResult(resp.Result)
+
+	if err := t.checkProtocolVersion(result.ProtocolVersion); err != nil {
+		return nil, err
+	}
 
 	// Send initialized notification
 	notif, err := mcp.NewNotification("notifications/initialized", nil)
@@ -284,3 +305,9 @@ func (t *StdioTransport) Initialize(ctx context.Context) (*mcp.InitializeResult,
 
 	return &result, nil
 }
+
+// SetLogger sets the logger for transport events, logging.Default() if unset
+func (t *StdioTransport) SetLogger(logger *slog.Logger) {
+	t.handshake.SetLogger(logger)
+	t.notifications.logger = logger
+}
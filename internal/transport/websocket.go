@@ -0,0 +1,320 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/mcp"
+	"github.com/gorilla/websocket"
+)
+
+// WebSocketTransport implements MCP over a WebSocket, one JSON-RPC message
+// per text frame in both directions
+type WebSocketTransport struct {
+	url     string
+	headers map[string]string
+	timeout time.Duration
+
+	handshake
+	notifications
+
+	dialer         *websocket.Dialer
+	headerProvider HeaderProvider
+	conn           *websocket.Conn
+	writeMu        sync.Mutex // gorilla allows one concurrent writer
+	mu             sync.Mutex
+	requestID      int
+	connected      bool
+	responses      map[string]chan json.RawMessage
+	responseMu     sync.Mutex
+	ctx            context.Context
+	cancel         context.CancelFunc
+}
+
+// NewWebSocketTransport creates a new WebSocket transport for a ws:// or
+// wss:// URL
+func NewWebSocketTransport(url string, headers map[string]string, timeout time.Duration) *WebSocketTransport {
+	if timeout == 0 {
+		timeout = 30 * time.Second
+	}
+	return &WebSocketTransport{
+		url:     url,
+		headers: headers,
+		timeout: timeout,
+		dialer: &websocket.Dialer{
+			Proxy:            http.ProxyFromEnvironment,
+			HandshakeTimeout: timeout,
+			Subprotocols:     []string{"mcp"},
+		},
+		responses: make(map[string]chan json.RawMessage),
+	}
+}
+
+// SetHeaderProvider sets headers resolved when the connection is opened,
+// e.g. a rotating Authorization token. It must be called before Start.
+func (t *WebSocketTransport) SetHeaderProvider(p HeaderProvider) {
+	t.headerProvider = p
+}
+
+// Start opens the WebSocket connection
+func (t *WebSocketTransport) Start(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.connected {
+		return fmt.Errorf("transport already started")
+	}
+
+	header := http.Header{}
+	for k, v := range t.headers {
+		header.Set(k, v)
+	}
+	if t.headerProvider != nil {
+		resolved, err := t.headerProvider(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to resolve headers: %w", err)
+		}
+		for k, v := range resolved {
+			header.Set(k, v)
+		}
+	}
+
+	conn, resp, err := t.dialer.DialContext(ctx, t.url, header)
+	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("failed to connect to WebSocket: status %d: %w", resp.StatusCode, err)
+		}
+		return fmt.Errorf("failed to connect to WebSocket: %w", err)
+	}
+
+	// The connection outlives the start context, Close ends it
+	t.ctx, t.cancel = context.WithCancel(context.Background())
+	t.conn = conn
+	t.connected = true
+	t.requestID = 0
+
+	go t.readMessages(conn)
+
+	return nil
+}
+
+// readMessages routes incoming frames until the connection closes
+func (t *WebSocketTransport) readMessages(conn *websocket.Conn) {
+	defer func() {
+		t.mu.Lock()
+		t.connected = false
+		t.mu.Unlock()
+		t.cancel()
+	}()
+
+	for {
+		kind, data, err := conn.ReadMessage()
+		if err != nil {
+			if t.ctx.Err() == nil && !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				t.log().Warn("ws:read-fail", "url", t.url, "err", err)
+			}
+			return
+		}
+		if kind != websocket.TextMessage && kind != websocket.BinaryMessage {
+			continue
+		}
+		t.handleMessage(data)
+	}
+}
+
+// handleMessage processes one received message
+func (t *WebSocketTransport) handleMessage(data []byte) {
+	// Notifications are queued for their own dispatcher, the reader moves
+	// straight on to the next frame
+	if note, ok := parseNotification(data); ok {
+		t.push(note)
+		return
+	}
+
+	var msg mcp.JSONRPCResponse
+	if err := json.Unmarshal(data, &msg); err != nil {
+		t.log().Warn("ws:bad-message", "url", t.url, "err", err)
+		return
+	}
+
+	if key, ok := idKey(msg.ID); ok {
+		t.responseMu.Lock()
+		if ch, ok := t.responses[key]; ok {
+			select {
+			case ch <- json.RawMessage(data):
+			default:
+			}
+		}
+		t.responseMu.Unlock()
+	}
+}
+
+// write sends one message as a text frame
+func (t *WebSocketTransport) write(ctx context.Context, data []byte) error {
+	t.mu.Lock()
+	conn, connected := t.conn, t.connected
+	t.mu.Unlock()
+	if !connected {
+		return fmt.Errorf("transport not connected")
+	}
+
+	t.writeMu.Lock()
+	defer t.writeMu.Unlock()
+	deadline := time.Now().Add(t.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetWriteDeadline(deadline)
+	if err := conn.WriteMessage(websocket.TextMessage, data); err != nil {
+		return fmt.Errorf("failed to write message: %w", err)
+	}
+	return nil
+}
+
+// SendRequest sends a JSON-RPC request and waits for its response
+func (t *WebSocketTransport) SendRequest(ctx context.Context, req interface{}) (json.RawMessage, error) {
+	reqBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	var reqMsg mcp.JSONRPCRequest
+	if err := json.Unmarshal(reqBytes, &reqMsg); err != nil {
+		return nil, fmt.Errorf("failed to parse request: %w", err)
+	}
+
+	// Notifications have no ID and get no response
+	key, expectResponse := idKey(reqMsg.ID)
+	if !expectResponse {
+		return nil, t.write(ctx, reqBytes)
+	}
+
+	respCh := make(chan json.RawMessage, 1)
+	t.responseMu.Lock()
+	t.responses[key] = respCh
+	t.responseMu.Unlock()
+	defer func() {
+		t.responseMu.Lock()
+		delete(t.responses, key)
+		t.responseMu.Unlock()
+	}()
+
+	if err := t.write(ctx, reqBytes); err != nil {
+		return nil, err
+	}
+
+	select {
+	case result := <-respCh:
+		return result, nil
+	case <-ctx.Done():
+		notifyCancelled(t.SendNotification, reqBytes, ctx.Err())
+		return nil, ctx.Err()
+	case <-t.ctx.Done():
+		return nil, fmt.Errorf("connection closed")
+	case <-time.After(t.timeout):
+		return nil, fmt.Errorf("request timeout")
+	}
+}
+
+// SendNotification sends a JSON-RPC notification
+func (t *WebSocketTransport) SendNotification(ctx context.Context, notification interface{}) error {
+	data, err := json.Marshal(notification)
+	if err != nil {
+		return fmt.Errorf("failed to marshal notification: %w", err)
+	}
+	return t.write(ctx, data)
+}
+
+// Close sends a close frame and closes the connection
+func (t *WebSocketTransport) Close() error {
+	t.closeNotifications()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.cancel != nil {
+		t.cancel()
+	}
+	if t.conn == nil {
+		return nil
+	}
+
+	t.writeMu.Lock()
+	msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	_ = t.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+	t.writeMu.Unlock()
+
+	t.connected = false
+	return t.conn.Close()
+}
+
+// IsConnected returns connection status
+func (t *WebSocketTransport) IsConnected() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.connected
+}
+
+// NextRequestID generates a unique request ID
+func (t *WebSocketTransport) NextRequestID() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.requestID++
+	return t.requestID
+}
+
+// Initialize performs MCP initialization handshake
+func (t *WebSocketTransport) Initialize(ctx context.Context) (*mcp.InitializeResult, error) {
+	reqID := t.NextRequestID()
+
+	req, err := mcp.NewRequest(t.formatID(reqID), "initialize", t.initializeParams())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create initialize request: %w", err)
+	}
+
+	respBytes, err := t.SendRequest(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("initialize request failed: %w", err)
+	}
+
+	var resp mcp.JSONRPCResponse
+	if err := json.Unmarshal(respBytes, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse initialize response: %w", err)
+	}
+
+	if resp.Error != nil {
+		return nil, fmt.Errorf("initialize error: %s", resp.Error.Message)
+	}
+
+	var result mcp.InitializeResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse initialize result: %w", err)
+	}
+	t.setRawInitializeResult(resp.Result)
+
+	if err := t.checkProtocolVersion(result.ProtocolVersion); err != nil {
+		return nil, err
+	}
+
+	notif, err := mcp.NewNotification("notifications/initialized", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create initialized notification: %w", err)
+	}
+	if err := t.SendNotification(ctx, notif); err != nil {
+		// Log but don't fail - some servers may not require this
+		t.log().Warn("ws:initialized-fail", "url", t.url, "err", err)
+	}
+
+	return &result, nil
+}
+
+// SetLogger sets the logger for transport events, logging.Default() if unset
+func (t *WebSocketTransport) SetLogger(logger *slog.Logger) {
+	t.handshake.SetLogger(logger)
+	t.notifications.logger = logger
+}
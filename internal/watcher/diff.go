@@ -0,0 +1,110 @@
+package watcher
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+)
+
+// serverChange is how a changed server config can be applied
+type serverChange int
+
+const (
+	changeNone    serverChange = iota
+	changeHeaders              // only headers, set on the HTTP transport in place
+	changeLive                 // applied to the running connection
+	changeRestart              // needs a new connection
+)
+
+// liveFields are the server fields read per call or on the next reconnect,
+// which change without a new connection
+var liveFields = map[string]bool{
+	"timeout":          true,
+	"onTimeout":        true,
+	"optional":         true,
+	"standby":          true,
+	"failoverOn":       true,
+	"failoverTimeout":  true,
+	"maxArgumentsSize": true,
+	"validateArgs":     true,
+	"cacheTTL":         true,
+	"logResults":       true,
+	"forwardErrors":    true,
+	"priority":         true,
+	"toolPrefix":       true,
+	"weight":           true,
+	"labels":           true,
+	"giveUpAfter":      true,
+	"restartPolicy":    true,
+	"restartBaseDelay": true,
+	"restartMaxDelay":  true,
+	"maxRestarts":      true,
+	"startRetries":     true,
+	"startRetryDelay":  true,
+	"breakerThreshold": true,
+	"breakerWindow":    true,
+	"breakerCooldown":  true,
+	"transforms":       true,
+	"includeTools":     true,
+	"excludeTools":     true,
+}
+
+// headerFields can be applied in place to HTTP and SSE servers
+var headerFields = map[string]bool{
+	"headers":        true,
+	"dynamicHeaders": true,
+}
+
+// classifyChange compares two configs of a server field by field. Anything
+// not known to be safe to apply in place, such as the command, args, url,
+// image or env, needs a restart.
+func classifyChange(a, b config.ServerConfig) serverChange {
+	fields := changedFields(a, b)
+	if len(fields) == 0 {
+		return changeNone
+	}
+
+	t := a.TransportType()
+	inPlaceHeaders := t == b.TransportType() && (t == "http" || t == "sse")
+	change := changeHeaders
+	for _, field := range fields {
+		switch {
+		case headerFields[field] && inPlaceHeaders:
+		case liveFields[field]:
+			change = changeLive
+		default:
+			return changeRestart
+		}
+	}
+	return change
+}
+
+// changedFields returns the JSON names of the fields that differ between a
+// and b. Empty and missing maps and lists are equal.
+func changedFields(a, b config.ServerConfig) []string {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	typ := va.Type()
+
+	var changed []string
+	for i := range typ.NumField() {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			name = typ.Field(i).Name
+		}
+		if !fieldEqual(va.Field(i), vb.Field(i)) {
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
+
+func fieldEqual(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Map, reflect.Slice:
+		if a.Len() == 0 && b.Len() == 0 {
+			return true
+		}
+	}
+	return reflect.DeepEqual(a.Interface(), b.Interface())
+}
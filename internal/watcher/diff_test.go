@@ -0,0 +1,98 @@
+package watcher
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+)
+
+func TestClassifyChange(t *testing.T) {
+	stdio := config.ServerConfig{Command: "mcp-files", Args: []string{"--root", "/srv"}, Env: map[string]string{"LEVEL": "info"}}
+	http := config.ServerConfig{Type: "http", URL: "http://github.example/mcp", Headers: map[string]string{"Authorization": "Bearer old"}}
+	docker := config.ServerConfig{Image: "mcp/search:1"}
+
+	tests := []struct {
+		name   string
+		a      config.ServerConfig
+		change func(*config.ServerConfig)
+		want   serverChange
+	}{
+		{"unchanged", stdio, func(*config.ServerConfig) {}, changeNone},
+		{"empty map for a missing one", stdio, func(c *config.ServerConfig) { c.Labels = map[string]string{} }, changeNone},
+
+		// Settings read per call or on the next reconnect
+		{"timeout", stdio, func(c *config.ServerConfig) { c.Timeout = 30 }, changeLive},
+		{"include list", stdio, func(c *config.ServerConfig) { c.IncludeTools = []string{"read_*"} }, changeLive},
+		{"labels", http, func(c *config.ServerConfig) { c.Labels = map[string]string{"team": "search"} }, changeLive},
+		{"restart policy", docker, func(c *config.ServerConfig) { c.RestartPolicy = "never" }, changeLive},
+
+		// Headers are only swapped in place on HTTP transports
+		{"http headers", http, func(c *config.ServerConfig) { c.Headers = map[string]string{"Authorization": "Bearer new"} }, changeHeaders},
+		{"http headers and timeout", http, func(c *config.ServerConfig) {
+			c.Headers = map[string]string{"Authorization": "Bearer new"}
+			c.Timeout = 30
+		}, changeLive},
+		{"headers with a new transport", http, func(c *config.ServerConfig) {
+			c.Type = "sse"
+			c.Headers = nil
+		}, changeRestart},
+
+		// Anything shaping the connection
+		{"command", stdio, func(c *config.ServerConfig) { c.Command = "mcp-files-v2" }, changeRestart},
+		{"args", stdio, func(c *config.ServerConfig) { c.Args = []string{"--root", "/home"} }, changeRestart},
+		{"env", stdio, func(c *config.ServerConfig) { c.Env = map[string]string{"LEVEL": "debug"} }, changeRestart},
+		{"url", http, func(c *config.ServerConfig) { c.URL = "http://github.example/v2/mcp" }, changeRestart},
+		{"image", docker, func(c *config.ServerConfig) { c.Image = "mcp/search:2" }, changeRestart},
+		{"restart field next to a live one", stdio, func(c *config.ServerConfig) {
+			c.Timeout = 30
+			c.Command = "mcp-files-v2"
+		}, changeRestart},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := tt.a
+			b.Args = slices.Clone(tt.a.Args)
+			tt.change(&b)
+			if got := classifyChange(tt.a, b); got != tt.want {
+				t.Errorf("classifyChange = %d, want %d (changed %v)", got, tt.want, changedFields(tt.a, b))
+			}
+		})
+	}
+}
+
+func TestApplyFieldChanges(t *testing.T) {
+	old := map[string]config.ServerConfig{
+		"files":  {Command: "mcp-files"},
+		"github": {Type: "http", URL: "http://github.example/mcp", Headers: map[string]string{"Authorization": "Bearer old"}},
+		"search": {Type: "http", URL: "http://search.example/mcp"},
+		"docs":   {Command: "mcp-docs"},
+	}
+	changed := map[string]config.ServerConfig{
+		"files":  {Command: "mcp-files", Labels: map[string]string{"team": "infra"}, Timeout: 30},
+		"github": {Type: "http", URL: "http://github.example/mcp", Headers: map[string]string{"Authorization": "Bearer new"}},
+		"search": {Type: "http", URL: "http://search.example/v2/mcp"},
+		"docs":   {Command: "mcp-docs"},
+	}
+	tests := []struct {
+		mode string
+		want []string
+	}{
+		{"", []string{"headers github", "live files", "reload search"}},
+		// A restart is part of the swap
+		{"graceful", []string{"headers github", "live files", "start search"}},
+	}
+	for _, tt := range tests {
+		mode := tt.mode
+		t.Run("reloadMode "+mode, func(t *testing.T) {
+			m := &fakeManager{}
+			w := &Watcher{manager: m, logger: testLogger, lastConfig: &config.Config{MCPServers: old, Hub: config.HubConfig{ReloadMode: mode}}}
+			w.applyConfig(context.Background(), &config.Config{MCPServers: changed, Hub: config.HubConfig{ReloadMode: mode}})
+
+			if got := m.take(); !slices.Equal(got, tt.want) {
+				t.Errorf("applied %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
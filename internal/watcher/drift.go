@@ -0,0 +1,130 @@
+package watcher
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/plugin"
+)
+
+// driftLoop compares the declared config with the running servers on every
+// tick, repairing differences if configured to
+func (w *Watcher) driftLoop(ctx context.Context, interval time.Duration, repair bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.checkDrift(ctx, repair)
+		}
+	}
+}
+
+// checkDrift re-reads the declared config, the file or the last polled remote
+// config, and records how the running servers differ from it
+func (w *Watcher) checkDrift(ctx context.Context, repair bool) {
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	declared := w.lastConfig
+	if w.remote == nil {
+		cfg, err := config.Load(w.configPath)
+		if err != nil {
+			w.logger.Warn("drift:skip", "err", err)
+			return
+		}
+		declared = cfg
+	}
+	servers, invalid, err := declared.ActiveServers()
+	if err != nil {
+		w.logger.Warn("drift:skip", "err", err)
+		return
+	}
+
+	report := plugin.DriftReport{CheckedAt: time.Now(), Drift: findDrift(servers, w.manager)}
+	for _, d := range report.Drift {
+		w.logger.Warn("drift:detected", "plugin", d.Server, "kind", d.Kind)
+	}
+
+	if repair && len(report.Drift) > 0 {
+		w.manager.SetInvalidServers(invalid)
+		w.manager.SetVirtualServers(declared.VirtualServers)
+		repaired, failed := 0, 0
+		for _, d := range report.Drift {
+			if d.Kind == "gave_up" {
+				continue
+			}
+			if err := w.repairDrift(ctx, d, servers[d.Server]); err != nil {
+				w.logger.Error("drift:repair-fail", "plugin", d.Server, "kind", d.Kind, "err", err)
+				failed++
+				continue
+			}
+			repaired++
+		}
+		// Until every repair went through the running servers still differ
+		// from the declared config, so the next reload must diff against the
+		// old one
+		if repaired > 0 && failed == 0 {
+			w.lastConfig = declared
+			report.Repaired = true
+		}
+	}
+	w.manager.SetDriftReport(report)
+}
+
+// findDrift lists how the running servers differ from the declared ones.
+// Servers that are starting or reconnecting are not drift, and servers the hub
+// gave up on are reported as such rather than as missing, so that a repair
+// doesn't undo giveUpAfter and maxRestarts.
+func findDrift(declared map[string]config.ServerConfig, manager PluginManager) []plugin.Drift {
+	drift := []plugin.Drift{}
+	for name, cfg := range declared {
+		srv, running := manager.GetServer(name)
+		switch {
+		case running && !configEqual(srv.Config(), cfg):
+			drift = append(drift, plugin.Drift{Server: name, Kind: "changed"})
+		case !running:
+			switch manager.State(name) {
+			case plugin.StateConnecting, plugin.StateReconnecting:
+			default:
+				kind := "missing"
+				if manager.GaveUp(name) {
+					kind = "gave_up"
+				}
+				drift = append(drift, plugin.Drift{Server: name, Kind: kind})
+			}
+		}
+	}
+	for _, name := range manager.ListServers() {
+		if _, ok := declared[name]; !ok {
+			drift = append(drift, plugin.Drift{Server: name, Kind: "unexpected"})
+		}
+	}
+	sort.Slice(drift, func(i, j int) bool { return drift[i].Server < drift[j].Server })
+	return drift
+}
+
+// repairDrift brings one server in line with its declared config
+func (w *Watcher) repairDrift(ctx context.Context, d plugin.Drift, cfg config.ServerConfig) error {
+	var err error
+	switch d.Kind {
+	case "missing":
+		err = w.manager.StartServer(ctx, d.Server, cfg)
+	case "unexpected":
+		err = w.manager.StopServer(d.Server)
+	case "changed":
+		err = w.manager.ReloadServer(ctx, d.Server, cfg)
+	}
+	if err != nil {
+		return err
+	}
+	w.logger.Info("drift:repaired", "plugin", d.Server, "kind", d.Kind)
+	return nil
+}
@@ -0,0 +1,125 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/plugin"
+	"github.com/amir-the-h/mcp-hub/internal/registry"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestDriftCheck(t *testing.T) {
+	backend := mcp.NewServer(&mcp.Implementation{Name: "echo"}, nil)
+	srv := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return backend }, nil))
+	t.Cleanup(func() {
+		srv.CloseClientConnections()
+		srv.Close()
+	})
+
+	path := filepath.Join(t.TempDir(), "config.json")
+	doc := fmt.Sprintf(`{"mcpServers":{"echo":{"type":"http","url":%q}}}`, srv.URL)
+	if err := os.WriteFile(path, []byte(doc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	pm := plugin.NewManager(registry.New(), plugin.WithLogger(testLogger))
+	if err := pm.LoadFromConfig(ctx, cfg); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pm.StopAll(ctx) })
+	w, err := New(path, pm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(w.Stop)
+	w.SetLogger(testLogger)
+
+	w.checkDrift(ctx, false)
+	if r, _ := pm.DriftReport(); len(r.Drift) != 0 {
+		t.Fatalf("drift %v right after loading", r.Drift)
+	}
+
+	// A server stopped behind the watcher's back is missing, and stays
+	// stopped unless the check repairs it
+	pm.StopServer("echo")
+	want := []plugin.Drift{{Server: "echo", Kind: "missing"}}
+	w.checkDrift(ctx, false)
+	if r, _ := pm.DriftReport(); !slices.Equal(r.Drift, want) || r.Repaired {
+		t.Errorf("report = %+v, want %v unrepaired", r, want)
+	}
+	if _, ok := pm.GetServer("echo"); ok {
+		t.Error("check without repair restarted the server")
+	}
+
+	w.checkDrift(ctx, true)
+	if r, _ := pm.DriftReport(); !slices.Equal(r.Drift, want) || !r.Repaired {
+		t.Errorf("report = %+v, want %v repaired", r, want)
+	}
+	if st := pm.State("echo"); st != plugin.StateConnected {
+		t.Errorf("repaired server is %s", st)
+	}
+	w.checkDrift(ctx, false)
+	if r, _ := pm.DriftReport(); len(r.Drift) != 0 {
+		t.Errorf("drift %v after the repair", r.Drift)
+	}
+}
+
+func TestDriftRepairGaveUpAndFailures(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	doc := `{"mcpServers":{"flaky":{"command":"flaky"},"down":{"command":"down"},"ok":{"command":"ok"}}}`
+	if err := os.WriteFile(path, []byte(doc), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	m := &fakeManager{
+		stopped: map[string]bool{"flaky": true, "down": true, "ok": true},
+		gaveUp:  map[string]bool{"flaky": true},
+		failing: map[string]bool{"down": true},
+	}
+	w, err := New(path, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(w.Stop)
+	w.SetLogger(testLogger)
+	loaded := w.lastConfig
+
+	// The server the hub gave up on is reported but left alone, and a failed
+	// repair leaves the report unrepaired
+	ctx := context.Background()
+	w.checkDrift(ctx, true)
+	if calls := m.take(); !slices.Equal(calls, []string{"start down", "start ok"}) {
+		t.Errorf("repair calls = %v, want down and ok started", calls)
+	}
+	want := []plugin.Drift{{Server: "down", Kind: "missing"}, {Server: "flaky", Kind: "gave_up"}, {Server: "ok", Kind: "missing"}}
+	if r := m.drift; !slices.Equal(r.Drift, want) || r.Repaired {
+		t.Errorf("report = %+v, want %v unrepaired", r, want)
+	}
+	if w.lastConfig != loaded {
+		t.Error("a failed repair replaced the last applied config")
+	}
+
+	m.failing = nil
+	w.checkDrift(ctx, true)
+	if calls := m.take(); slices.Contains(calls, "start flaky") {
+		t.Errorf("repair calls = %v, restarted the server the hub gave up on", calls)
+	}
+	if r := m.drift; !r.Repaired {
+		t.Errorf("report = %+v, want repaired", r)
+	}
+	if w.lastConfig == loaded {
+		t.Error("a successful repair kept the old config")
+	}
+}
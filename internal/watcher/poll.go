@@ -0,0 +1,96 @@
+package watcher
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+)
+
+// defaultFilePollInterval is how often the config file is polled when
+// fsnotify is unavailable and hub.watchPollInterval is unset
+const defaultFilePollInterval = 2 * time.Second
+
+// filePollInterval returns the configured interval for polling the file
+func (w *Watcher) filePollInterval() time.Duration {
+	if s := w.lastConfig.Hub.WatchPollInterval; s > 0 {
+		return time.Duration(s) * time.Second
+	}
+	return defaultFilePollInterval
+}
+
+// startFilePolling polls the config file, as configured if err is nil or
+// as a fallback after fsnotify failed with err
+func (w *Watcher) startFilePolling(ctx context.Context, err error) {
+	interval := w.filePollInterval()
+	if err == nil {
+		w.logger.Info("watch:poll", "path", w.configPath, "interval", interval)
+	} else {
+		w.logger.Warn("watch:poll", "path", w.configPath, "interval", interval, "reason", err)
+	}
+	// Hashed before returning, a change right after Start must not become
+	// the baseline
+	last, _ := hashConfig(w.configPath, w.dir)
+	go w.filePollLoop(ctx, interval, last)
+}
+
+// filePollLoop reloads the config whenever the file's content differs from
+// last. Contents are hashed rather than trusting mtime, which some network
+// filesystems only keep to the second.
+func (w *Watcher) filePollLoop(ctx context.Context, interval time.Duration, last [sha256.Size]byte) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			sum, err := hashConfig(w.configPath, w.dir)
+			if err != nil {
+				// Editors may replace the file, try again next tick
+				continue
+			}
+			if sum == last {
+				continue
+			}
+			last = sum
+			w.scheduleReload(ctx)
+		}
+	}
+}
+
+// hashConfig hashes the config file, or the names and contents of the
+// fragments of a config directory
+func hashConfig(path string, dir bool) ([sha256.Size]byte, error) {
+	if !dir {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return [sha256.Size]byte{}, err
+		}
+		return sha256.Sum256(data), nil
+	}
+
+	files, err := config.Fragments(path)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	h := sha256.New()
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return [sha256.Size]byte{}, err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", filepath.Base(file), len(data))
+		h.Write(data)
+	}
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
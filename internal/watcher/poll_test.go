@@ -0,0 +1,59 @@
+package watcher
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPollingFallback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	write := func(doc string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(doc), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`{"hub":{"watchPollInterval":1},"mcpServers":{"github":{"type":"http","url":"http://github.example/mcp"}}}`)
+
+	m := &fakeManager{}
+	w, err := New(path, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logs bytes.Buffer
+	w.SetLogger(slog.New(slog.NewTextHandler(&logs, nil)))
+
+	// Act as if fsnotify isn't supported here
+	if w.watcher != nil {
+		w.watcher.Close()
+		w.watcher = nil
+	}
+	w.watchErr = errors.New("inotify unavailable")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := w.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	if out := logs.String(); !strings.Contains(out, "msg=watch:poll") || !strings.Contains(out, "inotify unavailable") {
+		t.Errorf("fallback not logged:\n%s", out)
+	}
+
+	write(`{"hub":{"watchPollInterval":1},"mcpServers":{"github":{"type":"http","url":"http://github.example/mcp"},"files":{"command":"mcp-files"}}}`)
+	var got []string
+	for deadline := time.Now().Add(5 * time.Second); len(got) == 0 && time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		got = m.take()
+	}
+	if !slices.Equal(got, []string{"start files"}) {
+		t.Errorf("reload applied %v, want [start files]", got)
+	}
+}
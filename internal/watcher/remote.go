@@ -0,0 +1,85 @@
+package watcher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/logging"
+)
+
+// NewRemote creates a watcher that polls a remote configuration every
+// interval and applies changes the same way as file changes
+func NewRemote(ctx context.Context, remote *config.Remote, interval time.Duration, manager PluginManager) (*Watcher, *config.Config, error) {
+	if interval <= 0 {
+		return nil, nil, fmt.Errorf("poll interval must be positive")
+	}
+
+	data, err := remote.Fetch(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load initial config: %w", err)
+	}
+	initialConfig, err := config.Parse(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load initial config: %w", err)
+	}
+
+	w := &Watcher{
+		manager:      manager,
+		remote:       remote,
+		pollInterval: interval,
+		lastRaw:      data,
+		lastConfig:   initialConfig,
+		stopCh:       make(chan struct{}),
+		logger:       logging.Default(),
+	}
+	return w, initialConfig.Clone(), nil
+}
+
+// pollLoop fetches the remote config on every tick
+func (w *Watcher) pollLoop(ctx context.Context) {
+	ticker := time.NewTicker(w.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.pollRemote(ctx)
+		}
+	}
+}
+
+// pollRemote applies the remote config if it changed since the last fetch.
+// Fetch and parse failures keep the last good config running.
+func (w *Watcher) pollRemote(ctx context.Context) {
+	fetchCtx, cancel := context.WithTimeout(ctx, w.pollInterval)
+	data, err := w.remote.Fetch(fetchCtx)
+	cancel()
+	if err != nil {
+		w.logger.Warn("reload:fetch-fail", "url", w.remote.URL, "err", err)
+		return
+	}
+
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	if bytes.Equal(data, w.lastRaw) {
+		return
+	}
+
+	newConfig, err := config.Parse(data)
+	if err != nil {
+		w.logger.Error("reload:load-fail", "url", w.remote.URL, "err", err)
+		return
+	}
+
+	w.logger.Info("reload:start", "url", w.remote.URL)
+	w.lastRaw = data
+	w.applyConfig(ctx, newConfig)
+}
@@ -0,0 +1,87 @@
+package watcher
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+)
+
+// configServer serves a configuration document that tests can swap, or an
+// error status while status is set
+type configServer struct {
+	mu     sync.Mutex
+	body   string
+	status int
+	token  string // Authorization of the last fetch
+}
+
+func (s *configServer) set(body string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body, s.status = body, status
+}
+
+func (s *configServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = r.Header.Get("Authorization")
+	if s.status != 0 {
+		w.WriteHeader(s.status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(s.body))
+}
+
+func TestRemoteConfig(t *testing.T) {
+	cs := &configServer{body: `{"mcpServers":{"github":{"type":"http","url":"http://github.example/mcp"}}}`}
+	srv := httptest.NewServer(cs)
+	defer srv.Close()
+
+	ctx := context.Background()
+	m := &fakeManager{}
+	remote := &config.Remote{URL: srv.URL, HeaderName: "Authorization", HeaderValue: "Bearer secret"}
+	w, cfg, err := NewRemote(ctx, remote, time.Minute, m)
+	if err != nil {
+		t.Fatalf("new remote: %v", err)
+	}
+	w.SetLogger(testLogger)
+	if _, ok := cfg.MCPServers["github"]; !ok {
+		t.Fatalf("initial config = %+v", cfg.MCPServers)
+	}
+	if cs.token != "Bearer secret" {
+		t.Errorf("fetch sent Authorization %q", cs.token)
+	}
+
+	// A changed document is applied like a changed file
+	cs.set(`{"mcpServers":{"github":{"type":"http","url":"http://github.example/mcp"},"files":{"command":"mcp-files"}}}`, 0)
+	w.pollRemote(ctx)
+	if got := m.take(); !slices.Equal(got, []string{"start files"}) {
+		t.Errorf("reload applied %v, want [start files]", got)
+	}
+
+	// Failed fetches and broken documents keep the last good config
+	cs.set("", http.StatusInternalServerError)
+	w.pollRemote(ctx)
+	cs.set(`{"mcpServers":`, 0)
+	w.pollRemote(ctx)
+	if got := m.take(); len(got) != 0 {
+		t.Errorf("failed fetches applied %v", got)
+	}
+	if _, ok := w.lastConfig.MCPServers["files"]; !ok {
+		t.Error("last good config dropped")
+	}
+
+	// Once it is fixed, the next poll applies it
+	cs.set(`{"mcpServers":{"files":{"command":"mcp-files"}}}`, 0)
+	w.pollRemote(ctx)
+	if got := m.take(); !slices.Equal(got, []string{"stop github"}) {
+		t.Errorf("reload applied %v, want [stop github]", got)
+	}
+}
@@ -0,0 +1,66 @@
+package watcher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRenameOverConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	// replace writes doc to a temp file and renames it over the config, the
+	// way editors and ConfigMap updates do
+	replace := func(doc string) {
+		t.Helper()
+		tmp := filepath.Join(dir, ".config.json.tmp")
+		if err := os.WriteFile(tmp, []byte(doc), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Rename(tmp, path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// reloaded waits for the next reload to apply something
+	reloaded := func(m *fakeManager) []string {
+		t.Helper()
+		var got []string
+		for deadline := time.Now().Add(5 * time.Second); len(got) == 0 && time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+			got = m.take()
+		}
+		return got
+	}
+	if err := os.WriteFile(path, []byte(`{"mcpServers":{"github":{"type":"http","url":"http://github.example/mcp"}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	m := &fakeManager{}
+	w, err := New(path, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SetLogger(testLogger)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := w.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer w.Stop()
+	if w.watcher == nil {
+		t.Skip("fsnotify unavailable:", w.watchErr)
+	}
+
+	replace(`{"mcpServers":{"github":{"type":"http","url":"http://github.example/mcp"},"files":{"command":"mcp-files"}}}`)
+	if got := reloaded(m); !slices.Equal(got, []string{"start files"}) {
+		t.Fatalf("first rename applied %v, want [start files]", got)
+	}
+
+	// The watch survives the replaced file, the next swap is seen too
+	replace(`{"mcpServers":{"files":{"command":"mcp-files"}}}`)
+	if got := reloaded(m); !slices.Equal(got, []string{"stop github"}) {
+		t.Errorf("second rename applied %v, want [stop github]", got)
+	}
+}
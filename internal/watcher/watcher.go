@@ -2,15 +2,17 @@ package watcher
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"path/filepath"
-	"reflect"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/logging"
+	"github.com/amir-the-h/mcp-hub/internal/plugin"
 	"github.com/fsnotify/fsnotify"
 )
 
@@ -19,15 +21,49 @@ type PluginManager interface {
 	StartServer(ctx context.Context, name string, cfg config.ServerConfig) error
 	StopServer(name string) error
 	ReloadServer(ctx context.Context, name string, cfg config.ServerConfig) error
+	UpdateConfig(ctx context.Context, name string, cfg config.ServerConfig) error
+	ReplaceServers(ctx context.Context, start map[string]config.ServerConfig, stop []string) error
+	SetInvalidServers(invalid map[string]error)
+	SetVirtualServers(virtuals map[string]config.VirtualServer)
+	UpdateHeaders(name string, cfg config.ServerConfig) error
+	State(name string) plugin.ServerState
+	GaveUp(name string) bool
+	GetServer(name string) (*plugin.MCPServer, bool)
+	ListServers() []string
+	SetDriftReport(r plugin.DriftReport)
 }
 
 // Watcher monitors configuration file for changes
 type Watcher struct {
 	configPath string
+	dir        bool // configPath is a directory of config fragments
 	manager    PluginManager
-	watcher    *fsnotify.Watcher
-	lastConfig *config.Config
+	watcher    *fsnotify.Watcher // nil when polling the file instead
+	watchErr   error             // why fsnotify is unavailable
 	stopCh     chan struct{}
+
+	// Set instead of configPath/watcher when polling a remote config
+	remote       *config.Remote
+	pollInterval time.Duration
+	lastRaw      []byte
+
+	// reloadMu serializes reloads, debounced callbacks may overlap
+	reloadMu   sync.Mutex
+	lastConfig *config.Config
+
+	// debounce delays the reload after a change, see scheduleReload
+	debounceMu sync.Mutex
+	debounce   *time.Timer
+
+	logger *slog.Logger
+}
+
+// SetLogger sets the logger for reload events, logging.Default() if unset.
+// It must be called before Start.
+func (w *Watcher) SetLogger(logger *slog.Logger) {
+	if logger != nil {
+		w.logger = logger
+	}
 }
 
 // New creates a new config file watcher
@@ -53,18 +89,26 @@ func New(configPath string, manager PluginManager) (*Watcher, error) {
 		return nil, fmt.Errorf("failed to load initial config: %w", err)
 	}
 
-	// Create fsnotify watcher
-	fsWatcher, err := fsnotify.NewWatcher()
+	info, err := os.Stat(absPath)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create file watcher: %w", err)
+		return nil, fmt.Errorf("failed to stat config path: %w", err)
 	}
 
 	w := &Watcher{
 		configPath: absPath,
+		dir:        info.IsDir(),
 		manager:    manager,
-		watcher:    fsWatcher,
 		lastConfig: initialConfig,
 		stopCh:     make(chan struct{}),
+		logger:     logging.Default(),
+	}
+
+	// Create fsnotify watcher, Start falls back to polling without one
+	fsWatcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		w.watchErr = fmt.Errorf("failed to create file watcher: %w", err)
+	} else {
+		w.watcher = fsWatcher
 	}
 
 	return w, nil
@@ -72,12 +116,51 @@ func New(configPath string, manager PluginManager) (*Watcher, error) {
 
 // Start begins watching the config file
 func (w *Watcher) Start(ctx context.Context) error {
-	// Watch the config file
-	if err := w.watcher.Add(w.configPath); err != nil {
-		return fmt.Errorf("failed to watch config file: %w", err)
+	if hub := w.lastConfig.Hub; hub.DriftCheckInterval > 0 {
+		interval := time.Duration(hub.DriftCheckInterval) * time.Second
+		w.logger.Info("drift:watch", "interval", interval, "repair", hub.DriftRepair)
+		go w.driftLoop(ctx, interval, hub.DriftRepair)
+	}
+
+	if w.remote != nil {
+		w.logger.Info("watch:remote", "url", w.remote.URL, "interval", w.pollInterval)
+		go w.pollLoop(ctx)
+		return nil
+	}
+
+	if w.lastConfig.Hub.WatchMode == "poll" {
+		if w.watcher != nil {
+			w.watcher.Close()
+			w.watcher = nil
+		}
+		w.startFilePolling(ctx, nil)
+		return nil
 	}
 
-	log.Printf("watching config file: %s", w.configPath)
+	if w.watcher == nil {
+		w.startFilePolling(ctx, w.watchErr)
+		return nil
+	}
+
+	// Watch the directory holding the config file rather than the file.
+	// Editors and ConfigMap updates replace the file by renaming another
+	// over it, and a watch on the file itself would stay on the old one.
+	watchPath := w.configPath
+	if !w.dir {
+		watchPath = filepath.Dir(w.configPath)
+	}
+	if err := w.watcher.Add(watchPath); err != nil {
+		w.watcher.Close()
+		w.watcher = nil
+		w.startFilePolling(ctx, fmt.Errorf("failed to watch config file: %w", err))
+		return nil
+	}
+
+	if w.dir {
+		w.logger.Info("watch:dir", "path", w.configPath)
+	} else {
+		w.logger.Info("watch:file", "path", w.configPath)
+	}
 
 	go w.watchLoop(ctx)
 	return nil
@@ -86,15 +169,18 @@ func (w *Watcher) Start(ctx context.Context) error {
 // Stop stops the watcher
 func (w *Watcher) Stop() {
 	close(w.stopCh)
-	w.watcher.Close()
+	w.debounceMu.Lock()
+	if w.debounce != nil {
+		w.debounce.Stop()
+	}
+	w.debounceMu.Unlock()
+	if w.watcher != nil {
+		w.watcher.Close()
+	}
 }
 
 // watchLoop is the main event loop
 func (w *Watcher) watchLoop(ctx context.Context) {
-	// Debounce timer to avoid processing multiple rapid changes
-	var debounceTimer *time.Timer
-	const debounceDelay = 500 * time.Millisecond
-
 	for {
 		select {
 		case <-w.stopCh:
@@ -103,64 +189,135 @@ func (w *Watcher) watchLoop(ctx context.Context) {
 			return
 		case event, ok := <-w.watcher.Events:
 			if !ok {
+				w.fallBackToPolling(ctx)
 				return
 			}
 
-			// We care about Write and Create events
-			if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) {
-				// Reset debounce timer
-				if debounceTimer != nil {
-					debounceTimer.Stop()
-				}
-				debounceTimer = time.AfterFunc(debounceDelay, func() {
-					w.handleConfigChange(ctx)
-				})
+			if w.relevant(event) {
+				w.scheduleReload(ctx)
 			}
 
 		case err, ok := <-w.watcher.Errors:
 			if !ok {
+				w.fallBackToPolling(ctx)
 				return
 			}
-			log.Printf("watcher error: %v", err)
+			w.logger.Warn("watch:error", "err", err)
 		}
 	}
 }
 
+// debounceDelay is how long the config must stay unchanged before it is
+// reloaded, so editors writing several times cause a single reload
+const debounceDelay = 500 * time.Millisecond
+
+// scheduleReload reloads the config once no further change arrived for
+// debounceDelay
+func (w *Watcher) scheduleReload(ctx context.Context) {
+	w.debounceMu.Lock()
+	defer w.debounceMu.Unlock()
+	if w.debounce != nil {
+		w.debounce.Stop()
+	}
+	w.debounce = time.AfterFunc(debounceDelay, func() {
+		w.handleConfigChange(ctx)
+	})
+}
+
+// relevant reports whether event changes the config: the config file being
+// written or replaced, or any change to a fragment of a config directory
+func (w *Watcher) relevant(event fsnotify.Event) bool {
+	if w.dir {
+		return config.IsFragment(event.Name) &&
+			(event.Has(fsnotify.Write) || event.Has(fsnotify.Create) || event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename))
+	}
+	// Kubernetes swaps a ConfigMap's files by renaming the ..data symlink
+	// the config file resolves through
+	if filepath.Clean(event.Name) != w.configPath && !strings.HasPrefix(filepath.Base(event.Name), "..") {
+		return false
+	}
+	return event.Has(fsnotify.Write) || event.Has(fsnotify.Create) || event.Has(fsnotify.Rename)
+}
+
+// fallBackToPolling keeps reloads working after the fsnotify watcher closed
+// on its own, e.g. when its backend failed
+func (w *Watcher) fallBackToPolling(ctx context.Context) {
+	select {
+	case <-w.stopCh:
+		return
+	case <-ctx.Done():
+		return
+	default:
+	}
+	w.startFilePolling(ctx, fmt.Errorf("file watcher closed unexpectedly"))
+}
+
 // handleConfigChange processes config file changes
 func (w *Watcher) handleConfigChange(ctx context.Context) {
-	log.Printf("config file changed, reloading...")
+	w.reloadMu.Lock()
+	defer w.reloadMu.Unlock()
+
+	w.logger.Info("reload:start", "path", w.configPath)
 
 	// Load new config
 	newConfig, err := config.Load(w.configPath)
 	if err != nil {
-		log.Printf("error loading new config: %v", err)
+		w.logger.Error("reload:load-fail", "err", err)
 		return
 	}
 
+	w.applyConfig(ctx, newConfig)
+}
+
+// applyConfig validates a freshly loaded config and applies it, the caller
+// must hold reloadMu. An invalid config leaves the last good one in place.
+func (w *Watcher) applyConfig(ctx context.Context, newConfig *config.Config) {
 	// Validate new config
-	if err := newConfig.Validate(); err != nil {
-		log.Printf("invalid config, skipping reload: %v", err)
+	newServers, invalid, err := newConfig.ActiveServers()
+	if err != nil {
+		w.logger.Error("reload:invalid", "err", err)
 		return
 	}
+	// A graceful reload that fails leaves everything as it was, and the
+	// next change retries it
+	if newConfig.Hub.ReloadMode == "graceful" {
+		if err := w.replaceServers(ctx, newServers); err != nil {
+			w.logger.Error("reload:replace-fail", "err", err)
+			return
+		}
+		w.manager.SetInvalidServers(invalid)
+		w.manager.SetVirtualServers(newConfig.VirtualServers)
+		w.lastConfig = newConfig
+		return
+	}
+
+	w.manager.SetInvalidServers(invalid)
+	w.manager.SetVirtualServers(newConfig.VirtualServers)
 
 	// Compare and apply changes
-	w.applyConfigChanges(ctx, newConfig)
+	w.applyConfigChanges(ctx, newServers)
 
 	// Update last config
 	w.lastConfig = newConfig
 }
 
 // applyConfigChanges determines what changed and applies updates
-func (w *Watcher) applyConfigChanges(ctx context.Context, newConfig *config.Config) {
-	oldServers := w.lastConfig.GetEnabledServers()
-	newServers := newConfig.GetEnabledServers()
+func (w *Watcher) applyConfigChanges(ctx context.Context, newServers map[string]config.ServerConfig) {
+	oldServers, _, err := w.lastConfig.ActiveServers()
+	if err != nil {
+		oldServers = w.lastConfig.GetEnabledServers()
+	}
+
+	if len(newServers) == 0 && len(oldServers) > 0 {
+		w.logger.Warn("reload:empty", "action", "stopping all servers")
+	}
 
 	// Find servers to remove (in old but not in new, or disabled in new)
 	for name := range oldServers {
 		if _, exists := newServers[name]; !exists {
-			log.Printf("removing server: %s", name)
+			w.logger.Info("reload:remove", "plugin", name)
 			if err := w.manager.StopServer(name); err != nil {
-				log.Printf("error stopping server %s: %v", name, err)
+				w.logger.Warn("reload:stop-fail", "plugin", name, "err", err)
 			}
 		}
 	}
@@ -168,33 +325,125 @@ func (w *Watcher) applyConfigChanges(ctx context.Context, newConfig *config.Conf
 	// Find servers to add or update
 	for name, newCfg := range newServers {
 		oldCfg, exists := oldServers[name]
-
 		if !exists {
 			// New server
-			log.Printf("adding server: %s", name)
+			w.logger.Info("reload:add", "plugin", name)
 			if err := w.manager.StartServer(ctx, name, newCfg); err != nil {
-				log.Printf("error starting server %s: %v", name, err)
+				w.logger.Warn("reload:start-fail", "plugin", name, "err", err)
 			}
-		} else if !configEqual(oldCfg, newCfg) {
-			// Server configuration changed
-			log.Printf("reloading server: %s", name)
+			continue
+		}
+
+		switch classifyChange(oldCfg, newCfg) {
+		case changeHeaders:
+			// Rotated credentials on an HTTP backend, no reconnect needed
+			w.updateHeaders(ctx, name, newCfg)
+		case changeLive:
+			// Settings the running connection picks up
+			w.updateLive(ctx, name, newCfg)
+		case changeRestart:
+			// Server connection changed
+			w.logger.Info("reload:restart", "plugin", name)
 			if err := w.manager.ReloadServer(ctx, name, newCfg); err != nil {
-				log.Printf("error reloading server %s: %v", name, err)
+				w.logger.Warn("reload:restart-fail", "plugin", name, "err", err)
+			}
+		default:
+			if w.manager.State(name) == plugin.StateStopped {
+				// Unchanged server the hub gave up on, a reload retries it
+				w.logger.Info("reload:retry", "plugin", name)
+				if err := w.manager.StartServer(ctx, name, newCfg); err != nil {
+					w.logger.Warn("reload:start-fail", "plugin", name, "err", err)
+				}
 			}
 		}
 	}
 }
 
-// configEqual checks if two server configs are equal
-func configEqual(a, b config.ServerConfig) bool {
-	// Compare JSON representations for deep equality
-	aJSON, err := json.Marshal(a)
+// replaceServers applies a config change as one swap to the new set of
+// servers. Changes that need no new connection are applied in place, and
+// unchanged servers the hub gave up on are retried once the swap is done.
+func (w *Watcher) replaceServers(ctx context.Context, newServers map[string]config.ServerConfig) error {
+	oldServers, _, err := w.lastConfig.ActiveServers()
 	if err != nil {
-		return false
+		oldServers = w.lastConfig.GetEnabledServers()
 	}
-	bJSON, err := json.Marshal(b)
-	if err != nil {
-		return false
+
+	var stop []string
+	for name := range oldServers {
+		if _, exists := newServers[name]; !exists {
+			stop = append(stop, name)
+		}
+	}
+	start := make(map[string]config.ServerConfig)
+	headers := make(map[string]config.ServerConfig)
+	live := make(map[string]config.ServerConfig)
+	retry := make(map[string]config.ServerConfig)
+	for name, newCfg := range newServers {
+		oldCfg, exists := oldServers[name]
+		if !exists {
+			start[name] = newCfg
+			continue
+		}
+		switch classifyChange(oldCfg, newCfg) {
+		case changeHeaders:
+			headers[name] = newCfg
+		case changeLive:
+			live[name] = newCfg
+		case changeRestart:
+			start[name] = newCfg
+		default:
+			if w.manager.State(name) == plugin.StateStopped {
+				retry[name] = newCfg
+			}
+		}
+	}
+
+	if len(start) > 0 || len(stop) > 0 {
+		w.logger.Info("reload:replace", "start", len(start), "stop", len(stop))
+		if err := w.manager.ReplaceServers(ctx, start, stop); err != nil {
+			return err
+		}
+	}
+	for name, cfg := range headers {
+		w.updateHeaders(ctx, name, cfg)
+	}
+	for name, cfg := range live {
+		w.updateLive(ctx, name, cfg)
+	}
+	for name, cfg := range retry {
+		w.logger.Info("reload:retry", "plugin", name)
+		if err := w.manager.StartServer(ctx, name, cfg); err != nil {
+			w.logger.Warn("reload:start-fail", "plugin", name, "err", err)
+		}
+	}
+	return nil
+}
+
+// updateHeaders applies rotated headers to a running server, restarting it
+// if they can't be applied in place
+func (w *Watcher) updateHeaders(ctx context.Context, name string, cfg config.ServerConfig) {
+	w.logger.Info("reload:headers", "plugin", name)
+	if err := w.manager.UpdateHeaders(name, cfg); err != nil {
+		w.logger.Warn("reload:headers-fail", "plugin", name, "err", logging.RedactError(err, nil))
+		if err := w.manager.ReloadServer(ctx, name, cfg); err != nil {
+			w.logger.Warn("reload:restart-fail", "plugin", name, "err", err)
+		}
+	}
+}
+
+// updateLive applies a change the running connection can pick up,
+// restarting the server if it isn't running or the change can't be applied
+func (w *Watcher) updateLive(ctx context.Context, name string, cfg config.ServerConfig) {
+	w.logger.Info("reload:live", "plugin", name)
+	if err := w.manager.UpdateConfig(ctx, name, cfg); err != nil {
+		w.logger.Warn("reload:live-fail", "plugin", name, "err", err)
+		if err := w.manager.ReloadServer(ctx, name, cfg); err != nil {
+			w.logger.Warn("reload:restart-fail", "plugin", name, "err", err)
+		}
 	}
-	return reflect.DeepEqual(aJSON, bJSON)
+}
+
+// configEqual checks if two server configs are equal
+func configEqual(a, b config.ServerConfig) bool {
+	return len(changedFields(a, b)) == 0
 }
@@ -0,0 +1,153 @@
+package watcher
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/amir-the-h/mcp-hub/internal/config"
+	"github.com/amir-the-h/mcp-hub/internal/plugin"
+	"github.com/fsnotify/fsnotify"
+)
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+func TestRelevantDirectoryEvents(t *testing.T) {
+	w := &Watcher{configPath: "/etc/mcp-hub/conf.d", dir: true}
+	tests := []struct {
+		name string
+		op   fsnotify.Op
+		want bool
+	}{
+		{"/etc/mcp-hub/conf.d/github.json", fsnotify.Write, true},
+		{"/etc/mcp-hub/conf.d/github.yaml", fsnotify.Create, true},
+		{"/etc/mcp-hub/conf.d/github.yml", fsnotify.Remove, true},
+		{"/etc/mcp-hub/conf.d/github.json", fsnotify.Rename, true},
+		{"/etc/mcp-hub/conf.d/github.json", fsnotify.Chmod, false},
+		{"/etc/mcp-hub/conf.d/.github.json.swp", fsnotify.Create, false},
+		{"/etc/mcp-hub/conf.d/github.json~", fsnotify.Create, false},
+		{"/etc/mcp-hub/conf.d/github.json.bak", fsnotify.Remove, false},
+		{"/etc/mcp-hub/conf.d/4913", fsnotify.Rename, false},
+	}
+	for _, tt := range tests {
+		if got := w.relevant(fsnotify.Event{Name: tt.name, Op: tt.op}); got != tt.want {
+			t.Errorf("relevant(%s %s) = %v, want %v", tt.op, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRelevantFileEvents(t *testing.T) {
+	w := &Watcher{configPath: "/etc/mcp-hub/config.json"}
+	tests := []struct {
+		name string
+		op   fsnotify.Op
+		want bool
+	}{
+		{"/etc/mcp-hub/config.json", fsnotify.Write, true},
+		{"/etc/mcp-hub/config.json", fsnotify.Create, true},
+		{"/etc/mcp-hub/..data", fsnotify.Create, true},
+		{"/etc/mcp-hub/other.json", fsnotify.Write, false},
+		{"/etc/mcp-hub/config.json", fsnotify.Chmod, false},
+	}
+	for _, tt := range tests {
+		if got := w.relevant(fsnotify.Event{Name: tt.name, Op: tt.op}); got != tt.want {
+			t.Errorf("relevant(%s %s) = %v, want %v", tt.op, tt.name, got, tt.want)
+		}
+	}
+}
+
+// fakeManager records the changes a watcher applies, as "<op> <server>"
+type fakeManager struct {
+	mu      sync.Mutex
+	calls   []string
+	stopped map[string]bool // servers reported stopped by State
+	gaveUp  map[string]bool // servers reported given up on by GaveUp
+	failing map[string]bool // servers StartServer fails to start
+	drift   *plugin.DriftReport
+}
+
+func (m *fakeManager) record(op, name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, op+" "+name)
+}
+
+// take returns the recorded calls sorted, and forgets them
+func (m *fakeManager) take() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	calls := m.calls
+	m.calls = nil
+	slices.Sort(calls)
+	return calls
+}
+
+func (m *fakeManager) StartServer(ctx context.Context, name string, cfg config.ServerConfig) error {
+	m.record("start", name)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.failing[name] {
+		return fmt.Errorf("%s failed to start", name)
+	}
+	return nil
+}
+
+func (m *fakeManager) StopServer(name string) error {
+	m.record("stop", name)
+	return nil
+}
+
+func (m *fakeManager) ReloadServer(ctx context.Context, name string, cfg config.ServerConfig) error {
+	m.record("reload", name)
+	return nil
+}
+
+func (m *fakeManager) UpdateConfig(ctx context.Context, name string, cfg config.ServerConfig) error {
+	m.record("live", name)
+	return nil
+}
+
+func (m *fakeManager) ReplaceServers(ctx context.Context, start map[string]config.ServerConfig, stop []string) error {
+	for name := range start {
+		m.record("start", name)
+	}
+	for _, name := range stop {
+		m.record("stop", name)
+	}
+	return nil
+}
+
+func (m *fakeManager) UpdateHeaders(name string, cfg config.ServerConfig) error {
+	m.record("headers", name)
+	return nil
+}
+
+func (m *fakeManager) State(name string) plugin.ServerState {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stopped[name] {
+		return plugin.StateStopped
+	}
+	return plugin.StateConnected
+}
+
+func (m *fakeManager) SetInvalidServers(map[string]error)                {}
+func (m *fakeManager) SetVirtualServers(map[string]config.VirtualServer) {}
+func (m *fakeManager) GetServer(string) (*plugin.MCPServer, bool)        { return nil, false }
+func (m *fakeManager) ListServers() []string                             { return nil }
+
+func (m *fakeManager) GaveUp(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.gaveUp[name]
+}
+
+func (m *fakeManager) SetDriftReport(r plugin.DriftReport) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.drift = &r
+}